@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -44,6 +49,17 @@ func getCORSOrigins() []string {
 	return defaultOrigins
 }
 
+// getDuration returns the duration in the named environment variable, or
+// fallback when it is unset or invalid
+func getDuration(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
 func main() {
 	if err := flags.LoadFlagsFromDisk("local"); err != nil {
 		log.Fatal(err)
@@ -86,8 +102,40 @@ func main() {
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	srv := &http.Server{
+		Addr:         ":4000",
+		Handler:      r,
+		ReadTimeout:  getDuration("READ_TIMEOUT", 30*time.Second),
+		WriteTimeout: getDuration("WRITE_TIMEOUT", 30*time.Second),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	log.Println("feature-flags-api listening on :4000")
-	if err := r.Run(":4000"); err != nil && err != http.ErrServerClosed {
-		log.Fatal(err)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	// A second signal exits immediately
+	stop()
+
+	// Report not ready first, so load balancers stop routing here while
+	// requests are still being accepted
+	observability.SetReady(false)
+	drainDelay := getDuration("SHUTDOWN_DELAY", 5*time.Second)
+	log.Printf("Shutdown signal received, draining for %s", drainDelay)
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), getDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Requests still in flight at the shutdown deadline were cut off: %v", err)
+		srv.Close()
 	}
+	log.Println("Shutdown complete")
 }
@@ -0,0 +1,33 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Delay returns how long to wait before retry number attempt (1-based).
+// The delay doubles with each attempt starting from base and is capped at
+// max. Half of the delay is randomised ("equal jitter") so that events that
+// failed together do not all retry at the same instant.
+func Delay(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if base <= 0 {
+		return 0
+	}
+	if max < base {
+		max = base
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
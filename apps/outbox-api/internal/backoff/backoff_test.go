@@ -0,0 +1,54 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelay_Bounds(t *testing.T) {
+	base := time.Second
+	max := 30 * time.Second
+
+	tests := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "first attempt", attempt: 1, min: 500 * time.Millisecond, max: time.Second},
+		{name: "second attempt doubles", attempt: 2, min: time.Second, max: 2 * time.Second},
+		{name: "fourth attempt", attempt: 4, min: 4 * time.Second, max: 8 * time.Second},
+		{name: "capped at max", attempt: 10, min: 15 * time.Second, max: 30 * time.Second},
+		{name: "very large attempt does not overflow", attempt: 1000, min: 15 * time.Second, max: 30 * time.Second},
+		{name: "zero attempt treated as first", attempt: 0, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay := Delay(tt.attempt, base, max)
+				assert.GreaterOrEqual(t, delay, tt.min)
+				assert.LessOrEqual(t, delay, tt.max)
+			}
+		})
+	}
+}
+
+func TestDelay_Jitter(t *testing.T) {
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		seen[Delay(3, time.Second, time.Minute)] = true
+	}
+	assert.Greater(t, len(seen), 1, "delays should be randomised")
+}
+
+func TestDelay_Degenerate(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Delay(3, 0, time.Minute))
+
+	// max below base is raised to base
+	delay := Delay(5, 2*time.Second, time.Second)
+	assert.GreaterOrEqual(t, delay, time.Second)
+	assert.LessOrEqual(t, delay, 2*time.Second)
+}
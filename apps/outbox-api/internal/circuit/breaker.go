@@ -0,0 +1,295 @@
+package circuit
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while a breaker is rejecting requests
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String returns the display name used in logs and the admin API
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "CLOSED"
+	case StateOpen:
+		return "OPEN"
+	case StateHalfOpen:
+		return "HALF-OPEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Settings controls when a breaker trips and recovers
+type Settings struct {
+	// FailureThreshold is the number of failures within Interval that opens the circuit
+	FailureThreshold uint32
+	// Interval is the window after which the failure count of a closed circuit resets
+	Interval time.Duration
+	// Timeout is how long the circuit stays open before allowing a trial request
+	Timeout time.Duration
+}
+
+// Snapshot is a point-in-time view of a breaker for the admin API
+type Snapshot struct {
+	Destination     string     `json:"destination"`
+	State           string     `json:"state"`
+	Failures        uint32     `json:"failures"`
+	LastFailureAt   *time.Time `json:"last_failure_at,omitempty"`
+	OpenedAt        *time.Time `json:"opened_at,omitempty"`
+	RetryAfter      *time.Time `json:"retry_after,omitempty"`
+	TotalRejections uint64     `json:"total_rejections"`
+}
+
+// Breaker is a circuit breaker guarding a single destination
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mutex           sync.Mutex
+	state           State
+	failures        uint32
+	windowStart     time.Time
+	lastFailureAt   time.Time
+	openedAt        time.Time
+	probeInFlight   bool
+	totalRejections uint64
+}
+
+func newBreaker(name string, settings Settings, now func() time.Time) *Breaker {
+	circuitState.WithLabelValues(name).Set(float64(StateClosed))
+	return &Breaker{
+		name:        name,
+		settings:    settings,
+		now:         now,
+		state:       StateClosed,
+		windowStart: now(),
+	}
+}
+
+// Allow reports whether a request may be sent. It returns ErrOpen while the
+// circuit is open; once Timeout has passed a single trial request is let
+// through in the half-open state.
+func (b *Breaker) Allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+
+	switch b.state {
+	case StateClosed:
+		if now.Sub(b.windowStart) >= b.settings.Interval {
+			b.failures = 0
+			b.windowStart = now
+		}
+		return nil
+	case StateOpen:
+		if now.Sub(b.openedAt) < b.settings.Timeout {
+			b.totalRejections++
+			return ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probeInFlight = true
+		return nil
+	case StateHalfOpen:
+		if b.probeInFlight {
+			b.totalRejections++
+			return ErrOpen
+		}
+		b.probeInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess records a successful request
+func (b *Breaker) RecordSuccess() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.probeInFlight = false
+		b.failures = 0
+		b.windowStart = b.now()
+		b.setState(StateClosed)
+	}
+}
+
+// RecordFailure records a failed request, opening the circuit when the
+// threshold is reached or a half-open trial request fails
+func (b *Breaker) RecordFailure() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	b.lastFailureAt = now
+
+	switch b.state {
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.openedAt = now
+			b.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		b.probeInFlight = false
+		b.failures++
+		b.openedAt = now
+		b.setState(StateOpen)
+	}
+}
+
+// RecordCancelled records a request abandoned by the caller before the
+// destination answered. It says nothing about the destination, so a
+// half-open circuit lets the next trial request through instead.
+func (b *Breaker) RecordCancelled() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.probeInFlight = false
+	}
+}
+
+// RetryAfter returns when an open circuit will next allow a trial request
+func (b *Breaker) RetryAfter() time.Time {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.openedAt.Add(b.settings.Timeout)
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+// Snapshot returns a point-in-time view of the breaker
+func (b *Breaker) Snapshot() Snapshot {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	snapshot := Snapshot{
+		Destination:     b.name,
+		State:           b.state.String(),
+		Failures:        b.failures,
+		TotalRejections: b.totalRejections,
+	}
+
+	if !b.lastFailureAt.IsZero() {
+		lastFailureAt := b.lastFailureAt
+		snapshot.LastFailureAt = &lastFailureAt
+	}
+
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		retryAfter := b.openedAt.Add(b.settings.Timeout)
+		snapshot.OpenedAt = &openedAt
+		snapshot.RetryAfter = &retryAfter
+	}
+
+	return snapshot
+}
+
+// setState must be called with the mutex held
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	log.Printf("Circuit breaker [%s]: %s → %s", b.name, b.state, state)
+	b.state = state
+	circuitState.WithLabelValues(b.name).Set(float64(state))
+}
+
+// Registry holds one breaker per destination
+type Registry struct {
+	settings Settings
+	now      func() time.Time
+
+	mutex    sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewRegistry creates a registry whose breakers share the given settings.
+// Zero values fall back to 5 failures, a 10s interval and a 5s timeout.
+func NewRegistry(settings Settings) *Registry {
+	if settings.FailureThreshold == 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.Interval <= 0 {
+		settings.Interval = 10 * time.Second
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = 5 * time.Second
+	}
+
+	return &Registry{
+		settings: settings,
+		now:      time.Now,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Get returns the breaker for destination, creating it on first use
+func (r *Registry) Get(destination string) *Breaker {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	breaker, ok := r.breakers[destination]
+	if !ok {
+		breaker = newBreaker(destination, r.settings, r.now)
+		r.breakers[destination] = breaker
+	}
+
+	return breaker
+}
+
+// Remove forgets the breaker for destination and its state metric, so a
+// destination that is no longer delivered to does not stay in the registry
+func (r *Registry) Remove(destination string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.breakers, destination)
+	circuitState.DeleteLabelValues(destination)
+}
+
+// Snapshots returns a view of every breaker, sorted by destination
+func (r *Registry) Snapshots() []Snapshot {
+	r.mutex.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		breakers = append(breakers, breaker)
+	}
+	r.mutex.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(breakers))
+	for _, breaker := range breakers {
+		snapshots = append(snapshots, breaker.Snapshot())
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Destination < snapshots[j].Destination
+	})
+
+	return snapshots
+}
@@ -0,0 +1,184 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock for deterministic state transitions
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.current
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.current = c.current.Add(d)
+}
+
+func newTestRegistry(clock *fakeClock) *Registry {
+	registry := NewRegistry(Settings{
+		FailureThreshold: 3,
+		Interval:         10 * time.Second,
+		Timeout:          5 * time.Second,
+	})
+	registry.now = clock.now
+	return registry
+}
+
+func TestBreaker_TripsAfterThreshold(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	breaker := newTestRegistry(clock).Get("http://subscriber/webhook")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, breaker.Allow())
+		breaker.RecordFailure()
+	}
+	assert.Equal(t, StateClosed, breaker.State())
+
+	require.NoError(t, breaker.Allow())
+	breaker.RecordFailure()
+	assert.Equal(t, StateOpen, breaker.State())
+
+	assert.ErrorIs(t, breaker.Allow(), ErrOpen)
+	assert.Equal(t, clock.current.Add(5*time.Second), breaker.RetryAfter())
+}
+
+func TestBreaker_FailuresResetAfterInterval(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	breaker := newTestRegistry(clock).Get("dest")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, breaker.Allow())
+		breaker.RecordFailure()
+	}
+
+	clock.advance(11 * time.Second)
+
+	require.NoError(t, breaker.Allow())
+	breaker.RecordFailure()
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.Equal(t, uint32(1), breaker.Snapshot().Failures)
+}
+
+func TestBreaker_HalfOpenRecovery(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	breaker := newTestRegistry(clock).Get("http://recovering/webhook")
+	gauge := circuitState.WithLabelValues("http://recovering/webhook")
+	assert.Equal(t, float64(StateClosed), testutil.ToFloat64(gauge))
+
+	for i := 0; i < 3; i++ {
+		breaker.RecordFailure()
+	}
+	require.Equal(t, StateOpen, breaker.State())
+	assert.Equal(t, float64(StateOpen), testutil.ToFloat64(gauge))
+
+	clock.advance(6 * time.Second)
+
+	// One trial request is allowed, concurrent ones are still rejected
+	require.NoError(t, breaker.Allow())
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	assert.Equal(t, float64(StateHalfOpen), testutil.ToFloat64(gauge))
+	assert.ErrorIs(t, breaker.Allow(), ErrOpen)
+
+	breaker.RecordSuccess()
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.Equal(t, float64(StateClosed), testutil.ToFloat64(gauge))
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestBreaker_HalfOpenFailureReopens(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	breaker := newTestRegistry(clock).Get("dest")
+
+	for i := 0; i < 3; i++ {
+		breaker.RecordFailure()
+	}
+	clock.advance(6 * time.Second)
+
+	require.NoError(t, breaker.Allow())
+	breaker.RecordFailure()
+
+	assert.Equal(t, StateOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), ErrOpen)
+}
+
+func TestBreaker_HalfOpenCancelledAllowsNextTrial(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	breaker := newTestRegistry(clock).Get("dest")
+
+	for i := 0; i < 3; i++ {
+		breaker.RecordFailure()
+	}
+	clock.advance(6 * time.Second)
+
+	require.NoError(t, breaker.Allow())
+	breaker.RecordCancelled()
+
+	// The abandoned trial is neither a success nor a failure
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), ErrOpen)
+}
+
+func TestRegistry_TracksDestinationsIndependently(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	registry := newTestRegistry(clock)
+
+	for i := 0; i < 3; i++ {
+		registry.Get("http://b/webhook").RecordFailure()
+	}
+	registry.Get("http://a/webhook").RecordSuccess()
+
+	assert.Same(t, registry.Get("http://b/webhook"), registry.Get("http://b/webhook"))
+	assert.NoError(t, registry.Get("http://a/webhook").Allow())
+	assert.ErrorIs(t, registry.Get("http://b/webhook").Allow(), ErrOpen)
+
+	snapshots := registry.Snapshots()
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "http://a/webhook", snapshots[0].Destination)
+	assert.Equal(t, "CLOSED", snapshots[0].State)
+	assert.Nil(t, snapshots[0].OpenedAt)
+	assert.Equal(t, "http://b/webhook", snapshots[1].Destination)
+	assert.Equal(t, "OPEN", snapshots[1].State)
+	assert.Equal(t, uint64(1), snapshots[1].TotalRejections)
+	require.NotNil(t, snapshots[1].RetryAfter)
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	registry := newTestRegistry(clock)
+
+	for i := 0; i < 3; i++ {
+		registry.Get("http://removed/webhook").RecordFailure()
+	}
+	registry.Get("http://kept/webhook")
+
+	registry.Remove("http://removed/webhook")
+
+	snapshots := registry.Snapshots()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "http://kept/webhook", snapshots[0].Destination)
+	assert.NoError(t, registry.Get("http://removed/webhook").Allow(), "a removed destination starts with a fresh breaker")
+}
+
+func TestNewRegistry_Defaults(t *testing.T) {
+	registry := NewRegistry(Settings{})
+
+	assert.Equal(t, uint32(5), registry.settings.FailureThreshold)
+	assert.Equal(t, 10*time.Second, registry.settings.Interval)
+	assert.Equal(t, 5*time.Second, registry.settings.Timeout)
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "CLOSED", StateClosed.String())
+	assert.Equal(t, "OPEN", StateOpen.String())
+	assert.Equal(t, "HALF-OPEN", StateHalfOpen.String())
+	assert.Equal(t, "UNKNOWN", State(42).String())
+}
@@ -0,0 +1,19 @@
+package circuit
+
+import (
+	observability "github.com/jared-scarr/portfolio-monorepo/packages/observability/handlers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// circuitState holds the State of each destination's breaker
+var circuitState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "outbox_circuit_state",
+		Help: "Circuit breaker state per destination: 0 closed, 1 open, 2 half-open",
+	},
+	[]string{"destination"},
+)
+
+func init() {
+	observability.MustRegister(circuitState)
+}
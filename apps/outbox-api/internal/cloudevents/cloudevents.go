@@ -0,0 +1,306 @@
+// Package cloudevents maps outbox events to and from CloudEvents 1.0
+// (https://github.com/cloudevents/spec) in the JSON event format and the
+// HTTP protocol binding's structured and binary content modes.
+//
+// Only JSON data is supported: an outbox event's data is always JSON, so
+// events carrying data_base64 or a non-JSON datacontenttype are rejected.
+package cloudevents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+const (
+	// SpecVersion is the only CloudEvents version accepted and produced
+	SpecVersion = "1.0"
+	// ContentType marks a structured-mode CloudEvent in JSON format
+	ContentType = "application/cloudevents+json"
+	// HeaderPrefix precedes attribute names in binary-mode HTTP headers
+	HeaderPrefix = "ce-"
+	// PartitionKeyAttribute is the partitioning extension attribute, which
+	// carries an event's partition key
+	PartitionKeyAttribute = "partitionkey"
+)
+
+// ErrUnsupportedData is returned for events whose data is not JSON
+var ErrUnsupportedData = errors.New("cloudevent data must be JSON")
+
+// attributeName matches valid CloudEvents attribute names
+var attributeName = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+// reserved attributes are set from the event itself and never from metadata
+var reserved = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"datacontenttype": true,
+	"data":            true,
+	"data_base64":     true,
+}
+
+// Event is a CloudEvent with JSON data
+type Event struct {
+	ID              string
+	Source          string
+	Type            string
+	DataContentType string
+	Data            json.RawMessage
+	// Extensions holds the optional attributes (time, subject, dataschema)
+	// and any extension attributes
+	Extensions map[string]interface{}
+}
+
+// FromEvent converts an outbox event. Scalar metadata entries whose keys are
+// valid attribute names become attributes, so events created from CloudEvents
+// are published with the attributes they arrived with. The time attribute
+// defaults to the event's creation time, and a partition key is sent as the
+// partitionkey extension.
+func FromEvent(event *models.Event) Event {
+	ce := Event{
+		ID:              event.ID,
+		Source:          event.Source,
+		Type:            event.Type,
+		DataContentType: "application/json",
+		Data:            event.Data,
+		Extensions: map[string]interface{}{
+			"time": event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+
+	var metadata map[string]interface{}
+	if len(event.Metadata) > 0 && json.Unmarshal(event.Metadata, &metadata) == nil {
+		for name, value := range metadata {
+			if reserved[name] || !attributeName.MatchString(name) {
+				continue
+			}
+			switch value.(type) {
+			case string, float64, bool:
+				ce.Extensions[name] = value
+			}
+		}
+	}
+
+	if event.PartitionKey != "" {
+		ce.Extensions[PartitionKeyAttribute] = event.PartitionKey
+	}
+
+	return ce
+}
+
+// MarshalJSON encodes the event in the structured-mode JSON format
+func (e Event) MarshalJSON() ([]byte, error) {
+	envelope := make(map[string]interface{}, len(e.Extensions)+6)
+	for name, value := range e.Extensions {
+		envelope[name] = value
+	}
+	envelope["specversion"] = SpecVersion
+	envelope["id"] = e.ID
+	envelope["source"] = e.Source
+	envelope["type"] = e.Type
+	if e.DataContentType != "" {
+		envelope["datacontenttype"] = e.DataContentType
+	}
+	if len(e.Data) > 0 {
+		envelope["data"] = e.Data
+	}
+	return json.Marshal(envelope)
+}
+
+// Attributes returns the context attributes carried outside the body in
+// binary mode, keyed by attribute name. datacontenttype is left out because
+// it travels as the message's content type.
+func (e Event) Attributes() map[string]string {
+	attributes := map[string]string{
+		"specversion": SpecVersion,
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+	}
+	for name, value := range e.Extensions {
+		attributes[name] = fmt.Sprint(value)
+	}
+	return attributes
+}
+
+// CreateEventRequest converts the CloudEvent into a request to create an
+// outbox event. The optional and extension attributes are kept as metadata,
+// except partitionkey which becomes the partition key. The source and id
+// become the idempotency key: together they identify an event, so a
+// redelivered CloudEvent is only stored once.
+func (e Event) CreateEventRequest() (models.CreateEventRequest, error) {
+	req := models.CreateEventRequest{
+		Type:           e.Type,
+		Source:         e.Source,
+		Data:           e.Data,
+		IdempotencyKey: e.Source + " " + e.ID,
+	}
+	if len(req.Data) == 0 {
+		req.Data = json.RawMessage("null")
+	}
+
+	extensions := make(map[string]interface{}, len(e.Extensions))
+	for name, value := range e.Extensions {
+		if name == PartitionKeyAttribute {
+			req.PartitionKey = fmt.Sprint(value)
+			continue
+		}
+		extensions[name] = value
+	}
+
+	if len(extensions) > 0 {
+		metadata, err := json.Marshal(extensions)
+		if err != nil {
+			return req, fmt.Errorf("failed to marshal cloudevent attributes: %w", err)
+		}
+		req.Metadata = metadata
+	}
+
+	return req, nil
+}
+
+// IsStructured reports whether a content type marks a structured-mode event
+func IsStructured(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentType
+}
+
+// IsBinary reports whether headers carry a binary-mode event
+func IsBinary(header http.Header) bool {
+	return header.Get(HeaderPrefix+"specversion") != ""
+}
+
+// ParseStructured decodes a structured-mode event
+func ParseStructured(body []byte) (*Event, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid cloudevent: %w", err)
+	}
+
+	event := &Event{Extensions: map[string]interface{}{}}
+	var specVersion string
+	for name, raw := range envelope {
+		switch name {
+		case "data":
+			event.Data = raw
+			continue
+		case "data_base64":
+			return nil, ErrUnsupportedData
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("invalid cloudevent attribute %q: %w", name, err)
+		}
+		if value == nil {
+			continue
+		}
+
+		switch name {
+		case "specversion":
+			specVersion, _ = value.(string)
+		case "id":
+			event.ID, _ = value.(string)
+		case "source":
+			event.Source, _ = value.(string)
+		case "type":
+			event.Type, _ = value.(string)
+		case "datacontenttype":
+			event.DataContentType, _ = value.(string)
+		default:
+			if !attributeName.MatchString(name) {
+				return nil, fmt.Errorf("invalid cloudevent attribute name %q", name)
+			}
+			switch value.(type) {
+			case string, float64, bool:
+				event.Extensions[name] = value
+			default:
+				return nil, fmt.Errorf("cloudevent attribute %q must be a string, number or boolean", name)
+			}
+		}
+	}
+
+	if err := event.validate(specVersion); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ParseBinary decodes a binary-mode event from HTTP headers and body
+func ParseBinary(header http.Header, body []byte) (*Event, error) {
+	event := &Event{
+		DataContentType: header.Get("Content-Type"),
+		Extensions:      map[string]interface{}{},
+	}
+	if len(body) > 0 {
+		event.Data = body
+	}
+
+	var specVersion string
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, HeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		name = strings.TrimPrefix(name, HeaderPrefix)
+		value := values[0]
+
+		switch name {
+		case "specversion":
+			specVersion = value
+		case "id":
+			event.ID = value
+		case "source":
+			event.Source = value
+		case "type":
+			event.Type = value
+		default:
+			if !attributeName.MatchString(name) {
+				return nil, fmt.Errorf("invalid cloudevent attribute name %q", name)
+			}
+			event.Extensions[name] = value
+		}
+	}
+
+	if err := event.validate(specVersion); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// validate checks the required attributes and that the data is JSON
+func (e *Event) validate(specVersion string) error {
+	if specVersion != SpecVersion {
+		return fmt.Errorf("unsupported cloudevents specversion %q", specVersion)
+	}
+	if e.ID == "" {
+		return fmt.Errorf("cloudevent id is required")
+	}
+	if e.Source == "" {
+		return fmt.Errorf("cloudevent source is required")
+	}
+	if e.Type == "" {
+		return fmt.Errorf("cloudevent type is required")
+	}
+
+	if e.DataContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(e.DataContentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return ErrUnsupportedData
+		}
+	}
+	if len(e.Data) > 0 && !json.Valid(e.Data) {
+		return fmt.Errorf("invalid JSON in cloudevent data")
+	}
+
+	return nil
+}
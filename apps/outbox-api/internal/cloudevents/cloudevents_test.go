@@ -0,0 +1,251 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEvent(t *testing.T) {
+	event := &models.Event{
+		ID:        "event-1",
+		Type:      "order.created",
+		Source:    "order-service",
+		Data:      json.RawMessage(`{"order_id":"123"}`),
+		Metadata:  json.RawMessage(`{"subject": "order-123", "attempt": 2, "id": "spoofed", "Upper": "x", "nested": {"a": 1}}`),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	body, err := json.Marshal(FromEvent(event))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "event-1",
+		"source": "order-service",
+		"type": "order.created",
+		"time": "2024-01-02T03:04:05Z",
+		"subject": "order-123",
+		"attempt": 2,
+		"datacontenttype": "application/json",
+		"data": {"order_id": "123"}
+	}`, string(body))
+}
+
+func TestFromEvent_MetadataTimeWins(t *testing.T) {
+	event := &models.Event{
+		ID:        "event-1",
+		Metadata:  json.RawMessage(`{"time": "2023-12-31T23:59:59Z"}`),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	assert.Equal(t, "2023-12-31T23:59:59Z", FromEvent(event).Attributes()["time"])
+}
+
+func TestFromEvent_PartitionKey(t *testing.T) {
+	event := &models.Event{
+		ID:           "event-1",
+		PartitionKey: "order-123",
+		CreatedAt:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	assert.Equal(t, "order-123", FromEvent(event).Attributes()["partitionkey"])
+}
+
+func TestParseStructured(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expected      *Event
+		expectedError string
+	}{
+		{
+			name: "valid event",
+			body: `{"specversion": "1.0", "id": "A234-1234", "source": "/orders", "type": "order.created",
+				"subject": "order-123", "datacontenttype": "application/json", "data": {"order_id": "123"}}`,
+			expected: &Event{
+				ID:              "A234-1234",
+				Source:          "/orders",
+				Type:            "order.created",
+				DataContentType: "application/json",
+				Data:            json.RawMessage(`{"order_id": "123"}`),
+				Extensions:      map[string]interface{}{"subject": "order-123"},
+			},
+		},
+		{
+			name:          "missing id",
+			body:          `{"specversion": "1.0", "source": "/orders", "type": "order.created"}`,
+			expectedError: "cloudevent id is required",
+		},
+		{
+			name:          "unsupported specversion",
+			body:          `{"specversion": "0.3", "id": "1", "source": "/orders", "type": "order.created"}`,
+			expectedError: `unsupported cloudevents specversion "0.3"`,
+		},
+		{
+			name:          "base64 data",
+			body:          `{"specversion": "1.0", "id": "1", "source": "/orders", "type": "order.created", "data_base64": "AAEC"}`,
+			expectedError: ErrUnsupportedData.Error(),
+		},
+		{
+			name:          "non-JSON data",
+			body:          `{"specversion": "1.0", "id": "1", "source": "/orders", "type": "order.created", "datacontenttype": "text/plain", "data": "hi"}`,
+			expectedError: ErrUnsupportedData.Error(),
+		},
+		{
+			name:          "invalid extension name",
+			body:          `{"specversion": "1.0", "id": "1", "source": "/orders", "type": "order.created", "Trace_ID": "x"}`,
+			expectedError: `invalid cloudevent attribute name "Trace_ID"`,
+		},
+		{
+			name:          "object extension",
+			body:          `{"specversion": "1.0", "id": "1", "source": "/orders", "type": "order.created", "trace": {"id": "x"}}`,
+			expectedError: `cloudevent attribute "trace" must be a string, number or boolean`,
+		},
+		{
+			name:          "not JSON",
+			body:          `not json`,
+			expectedError: "invalid cloudevent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseStructured([]byte(tt.body))
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.ID, event.ID)
+			assert.Equal(t, tt.expected.Source, event.Source)
+			assert.Equal(t, tt.expected.Type, event.Type)
+			assert.Equal(t, tt.expected.DataContentType, event.DataContentType)
+			assert.JSONEq(t, string(tt.expected.Data), string(event.Data))
+			assert.Equal(t, tt.expected.Extensions, event.Extensions)
+		})
+	}
+}
+
+func TestParseBinary(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        map[string]string
+		body          string
+		expectedError string
+	}{
+		{
+			name: "valid event",
+			header: map[string]string{
+				"Content-Type":   "application/json; charset=utf-8",
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "A234-1234",
+				"Ce-Source":      "/orders",
+				"Ce-Type":        "order.created",
+				"Ce-Subject":     "order-123",
+			},
+			body: `{"order_id": "123"}`,
+		},
+		{
+			name: "missing type",
+			header: map[string]string{
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "A234-1234",
+				"Ce-Source":      "/orders",
+			},
+			expectedError: "cloudevent type is required",
+		},
+		{
+			name: "non-JSON content type",
+			header: map[string]string{
+				"Content-Type":   "application/octet-stream",
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "A234-1234",
+				"Ce-Source":      "/orders",
+				"Ce-Type":        "order.created",
+			},
+			body:          "\x00\x01",
+			expectedError: ErrUnsupportedData.Error(),
+		},
+		{
+			name: "invalid JSON body",
+			header: map[string]string{
+				"Content-Type":   "application/json",
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "A234-1234",
+				"Ce-Source":      "/orders",
+				"Ce-Type":        "order.created",
+			},
+			body:          `{"order_id":`,
+			expectedError: "invalid JSON in cloudevent data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			require.True(t, IsBinary(header))
+
+			event, err := ParseBinary(header, []byte(tt.body))
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "A234-1234", event.ID)
+			assert.Equal(t, "/orders", event.Source)
+			assert.Equal(t, "order.created", event.Type)
+			assert.JSONEq(t, tt.body, string(event.Data))
+			assert.Equal(t, map[string]interface{}{"subject": "order-123"}, event.Extensions)
+		})
+	}
+}
+
+func TestEvent_CreateEventRequest(t *testing.T) {
+	event := Event{
+		ID:         "A234-1234",
+		Source:     "/orders",
+		Type:       "order.created",
+		Data:       json.RawMessage(`{"order_id": "123"}`),
+		Extensions: map[string]interface{}{"subject": "order-123", "partitionkey": "order-123"},
+	}
+
+	req, err := event.CreateEventRequest()
+	require.NoError(t, err)
+
+	assert.Equal(t, "order.created", req.Type)
+	assert.Equal(t, "/orders", req.Source)
+	assert.JSONEq(t, `{"order_id": "123"}`, string(req.Data))
+	assert.JSONEq(t, `{"subject": "order-123"}`, string(req.Metadata))
+	assert.Equal(t, "/orders A234-1234", req.IdempotencyKey)
+	assert.Equal(t, "order-123", req.PartitionKey)
+	require.NoError(t, req.Validate())
+
+	event.Data = nil
+	event.Extensions = nil
+	req, err = event.CreateEventRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(req.Data))
+	assert.Nil(t, req.Metadata)
+}
+
+func TestIsStructured(t *testing.T) {
+	assert.True(t, IsStructured("application/cloudevents+json"))
+	assert.True(t, IsStructured("application/cloudevents+json; charset=utf-8"))
+	assert.False(t, IsStructured("application/json"))
+	assert.False(t, IsStructured(""))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config holds all configuration for the outbox-api service
@@ -15,14 +16,65 @@ type Config struct {
 	Publish      PublishConfig      `json:"publish"`
 	Circuit      CircuitConfig      `json:"circuit"`
 	FeatureFlags FeatureFlagsConfig `json:"feature_flags"`
+	Kafka        KafkaConfig        `json:"kafka"`
+	NATS         NATSConfig         `json:"nats"`
+	SQS          SQSConfig          `json:"sqs"`
+	Retention    RetentionConfig    `json:"retention"`
+	Stream       StreamConfig       `json:"stream"`
+	Metrics      MetricsConfig      `json:"metrics"`
 }
 
+// Publisher backends selectable with PUBLISHER
+const (
+	PublisherWebhook = "webhook"
+	PublisherKafka   = "kafka"
+	PublisherNATS    = "nats"
+	PublisherSQS     = "sqs"
+)
+
+// Event encodings selectable with EVENT_FORMAT
+const (
+	EventFormatJSON                  = "json"
+	EventFormatCloudEventsStructured = "cloudevents-structured"
+	EventFormatCloudEventsBinary     = "cloudevents-binary"
+)
+
+// Schema validation modes selectable with SCHEMA_VALIDATION
+const (
+	// SchemaValidationLenient accepts events of types with no registered schema
+	SchemaValidationLenient = "lenient"
+	// SchemaValidationStrict rejects events of types with no registered schema
+	SchemaValidationStrict = "strict"
+)
+
+// Retention modes selectable with RETENTION_MODE
+const (
+	// RetentionModeDelete deletes expired events
+	RetentionModeDelete = "delete"
+	// RetentionModeArchive moves expired events to outbox_events_archive
+	RetentionModeArchive = "archive"
+	// RetentionModeNDJSON writes expired events to gzipped NDJSON files
+	// before deleting them
+	RetentionModeNDJSON = "ndjson"
+)
+
 // ServerConfig holds server-specific configuration
 type ServerConfig struct {
 	Port         string   `json:"port"`
 	ReadTimeout  string   `json:"read_timeout"`
 	WriteTimeout string   `json:"write_timeout"`
 	CORSOrigins  []string `json:"cors_origins"`
+	// ShutdownDelay is how long /ready reports 503 before the server stops
+	// accepting connections, so load balancers stop routing to it first
+	ShutdownDelay string `json:"shutdown_delay"`
+	// ShutdownTimeout bounds how long in-flight requests, relay batches and
+	// bulk jobs get to finish once the server has stopped accepting work
+	ShutdownTimeout string `json:"shutdown_timeout"`
+	// IdempotencyRetention is how long an Idempotency-Key is remembered
+	IdempotencyRetention string `json:"idempotency_retention"`
+	// SchemaValidation decides what happens to events whose type has no
+	// registered schema: lenient accepts them, strict rejects them
+	SchemaValidation string `json:"schema_validation"`
 }
 
 // DatabaseConfig holds database connection configuration
@@ -33,6 +85,11 @@ type DatabaseConfig struct {
 	Password string `json:"password"`
 	DBName   string `json:"dbname"`
 	SSLMode  string `json:"sslmode"`
+	// AutoMigrate applies pending schema migrations on startup
+	AutoMigrate bool `json:"auto_migrate"`
+	// QueryTimeout bounds each storage operation, including the statements
+	// of a transaction
+	QueryTimeout string `json:"query_timeout"`
 }
 
 // PublishConfig holds publishing configuration
@@ -43,13 +100,93 @@ type PublishConfig struct {
 	RetryDelay    string `json:"retry_delay"`
 	MaxRetryDelay string `json:"max_retry_delay"`
 	WebhookURL    string `json:"webhook_url"`
+	RelayEnabled  bool   `json:"relay_enabled"`
+	LeaseDuration string `json:"lease_duration"`
+	// WebhookSecret signs deliveries to WebhookURL; WebhookPreviousSecret also
+	// signs them while consumers rotate to a new secret
+	WebhookSecret         string `json:"webhook_secret"`
+	WebhookPreviousSecret string `json:"webhook_previous_secret"`
+	// Publisher selects where events without a matching subscription are
+	// published: webhook, kafka, nats or sqs
+	Publisher string `json:"publisher"`
+	// EventFormat selects how published events are encoded: json, or
+	// CloudEvents 1.0 in structured or binary content mode
+	EventFormat string `json:"event_format"`
+	// Concurrency caps how many partition keys are published in parallel
+	// within a batch; events sharing a key are always published one at a time
+	Concurrency int `json:"concurrency"`
+	// PriorityAging is how long a waiting event takes to gain one priority
+	// level, so that low-priority events are not starved by urgent ones
+	PriorityAging string `json:"priority_aging"`
+	// PublishTimeout bounds each delivery of an event to one destination
+	PublishTimeout string `json:"publish_timeout"`
 }
 
 // CircuitConfig holds circuit breaker configuration
 type CircuitConfig struct {
-	MaxRequests uint32 `json:"max_requests"`
-	Interval    string `json:"interval"`
-	Timeout     string `json:"timeout"`
+	// FailureThreshold is how many consecutive failures within Interval
+	// open a destination's circuit
+	FailureThreshold uint32 `json:"failure_threshold"`
+	Interval         string `json:"interval"`
+	Timeout          string `json:"timeout"`
+}
+
+// KafkaConfig holds configuration for the Kafka publisher
+type KafkaConfig struct {
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+}
+
+// NATSConfig holds configuration for the NATS JetStream publisher
+type NATSConfig struct {
+	URL string `json:"url"`
+	// Subject is prefixed to the event type; it must be bound to a stream
+	Subject string `json:"subject"`
+}
+
+// SQSConfig holds configuration for the SQS publisher
+type SQSConfig struct {
+	QueueURL string `json:"queue_url"`
+	Region   string `json:"region"`
+	// Endpoint overrides the AWS endpoint, e.g. for ElasticMQ or LocalStack
+	Endpoint string `json:"endpoint"`
+}
+
+// RetentionConfig holds the policy for pruning finished events from
+// outbox_events. An empty age keeps events in that status forever.
+type RetentionConfig struct {
+	// Published, Failed and Expired are how long after their last update
+	// events in each of those statuses are kept
+	Published string `json:"published"`
+	Failed    string `json:"failed"`
+	Expired   string `json:"expired"`
+	Interval  string `json:"interval"`
+	BatchSize int    `json:"batch_size"`
+	// Mode selects what happens to expired events: delete, archive or ndjson
+	Mode string `json:"mode"`
+	// ArchiveDir is where ndjson mode writes its files
+	ArchiveDir string `json:"archive_dir"`
+	// Changes is how long the event change log is kept for stream clients
+	// resuming with Last-Event-ID
+	Changes string `json:"changes"`
+}
+
+// StreamConfig holds configuration for the event change stream
+type StreamConfig struct {
+	Enabled bool `json:"enabled"`
+	// Heartbeat is how often an idle stream sends a comment to keep
+	// proxies from closing the connection
+	Heartbeat string `json:"heartbeat"`
+	// Buffer is how many changes a client may fall behind by before it is
+	// disconnected to resume from the change log
+	Buffer int `json:"buffer"`
+}
+
+// MetricsConfig holds configuration for the outbox Prometheus metrics
+type MetricsConfig struct {
+	// SampleInterval is how often gauges read from Postgres, such as the
+	// queue depth, are refreshed
+	SampleInterval string `json:"sample_interval"`
 }
 
 // FeatureFlagsConfig holds feature flag service configuration
@@ -62,36 +199,76 @@ type FeatureFlagsConfig struct {
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:         "8080",
-			ReadTimeout:  "30s",
-			WriteTimeout: "30s",
-			CORSOrigins:  []string{"http://localhost:3000", "http://portfolio:3000"},
+			Port:                 "8080",
+			ReadTimeout:          "30s",
+			CORSOrigins:          []string{"http://localhost:3000", "http://portfolio:3000"},
+			ShutdownDelay:        "5s",
+			ShutdownTimeout:      "30s",
+			IdempotencyRetention: "24h",
+			SchemaValidation:     SchemaValidationLenient,
 		},
 		Database: DatabaseConfig{
-			Host:     "localhost",
-			Port:     5432,
-			User:     "postgres",
-			Password: "password",
-			DBName:   "outbox",
-			SSLMode:  "disable",
+			Host:         "localhost",
+			Port:         5432,
+			User:         "postgres",
+			Password:     "password",
+			DBName:       "outbox",
+			SSLMode:      "disable",
+			AutoMigrate:  true,
+			QueryTimeout: "10s",
 		},
 		Publish: PublishConfig{
-			BatchSize:     10,
-			BatchTimeout:  "5s",
-			RetryAttempts: 3,
-			RetryDelay:    "1s",
-			MaxRetryDelay: "30s",
-			WebhookURL:    "http://localhost:3000/api/webhook",
+			BatchSize:      10,
+			BatchTimeout:   "5s",
+			RetryAttempts:  3,
+			RetryDelay:     "1s",
+			MaxRetryDelay:  "30s",
+			WebhookURL:     "http://localhost:3000/api/webhook",
+			RelayEnabled:   true,
+			LeaseDuration:  "60s",
+			Publisher:      PublisherWebhook,
+			EventFormat:    EventFormatJSON,
+			Concurrency:    4,
+			PriorityAging:  "1m",
+			PublishTimeout: "30s",
 		},
 		Circuit: CircuitConfig{
-			MaxRequests: 5,
-			Interval:    "10s",
-			Timeout:     "5s",
+			FailureThreshold: 5,
+			Interval:         "10s",
+			Timeout:          "5s",
 		},
 		FeatureFlags: FeatureFlagsConfig{
 			BaseURL:     "http://localhost:4000",
 			Environment: "local",
 		},
+		Kafka: KafkaConfig{
+			Brokers: []string{"localhost:9092"},
+			Topic:   "outbox-events",
+		},
+		NATS: NATSConfig{
+			URL:     "nats://localhost:4222",
+			Subject: "outbox.events",
+		},
+		SQS: SQSConfig{
+			Region: "us-east-1",
+		},
+		Retention: RetentionConfig{
+			Published:  "168h",
+			Expired:    "168h",
+			Interval:   "10m",
+			BatchSize:  1000,
+			Mode:       RetentionModeDelete,
+			ArchiveDir: "archive",
+			Changes:    "24h",
+		},
+		Stream: StreamConfig{
+			Enabled:   true,
+			Heartbeat: "15s",
+			Buffer:    256,
+		},
+		Metrics: MetricsConfig{
+			SampleInterval: "15s",
+		},
 	}
 
 	// Load from .env file if it exists
@@ -103,6 +280,13 @@ func Load() (*Config, error) {
 	// Override with environment variables
 	loadFromEnv(cfg)
 
+	// A publisher renews an event's lease before publishing it, so the lease
+	// only has to outlast one delivery for the publisher to still hold it when
+	// the outcome is recorded
+	if lease, deadline := cfg.Publish.Lease(), cfg.Publish.DeliveryDeadline(); lease <= deadline {
+		return nil, fmt.Errorf("LEASE_DURATION (%s) must be longer than PUBLISH_TIMEOUT (%s)", lease, deadline)
+	}
+
 	return cfg, nil
 }
 
@@ -141,6 +325,30 @@ func loadFromEnv(cfg *Config) {
 		cfg.Server.Port = port
 	}
 
+	if readTimeout := os.Getenv("READ_TIMEOUT"); readTimeout != "" {
+		if _, err := time.ParseDuration(readTimeout); err == nil {
+			cfg.Server.ReadTimeout = readTimeout
+		}
+	}
+
+	if writeTimeout := os.Getenv("WRITE_TIMEOUT"); writeTimeout != "" {
+		if _, err := time.ParseDuration(writeTimeout); err == nil {
+			cfg.Server.WriteTimeout = writeTimeout
+		}
+	}
+
+	if shutdownDelay := os.Getenv("SHUTDOWN_DELAY"); shutdownDelay != "" {
+		if _, err := time.ParseDuration(shutdownDelay); err == nil {
+			cfg.Server.ShutdownDelay = shutdownDelay
+		}
+	}
+
+	if shutdownTimeout := os.Getenv("SHUTDOWN_TIMEOUT"); shutdownTimeout != "" {
+		if _, err := time.ParseDuration(shutdownTimeout); err == nil {
+			cfg.Server.ShutdownTimeout = shutdownTimeout
+		}
+	}
+
 	if host := os.Getenv("DB_HOST"); host != "" {
 		cfg.Database.Host = host
 	}
@@ -161,15 +369,130 @@ func loadFromEnv(cfg *Config) {
 	if sslmode := os.Getenv("DB_SSLMODE"); sslmode != "" {
 		cfg.Database.SSLMode = sslmode
 	}
+	if autoMigrate := os.Getenv("DB_AUTO_MIGRATE"); autoMigrate != "" {
+		if enabled, err := strconv.ParseBool(autoMigrate); err == nil {
+			cfg.Database.AutoMigrate = enabled
+		}
+	}
+	if queryTimeout := os.Getenv("DB_QUERY_TIMEOUT"); queryTimeout != "" {
+		if _, err := time.ParseDuration(queryTimeout); err == nil {
+			cfg.Database.QueryTimeout = queryTimeout
+		}
+	}
 
 	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
 		cfg.Publish.WebhookURL = webhookURL
 	}
+	if webhookSecret := os.Getenv("WEBHOOK_SECRET"); webhookSecret != "" {
+		cfg.Publish.WebhookSecret = webhookSecret
+	}
+	if previousSecret := os.Getenv("WEBHOOK_PREVIOUS_SECRET"); previousSecret != "" {
+		cfg.Publish.WebhookPreviousSecret = previousSecret
+	}
 	if batchSize := os.Getenv("BATCH_SIZE"); batchSize != "" {
 		if bs, err := strconv.Atoi(batchSize); err == nil {
 			cfg.Publish.BatchSize = bs
 		}
 	}
+	if batchTimeout := os.Getenv("BATCH_TIMEOUT"); batchTimeout != "" {
+		if _, err := time.ParseDuration(batchTimeout); err == nil {
+			cfg.Publish.BatchTimeout = batchTimeout
+		}
+	}
+	if retryAttempts := os.Getenv("RETRY_ATTEMPTS"); retryAttempts != "" {
+		if ra, err := strconv.Atoi(retryAttempts); err == nil && ra >= 0 {
+			cfg.Publish.RetryAttempts = ra
+		}
+	}
+	if retryDelay := os.Getenv("RETRY_DELAY"); retryDelay != "" {
+		if _, err := time.ParseDuration(retryDelay); err == nil {
+			cfg.Publish.RetryDelay = retryDelay
+		}
+	}
+	if maxRetryDelay := os.Getenv("MAX_RETRY_DELAY"); maxRetryDelay != "" {
+		if _, err := time.ParseDuration(maxRetryDelay); err == nil {
+			cfg.Publish.MaxRetryDelay = maxRetryDelay
+		}
+	}
+	if leaseDuration := os.Getenv("LEASE_DURATION"); leaseDuration != "" {
+		if _, err := time.ParseDuration(leaseDuration); err == nil {
+			cfg.Publish.LeaseDuration = leaseDuration
+		}
+	}
+	if concurrency := os.Getenv("PUBLISH_CONCURRENCY"); concurrency != "" {
+		if c, err := strconv.Atoi(concurrency); err == nil && c > 0 {
+			cfg.Publish.Concurrency = c
+		}
+	}
+	if priorityAging := os.Getenv("PRIORITY_AGING"); priorityAging != "" {
+		if _, err := time.ParseDuration(priorityAging); err == nil {
+			cfg.Publish.PriorityAging = priorityAging
+		}
+	}
+	if publishTimeout := os.Getenv("PUBLISH_TIMEOUT"); publishTimeout != "" {
+		if _, err := time.ParseDuration(publishTimeout); err == nil {
+			cfg.Publish.PublishTimeout = publishTimeout
+		}
+	}
+	if relayEnabled := os.Getenv("RELAY_ENABLED"); relayEnabled != "" {
+		if enabled, err := strconv.ParseBool(relayEnabled); err == nil {
+			cfg.Publish.RelayEnabled = enabled
+		}
+	}
+
+	if publisher := os.Getenv("PUBLISHER"); publisher != "" {
+		switch publisher = strings.ToLower(publisher); publisher {
+		case PublisherWebhook, PublisherKafka, PublisherNATS, PublisherSQS:
+			cfg.Publish.Publisher = publisher
+		}
+	}
+	if format := os.Getenv("EVENT_FORMAT"); format != "" {
+		switch format = strings.ToLower(format); format {
+		case EventFormatJSON, EventFormatCloudEventsStructured, EventFormatCloudEventsBinary:
+			cfg.Publish.EventFormat = format
+		}
+	}
+	if brokers := splitList(os.Getenv("KAFKA_BROKERS")); len(brokers) > 0 {
+		cfg.Kafka.Brokers = brokers
+	}
+	if topic := os.Getenv("KAFKA_TOPIC"); topic != "" {
+		cfg.Kafka.Topic = topic
+	}
+	if natsURL := os.Getenv("NATS_URL"); natsURL != "" {
+		cfg.NATS.URL = natsURL
+	}
+	if subject := os.Getenv("NATS_SUBJECT"); subject != "" {
+		cfg.NATS.Subject = subject
+	}
+	if queueURL := os.Getenv("SQS_QUEUE_URL"); queueURL != "" {
+		cfg.SQS.QueueURL = queueURL
+	}
+	if region := os.Getenv("SQS_REGION"); region != "" {
+		cfg.SQS.Region = region
+	}
+	if endpoint := os.Getenv("SQS_ENDPOINT"); endpoint != "" {
+		cfg.SQS.Endpoint = endpoint
+	}
+
+	// CIRCUIT_MAX_REQUESTS is the setting's old name, still read for
+	// existing deployments
+	for _, name := range []string{"CIRCUIT_MAX_REQUESTS", "CIRCUIT_FAILURE_THRESHOLD"} {
+		if threshold := os.Getenv(name); threshold != "" {
+			if ft, err := strconv.ParseUint(threshold, 10, 32); err == nil {
+				cfg.Circuit.FailureThreshold = uint32(ft)
+			}
+		}
+	}
+	if interval := os.Getenv("CIRCUIT_INTERVAL"); interval != "" {
+		if _, err := time.ParseDuration(interval); err == nil {
+			cfg.Circuit.Interval = interval
+		}
+	}
+	if timeout := os.Getenv("CIRCUIT_TIMEOUT"); timeout != "" {
+		if _, err := time.ParseDuration(timeout); err == nil {
+			cfg.Circuit.Timeout = timeout
+		}
+	}
 
 	if flagsURL := os.Getenv("FEATURE_FLAGS_API_URL"); flagsURL != "" {
 		cfg.FeatureFlags.BaseURL = flagsURL
@@ -178,17 +501,97 @@ func loadFromEnv(cfg *Config) {
 		cfg.FeatureFlags.Environment = flagsEnv
 	}
 
+	if retention := os.Getenv("IDEMPOTENCY_RETENTION"); retention != "" {
+		if _, err := time.ParseDuration(retention); err == nil {
+			cfg.Server.IdempotencyRetention = retention
+		}
+	}
+
+	if mode := os.Getenv("SCHEMA_VALIDATION"); mode != "" {
+		switch mode = strings.ToLower(mode); mode {
+		case SchemaValidationLenient, SchemaValidationStrict:
+			cfg.Server.SchemaValidation = mode
+		}
+	}
+
+	if published := os.Getenv("RETENTION_PUBLISHED"); published != "" {
+		if _, err := time.ParseDuration(published); err == nil {
+			cfg.Retention.Published = published
+		}
+	}
+	if failed := os.Getenv("RETENTION_FAILED"); failed != "" {
+		if _, err := time.ParseDuration(failed); err == nil {
+			cfg.Retention.Failed = failed
+		}
+	}
+	if expired := os.Getenv("RETENTION_EXPIRED"); expired != "" {
+		if _, err := time.ParseDuration(expired); err == nil {
+			cfg.Retention.Expired = expired
+		}
+	}
+	if interval := os.Getenv("RETENTION_INTERVAL"); interval != "" {
+		if _, err := time.ParseDuration(interval); err == nil {
+			cfg.Retention.Interval = interval
+		}
+	}
+	if batchSize := os.Getenv("RETENTION_BATCH_SIZE"); batchSize != "" {
+		if bs, err := strconv.Atoi(batchSize); err == nil && bs > 0 {
+			cfg.Retention.BatchSize = bs
+		}
+	}
+	if mode := os.Getenv("RETENTION_MODE"); mode != "" {
+		switch mode = strings.ToLower(mode); mode {
+		case RetentionModeDelete, RetentionModeArchive, RetentionModeNDJSON:
+			cfg.Retention.Mode = mode
+		}
+	}
+	if archiveDir := os.Getenv("RETENTION_ARCHIVE_DIR"); archiveDir != "" {
+		cfg.Retention.ArchiveDir = archiveDir
+	}
+	if changes := os.Getenv("RETENTION_CHANGES"); changes != "" {
+		if _, err := time.ParseDuration(changes); err == nil {
+			cfg.Retention.Changes = changes
+		}
+	}
+
+	if streamEnabled := os.Getenv("STREAM_ENABLED"); streamEnabled != "" {
+		if enabled, err := strconv.ParseBool(streamEnabled); err == nil {
+			cfg.Stream.Enabled = enabled
+		}
+	}
+	if heartbeat := os.Getenv("STREAM_HEARTBEAT"); heartbeat != "" {
+		if _, err := time.ParseDuration(heartbeat); err == nil {
+			cfg.Stream.Heartbeat = heartbeat
+		}
+	}
+	if buffer := os.Getenv("STREAM_BUFFER"); buffer != "" {
+		if b, err := strconv.Atoi(buffer); err == nil && b > 0 {
+			cfg.Stream.Buffer = b
+		}
+	}
+
+	if sampleInterval := os.Getenv("METRICS_SAMPLE_INTERVAL"); sampleInterval != "" {
+		if _, err := time.ParseDuration(sampleInterval); err == nil {
+			cfg.Metrics.SampleInterval = sampleInterval
+		}
+	}
+
 	if corsOrigins := os.Getenv("CORS_ALLOWED_ORIGINS"); corsOrigins != "" {
-		// Parse comma-separated origins
-		origins := strings.Split(corsOrigins, ",")
-		cfg.Server.CORSOrigins = make([]string, 0, len(origins))
-		for _, origin := range origins {
-			trimmed := strings.TrimSpace(origin)
-			if trimmed != "" {
-				cfg.Server.CORSOrigins = append(cfg.Server.CORSOrigins, trimmed)
-			}
+		cfg.Server.CORSOrigins = splitList(corsOrigins)
+	}
+}
+
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	list := make([]string, 0, len(parts))
+	for _, part := range parts {
+		trimmed := strings.TrimSpace(part)
+		if trimmed != "" {
+			list = append(list, trimmed)
 		}
 	}
+	return list
 }
 
 // DSN returns the database connection string
@@ -196,3 +599,131 @@ func (d *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		d.Host, d.Port, d.User, d.Password, d.DBName, d.SSLMode)
 }
+
+// QueryDeadline returns how long a storage operation may take
+func (d *DatabaseConfig) QueryDeadline() time.Duration {
+	return parseDuration(d.QueryTimeout, 10*time.Second)
+}
+
+// IdempotencyWindow returns how long an Idempotency-Key is remembered
+func (s *ServerConfig) IdempotencyWindow() time.Duration {
+	return parseDuration(s.IdempotencyRetention, 24*time.Hour)
+}
+
+// Timeouts returns how long the server may take to read a request and to
+// write its response
+func (s *ServerConfig) Timeouts() (time.Duration, time.Duration) {
+	return parseDuration(s.ReadTimeout, 30*time.Second), parseDuration(s.WriteTimeout, 30*time.Second)
+}
+
+// ServerTimeouts returns how long the server may take to read a request and
+// to write its response. Unless a write timeout is set, a response may take
+// the delivery deadline plus 30s, so /admin/publish is not cut off while an
+// event it is delivering still has time.
+func (c *Config) ServerTimeouts() (time.Duration, time.Duration) {
+	read, write := c.Server.Timeouts()
+	if c.Server.WriteTimeout == "" {
+		write = c.Publish.DeliveryDeadline() + 30*time.Second
+	}
+	return read, write
+}
+
+// DrainDelay returns how long the server keeps accepting connections after
+// reporting itself not ready; "0s" stops it right away
+func (s *ServerConfig) DrainDelay() time.Duration {
+	if d, err := time.ParseDuration(s.ShutdownDelay); err == nil && d >= 0 {
+		return d
+	}
+	return 5 * time.Second
+}
+
+// ShutdownDeadline returns how long in-flight work gets to finish on shutdown
+func (s *ServerConfig) ShutdownDeadline() time.Duration {
+	return parseDuration(s.ShutdownTimeout, 30*time.Second)
+}
+
+// BatchInterval returns how often the relay polls for pending events
+func (p *PublishConfig) BatchInterval() time.Duration {
+	return parseDuration(p.BatchTimeout, 5*time.Second)
+}
+
+// Lease returns how long a claimed event stays locked to one publisher
+func (p *PublishConfig) Lease() time.Duration {
+	return parseDuration(p.LeaseDuration, 60*time.Second)
+}
+
+// PriorityAgingInterval returns how long a waiting event takes to gain one
+// priority level
+func (p *PublishConfig) PriorityAgingInterval() time.Duration {
+	return parseDuration(p.PriorityAging, time.Minute)
+}
+
+// DeliveryDeadline returns how long one delivery of an event may take
+func (p *PublishConfig) DeliveryDeadline() time.Duration {
+	return parseDuration(p.PublishTimeout, 30*time.Second)
+}
+
+// Parallelism returns how many partition keys a batch publishes at once,
+// falling back to one at a time when unset
+func (p *PublishConfig) Parallelism() int {
+	if p.Concurrency < 1 {
+		return 1
+	}
+	return p.Concurrency
+}
+
+// RetryBackoff returns the initial and maximum delay between publish retries
+func (p *PublishConfig) RetryBackoff() (time.Duration, time.Duration) {
+	return parseDuration(p.RetryDelay, time.Second), parseDuration(p.MaxRetryDelay, 30*time.Second)
+}
+
+// WebhookSecrets returns the secrets deliveries to WebhookURL are signed with
+func (p *PublishConfig) WebhookSecrets() []string {
+	var secrets []string
+	for _, secret := range []string{p.WebhookSecret, p.WebhookPreviousSecret} {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// Durations returns the failure-counting interval and the open-state timeout
+func (c *CircuitConfig) Durations() (time.Duration, time.Duration) {
+	return parseDuration(c.Interval, 10*time.Second), parseDuration(c.Timeout, 5*time.Second)
+}
+
+// MaxAges returns how long published, failed and expired events are kept;
+// zero means forever. A "0s" age also disables pruning.
+func (r *RetentionConfig) MaxAges() (time.Duration, time.Duration, time.Duration) {
+	return parseDuration(r.Published, 0), parseDuration(r.Failed, 0), parseDuration(r.Expired, 0)
+}
+
+// ChangesMaxAge returns how long the event change log is kept; zero means
+// forever
+func (r *RetentionConfig) ChangesMaxAge() time.Duration {
+	return parseDuration(r.Changes, 0)
+}
+
+// HeartbeatInterval returns how often an idle stream sends a keepalive
+func (s *StreamConfig) HeartbeatInterval() time.Duration {
+	return parseDuration(s.Heartbeat, 15*time.Second)
+}
+
+// SamplingInterval returns how often gauges read from Postgres are refreshed
+func (m *MetricsConfig) SamplingInterval() time.Duration {
+	return parseDuration(m.SampleInterval, 15*time.Second)
+}
+
+// PruneInterval returns how often expired events are pruned
+func (r *RetentionConfig) PruneInterval() time.Duration {
+	return parseDuration(r.Interval, 10*time.Minute)
+}
+
+// parseDuration parses a duration string, falling back when it is empty or invalid
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,86 +20,210 @@ func TestLoad(t *testing.T) {
 			envVars: map[string]string{},
 			expected: &Config{
 				Server: ServerConfig{
-					Port:         "8080",
-					ReadTimeout:  "30s",
-					WriteTimeout: "30s",
-					CORSOrigins:  []string{"http://localhost:3000", "http://portfolio:3000"},
+					Port:                 "8080",
+					ReadTimeout:          "30s",
+					CORSOrigins:          []string{"http://localhost:3000", "http://portfolio:3000"},
+					ShutdownDelay:        "5s",
+					ShutdownTimeout:      "30s",
+					IdempotencyRetention: "24h",
+					SchemaValidation:     SchemaValidationLenient,
 				},
 				Database: DatabaseConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "postgres",
-					Password: "password",
-					DBName:   "outbox",
-					SSLMode:  "disable",
+					Host:         "localhost",
+					Port:         5432,
+					User:         "postgres",
+					Password:     "password",
+					DBName:       "outbox",
+					SSLMode:      "disable",
+					AutoMigrate:  true,
+					QueryTimeout: "10s",
 				},
 				Publish: PublishConfig{
-					BatchSize:     10,
-					BatchTimeout:  "5s",
-					RetryAttempts: 3,
-					RetryDelay:    "1s",
-					MaxRetryDelay: "30s",
-					WebhookURL:    "http://localhost:3000/api/webhook",
+					BatchSize:      10,
+					BatchTimeout:   "5s",
+					RetryAttempts:  3,
+					RetryDelay:     "1s",
+					MaxRetryDelay:  "30s",
+					WebhookURL:     "http://localhost:3000/api/webhook",
+					RelayEnabled:   true,
+					LeaseDuration:  "60s",
+					Publisher:      PublisherWebhook,
+					EventFormat:    EventFormatJSON,
+					Concurrency:    4,
+					PriorityAging:  "1m",
+					PublishTimeout: "30s",
 				},
 				Circuit: CircuitConfig{
-					MaxRequests: 5,
-					Interval:    "10s",
-					Timeout:     "5s",
+					FailureThreshold: 5,
+					Interval:         "10s",
+					Timeout:          "5s",
 				},
 				FeatureFlags: FeatureFlagsConfig{
 					BaseURL:     "http://localhost:4000",
 					Environment: "local",
 				},
+				Kafka: KafkaConfig{
+					Brokers: []string{"localhost:9092"},
+					Topic:   "outbox-events",
+				},
+				NATS: NATSConfig{
+					URL:     "nats://localhost:4222",
+					Subject: "outbox.events",
+				},
+				SQS: SQSConfig{
+					Region: "us-east-1",
+				},
+				Retention: RetentionConfig{
+					Published:  "168h",
+					Expired:    "168h",
+					Interval:   "10m",
+					BatchSize:  1000,
+					Mode:       RetentionModeDelete,
+					ArchiveDir: "archive",
+					Changes:    "24h",
+				},
+				Stream: StreamConfig{
+					Enabled:   true,
+					Heartbeat: "15s",
+					Buffer:    256,
+				},
+				Metrics: MetricsConfig{
+					SampleInterval: "15s",
+				},
 			},
 		},
 		{
 			name: "environment variable overrides",
 			envVars: map[string]string{
-				"PORT":                  "9090",
-				"DB_HOST":               "prod-db",
-				"DB_PORT":               "5433",
-				"DB_USER":               "admin",
-				"DB_PASSWORD":           "secret",
-				"DB_NAME":               "production",
-				"DB_SSLMODE":            "require",
-				"WEBHOOK_URL":           "https://api.example.com/webhook",
-				"BATCH_SIZE":            "20",
-				"FEATURE_FLAGS_API_URL": "https://flags.example.com",
-				"FEATURE_FLAGS_ENV":     "prod",
-				"CORS_ALLOWED_ORIGINS":  "https://example.com, https://api.example.com",
+				"PORT":                    "9090",
+				"DB_HOST":                 "prod-db",
+				"DB_PORT":                 "5433",
+				"DB_USER":                 "admin",
+				"DB_PASSWORD":             "secret",
+				"DB_NAME":                 "production",
+				"DB_SSLMODE":              "require",
+				"DB_AUTO_MIGRATE":         "false",
+				"DB_QUERY_TIMEOUT":        "3s",
+				"WEBHOOK_URL":             "https://api.example.com/webhook",
+				"WEBHOOK_SECRET":          "whsec_new",
+				"BATCH_SIZE":              "20",
+				"BATCH_TIMEOUT":           "2s",
+				"RELAY_ENABLED":           "false",
+				"LEASE_DURATION":          "2m",
+				"PUBLISH_CONCURRENCY":     "8",
+				"PRIORITY_AGING":          "30s",
+				"PUBLISH_TIMEOUT":         "5s",
+				"RETRY_ATTEMPTS":          "5",
+				"RETRY_DELAY":             "500ms",
+				"MAX_RETRY_DELAY":         "1m",
+				"CIRCUIT_MAX_REQUESTS":    "8",
+				"CIRCUIT_INTERVAL":        "1m",
+				"CIRCUIT_TIMEOUT":         "15s",
+				"FEATURE_FLAGS_API_URL":   "https://flags.example.com",
+				"FEATURE_FLAGS_ENV":       "prod",
+				"CORS_ALLOWED_ORIGINS":    "https://example.com, https://api.example.com",
+				"IDEMPOTENCY_RETENTION":   "1h",
+				"READ_TIMEOUT":            "15s",
+				"WRITE_TIMEOUT":           "1m",
+				"SHUTDOWN_DELAY":          "0s",
+				"SHUTDOWN_TIMEOUT":        "45s",
+				"SCHEMA_VALIDATION":       "strict",
+				"PUBLISHER":               "Kafka",
+				"KAFKA_BROKERS":           "kafka-1:9092, kafka-2:9092",
+				"KAFKA_TOPIC":             "events",
+				"EVENT_FORMAT":            "CloudEvents-Binary",
+				"NATS_URL":                "nats://nats:4222",
+				"NATS_SUBJECT":            "events",
+				"SQS_QUEUE_URL":           "http://elasticmq:9324/000000000000/events",
+				"SQS_REGION":              "eu-west-1",
+				"SQS_ENDPOINT":            "http://elasticmq:9324",
+				"RETENTION_PUBLISHED":     "72h",
+				"RETENTION_FAILED":        "720h",
+				"RETENTION_EXPIRED":       "24h",
+				"RETENTION_INTERVAL":      "1m",
+				"RETENTION_BATCH_SIZE":    "200",
+				"RETENTION_MODE":          "NDJSON",
+				"RETENTION_ARCHIVE_DIR":   "/var/lib/outbox/archive",
+				"RETENTION_CHANGES":       "1h",
+				"STREAM_ENABLED":          "false",
+				"STREAM_HEARTBEAT":        "30s",
+				"STREAM_BUFFER":           "64",
+				"METRICS_SAMPLE_INTERVAL": "1m",
 			},
 			expected: &Config{
 				Server: ServerConfig{
-					Port:         "9090",
-					ReadTimeout:  "30s",
-					WriteTimeout: "30s",
-					CORSOrigins:  []string{"https://example.com", "https://api.example.com"},
+					Port:                 "9090",
+					ReadTimeout:          "15s",
+					WriteTimeout:         "1m",
+					CORSOrigins:          []string{"https://example.com", "https://api.example.com"},
+					ShutdownDelay:        "0s",
+					ShutdownTimeout:      "45s",
+					IdempotencyRetention: "1h",
+					SchemaValidation:     SchemaValidationStrict,
 				},
 				Database: DatabaseConfig{
-					Host:     "prod-db",
-					Port:     5433,
-					User:     "admin",
-					Password: "secret",
-					DBName:   "production",
-					SSLMode:  "require",
+					Host:         "prod-db",
+					Port:         5433,
+					User:         "admin",
+					Password:     "secret",
+					DBName:       "production",
+					SSLMode:      "require",
+					QueryTimeout: "3s",
 				},
 				Publish: PublishConfig{
-					BatchSize:     20,
-					BatchTimeout:  "5s",
-					RetryAttempts: 3,
-					RetryDelay:    "1s",
-					MaxRetryDelay: "30s",
-					WebhookURL:    "https://api.example.com/webhook",
+					BatchSize:      20,
+					BatchTimeout:   "2s",
+					RetryAttempts:  5,
+					RetryDelay:     "500ms",
+					MaxRetryDelay:  "1m",
+					WebhookURL:     "https://api.example.com/webhook",
+					LeaseDuration:  "2m",
+					WebhookSecret:  "whsec_new",
+					Publisher:      PublisherKafka,
+					EventFormat:    EventFormatCloudEventsBinary,
+					Concurrency:    8,
+					PriorityAging:  "30s",
+					PublishTimeout: "5s",
 				},
 				Circuit: CircuitConfig{
-					MaxRequests: 5,
-					Interval:    "10s",
-					Timeout:     "5s",
+					FailureThreshold: 8,
+					Interval:         "1m",
+					Timeout:          "15s",
 				},
 				FeatureFlags: FeatureFlagsConfig{
 					BaseURL:     "https://flags.example.com",
 					Environment: "prod",
 				},
+				Kafka: KafkaConfig{
+					Brokers: []string{"kafka-1:9092", "kafka-2:9092"},
+					Topic:   "events",
+				},
+				NATS: NATSConfig{
+					URL:     "nats://nats:4222",
+					Subject: "events",
+				},
+				SQS: SQSConfig{
+					QueueURL: "http://elasticmq:9324/000000000000/events",
+					Region:   "eu-west-1",
+					Endpoint: "http://elasticmq:9324",
+				},
+				Retention: RetentionConfig{
+					Published:  "72h",
+					Failed:     "720h",
+					Expired:    "24h",
+					Interval:   "1m",
+					BatchSize:  200,
+					Mode:       RetentionModeNDJSON,
+					ArchiveDir: "/var/lib/outbox/archive",
+					Changes:    "1h",
+				},
+				Stream: StreamConfig{
+					Heartbeat: "30s",
+					Buffer:    64,
+				},
+				Metrics: MetricsConfig{
+					SampleInterval: "1m",
+				},
 			},
 		},
 	}
@@ -120,6 +245,54 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoad_InvalidPublisherKeepsDefault(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PUBLISHER", "carrier-pigeon")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, PublisherWebhook, cfg.Publish.Publisher)
+}
+
+func TestLoad_InvalidEventFormatKeepsDefault(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("EVENT_FORMAT", "xml")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, EventFormatJSON, cfg.Publish.EventFormat)
+}
+
+func TestLoad_CircuitFailureThreshold(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("CIRCUIT_FAILURE_THRESHOLD", "3")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), cfg.Circuit.FailureThreshold)
+
+	// The old name still works, but the new one wins
+	os.Setenv("CIRCUIT_MAX_REQUESTS", "7")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), cfg.Circuit.FailureThreshold)
+
+	os.Unsetenv("CIRCUIT_FAILURE_THRESHOLD")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), cfg.Circuit.FailureThreshold)
+}
+
+func TestLoad_LeaseMustOutlastDelivery(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("LEASE_DURATION", "30s")
+	os.Setenv("PUBLISH_TIMEOUT", "30s")
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LEASE_DURATION")
+}
+
 func TestDatabaseConfig_DSN(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -160,6 +333,158 @@ func TestDatabaseConfig_DSN(t *testing.T) {
 	}
 }
 
+func TestDatabaseConfig_QueryDeadline(t *testing.T) {
+	assert.Equal(t, 3*time.Second, (&DatabaseConfig{QueryTimeout: "3s"}).QueryDeadline())
+	assert.Equal(t, 10*time.Second, (&DatabaseConfig{QueryTimeout: "-1s"}).QueryDeadline())
+}
+
+func TestServerConfig_IdempotencyWindow(t *testing.T) {
+	assert.Equal(t, time.Hour, (&ServerConfig{IdempotencyRetention: "1h"}).IdempotencyWindow())
+	assert.Equal(t, 24*time.Hour, (&ServerConfig{}).IdempotencyWindow())
+}
+
+func TestServerConfig_Timeouts(t *testing.T) {
+	read, write := (&ServerConfig{ReadTimeout: "15s", WriteTimeout: "1m"}).Timeouts()
+	assert.Equal(t, 15*time.Second, read)
+	assert.Equal(t, time.Minute, write)
+
+	read, write = (&ServerConfig{}).Timeouts()
+	assert.Equal(t, 30*time.Second, read)
+	assert.Equal(t, 30*time.Second, write)
+}
+
+func TestConfig_ServerTimeouts(t *testing.T) {
+	cfg := &Config{Publish: PublishConfig{PublishTimeout: "45s"}}
+	read, write := cfg.ServerTimeouts()
+	assert.Equal(t, 30*time.Second, read)
+	assert.Equal(t, 75*time.Second, write)
+
+	cfg.Server.WriteTimeout = "20s"
+	_, write = cfg.ServerTimeouts()
+	assert.Equal(t, 20*time.Second, write)
+}
+
+func TestServerConfig_Shutdown(t *testing.T) {
+	server := &ServerConfig{ShutdownDelay: "0s", ShutdownTimeout: "45s"}
+	assert.Equal(t, time.Duration(0), server.DrainDelay())
+	assert.Equal(t, 45*time.Second, server.ShutdownDeadline())
+
+	assert.Equal(t, 5*time.Second, (&ServerConfig{}).DrainDelay())
+	assert.Equal(t, 30*time.Second, (&ServerConfig{}).ShutdownDeadline())
+}
+
+func TestPublishConfig_BatchInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  string
+		expected time.Duration
+	}{
+		{name: "configured interval", timeout: "250ms", expected: 250 * time.Millisecond},
+		{name: "empty falls back to default", timeout: "", expected: 5 * time.Second},
+		{name: "invalid falls back to default", timeout: "soon", expected: 5 * time.Second},
+		{name: "non-positive falls back to default", timeout: "0s", expected: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := PublishConfig{BatchTimeout: tt.timeout}
+			assert.Equal(t, tt.expected, cfg.BatchInterval())
+		})
+	}
+}
+
+func TestPublishConfig_PriorityAgingInterval(t *testing.T) {
+	assert.Equal(t, 30*time.Second, (&PublishConfig{PriorityAging: "30s"}).PriorityAgingInterval())
+	assert.Equal(t, time.Minute, (&PublishConfig{}).PriorityAgingInterval())
+}
+
+func TestPublishConfig_DeliveryDeadline(t *testing.T) {
+	assert.Equal(t, 5*time.Second, (&PublishConfig{PublishTimeout: "5s"}).DeliveryDeadline())
+	assert.Equal(t, 30*time.Second, (&PublishConfig{}).DeliveryDeadline())
+}
+
+func TestPublishConfig_Lease(t *testing.T) {
+	assert.Equal(t, 90*time.Second, (&PublishConfig{LeaseDuration: "90s"}).Lease())
+	assert.Equal(t, 60*time.Second, (&PublishConfig{}).Lease())
+}
+
+func TestPublishConfig_Parallelism(t *testing.T) {
+	assert.Equal(t, 8, (&PublishConfig{Concurrency: 8}).Parallelism())
+	assert.Equal(t, 1, (&PublishConfig{}).Parallelism())
+	assert.Equal(t, 1, (&PublishConfig{Concurrency: -2}).Parallelism())
+}
+
+func TestPublishConfig_RetryBackoff(t *testing.T) {
+	base, max := (&PublishConfig{RetryDelay: "2s", MaxRetryDelay: "1m"}).RetryBackoff()
+	assert.Equal(t, 2*time.Second, base)
+	assert.Equal(t, time.Minute, max)
+
+	base, max = (&PublishConfig{}).RetryBackoff()
+	assert.Equal(t, time.Second, base)
+	assert.Equal(t, 30*time.Second, max)
+}
+
+func TestPublishConfig_WebhookSecrets(t *testing.T) {
+	assert.Nil(t, (&PublishConfig{}).WebhookSecrets())
+	assert.Equal(t, []string{"new"}, (&PublishConfig{WebhookSecret: "new"}).WebhookSecrets())
+	assert.Equal(t, []string{"new", "old"}, (&PublishConfig{WebhookSecret: "new", WebhookPreviousSecret: "old"}).WebhookSecrets())
+}
+
+func TestCircuitConfig_Durations(t *testing.T) {
+	interval, timeout := (&CircuitConfig{Interval: "1m", Timeout: "15s"}).Durations()
+	assert.Equal(t, time.Minute, interval)
+	assert.Equal(t, 15*time.Second, timeout)
+
+	interval, timeout = (&CircuitConfig{}).Durations()
+	assert.Equal(t, 10*time.Second, interval)
+	assert.Equal(t, 5*time.Second, timeout)
+}
+
+func TestLoad_InvalidRetentionKeepsDefaults(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("RETENTION_PUBLISHED", "7 days")
+	os.Setenv("RETENTION_BATCH_SIZE", "0")
+	os.Setenv("RETENTION_MODE", "shred")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "168h", cfg.Retention.Published)
+	assert.Equal(t, 1000, cfg.Retention.BatchSize)
+	assert.Equal(t, RetentionModeDelete, cfg.Retention.Mode)
+}
+
+func TestRetentionConfig_MaxAges(t *testing.T) {
+	published, failed, expired := (&RetentionConfig{Published: "72h"}).MaxAges()
+	assert.Equal(t, 72*time.Hour, published)
+	assert.Zero(t, failed)
+	assert.Zero(t, expired)
+
+	published, failed, expired = (&RetentionConfig{Published: "0s", Failed: "720h", Expired: "24h"}).MaxAges()
+	assert.Zero(t, published)
+	assert.Equal(t, 720*time.Hour, failed)
+	assert.Equal(t, 24*time.Hour, expired)
+}
+
+func TestRetentionConfig_ChangesMaxAge(t *testing.T) {
+	assert.Equal(t, time.Hour, (&RetentionConfig{Changes: "1h"}).ChangesMaxAge())
+	assert.Zero(t, (&RetentionConfig{}).ChangesMaxAge())
+}
+
+func TestStreamConfig_HeartbeatInterval(t *testing.T) {
+	assert.Equal(t, 30*time.Second, (&StreamConfig{Heartbeat: "30s"}).HeartbeatInterval())
+	assert.Equal(t, 15*time.Second, (&StreamConfig{}).HeartbeatInterval())
+}
+
+func TestMetricsConfig_SamplingInterval(t *testing.T) {
+	assert.Equal(t, time.Minute, (&MetricsConfig{SampleInterval: "1m"}).SamplingInterval())
+	assert.Equal(t, 15*time.Second, (&MetricsConfig{SampleInterval: "soon"}).SamplingInterval())
+}
+
+func TestRetentionConfig_PruneInterval(t *testing.T) {
+	assert.Equal(t, time.Minute, (&RetentionConfig{Interval: "1m"}).PruneInterval())
+	assert.Equal(t, 10*time.Minute, (&RetentionConfig{}).PruneInterval())
+}
+
 func TestLoadFromFile_DISABLED(t *testing.T) {
 	t.Skip("Test disabled - loadFromEnvFile doesn't load JSON files")
 	// Create a temporary config file
@@ -237,9 +562,9 @@ func TestLoadFromFile_DISABLED(t *testing.T) {
 			WebhookURL:    "https://test.example.com/webhook",
 		},
 		Circuit: CircuitConfig{
-			MaxRequests: 10,
-			Interval:    "30s",
-			Timeout:     "10s",
+			FailureThreshold: 10,
+			Interval:         "30s",
+			Timeout:          "10s",
 		},
 	}
 
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/publisher"
+)
+
+// GetEventAttempts godoc
+// @Summary Get the delivery attempt history of an event
+// @Produce json
+// @Success 200 {object} models.DeliveryAttemptsResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/events/{id}/attempts [get]
+func (h *Handler) GetEventAttempts(c *gin.Context) {
+	id := c.Param("id")
+
+	attempts, err := h.store.ListAttempts(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if attempts == nil {
+		attempts = []models.DeliveryAttempt{}
+	}
+
+	c.JSON(http.StatusOK, models.DeliveryAttemptsResponse{
+		EventID:  id,
+		Attempts: attempts,
+		Total:    len(attempts),
+	})
+}
+
+// recordAttempt stores the outcome of publishing an event to destination.
+// A failure to store it is logged rather than failing the delivery.
+func (h *Handler) recordAttempt(ctx context.Context, event *models.Event, subscriptionID, destination string, started time.Time, resp publisher.Response, publishErr error) {
+	attempt := &models.DeliveryAttempt{
+		EventID:        event.ID,
+		SubscriptionID: subscriptionID,
+		Destination:    destination,
+		AttemptedAt:    started,
+		DurationMs:     time.Since(started).Milliseconds(),
+		StatusCode:     resp.StatusCode,
+		ResponseBody:   resp.Body,
+	}
+	if publishErr != nil {
+		attempt.Error = publishErr.Error()
+		attempt.ErrorClass = classifyError(publishErr, resp.StatusCode)
+	}
+
+	if err := h.store.RecordAttempt(ctx, attempt); err != nil {
+		fmt.Printf("Warning: failed to record delivery attempt of event %s to %s: %v\n", event.ID, destination, err)
+	}
+}
+
+// classifyError groups a publish error by cause: a timeout, a connection
+// that could not be made, an HTTP error status or any other publisher error
+func classifyError(err error, statusCode int) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.ErrorClassTimeout
+	case statusCode >= 500:
+		return models.ErrorClassServerError
+	case statusCode >= 400:
+		return models.ErrorClassClientError
+	case statusCode > 0:
+		return models.ErrorClassHTTPStatus
+	case errors.As(err, &netErr):
+		return models.ErrorClassConnection
+	default:
+		return models.ErrorClassPublish
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetEventAttempts(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+		expectedTotal  int
+	}{
+		{
+			name: "lists attempts",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListAttempts", "event-1").Return([]models.DeliveryAttempt{
+					{ID: 1, EventID: "event-1", Destination: "https://billing.example.com/hooks", StatusCode: 503, Error: "webhook returned status 503", ErrorClass: models.ErrorClassServerError},
+					{ID: 2, EventID: "event-1", Destination: "https://billing.example.com/hooks", StatusCode: 200},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  2,
+		},
+		{
+			name: "no attempts yet",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListAttempts", "event-1").Return([]models.DeliveryAttempt(nil), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListAttempts", "event-1").Return([]models.DeliveryAttempt(nil), assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			tt.mockSetup(mockStore)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			h := New(mockStore, &config.Config{}, &MockSimulationGates{})
+			router.GET("/api/v1/events/:id/attempts", h.GetEventAttempts)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/api/v1/events/event-1/attempts", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.DeliveryAttemptsResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "event-1", response.EventID)
+				assert.Equal(t, tt.expectedTotal, response.Total)
+				assert.NotNil(t, response.Attempts)
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PublishPending_RecordsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream unavailable"))
+	}))
+	defer server.Close()
+
+	subscriptions := []models.Subscription{
+		{ID: "billing", URL: server.URL, Active: true},
+	}
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return(subscriptions, nil)
+	mockStore.On("GetDeliveries", "event-1").Return([]models.Delivery{}, nil)
+	mockStore.On("RecordDelivery", "event-1", "billing", "webhook returned status 502").Return(nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 1, mock.AnythingOfType("time.Time")).Return(nil)
+	mockStore.On("RecordAttempt", mock.MatchedBy(func(attempt *models.DeliveryAttempt) bool {
+		return attempt.EventID == "event-1" &&
+			attempt.SubscriptionID == "billing" &&
+			attempt.Destination == server.URL &&
+			attempt.StatusCode == http.StatusBadGateway &&
+			attempt.ResponseBody == "upstream unavailable" &&
+			attempt.Error == "webhook returned status 502" &&
+			attempt.ErrorClass == models.ErrorClassServerError &&
+			!attempt.AttemptedAt.IsZero()
+	})).Return(nil).Once()
+
+	h := newDeliveryTestHandler(mockStore)
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Failed)
+
+	mockStore.AssertExpectations(t)
+}
+
+// timeoutError is a net.Error that reports a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		expected   string
+	}{
+		{name: "deadline exceeded", err: fmt.Errorf("failed to publish: %w", context.DeadlineExceeded), expected: models.ErrorClassTimeout},
+		{name: "network timeout", err: fmt.Errorf("failed to send webhook request: %w", timeoutError{}), expected: models.ErrorClassTimeout},
+		{name: "connection refused", err: fmt.Errorf("failed to send webhook request: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), expected: models.ErrorClassConnection},
+		{name: "server error", err: errors.New("webhook returned status 503"), statusCode: 503, expected: models.ErrorClassServerError},
+		{name: "client error", err: errors.New("webhook returned status 404"), statusCode: 404, expected: models.ErrorClassClientError},
+		{name: "redirect", err: errors.New("webhook returned status 302"), statusCode: 302, expected: models.ErrorClassHTTPStatus},
+		{name: "broker error", err: errors.New("failed to write kafka message: broker unavailable"), expected: models.ErrorClassPublish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifyError(tt.err, tt.statusCode))
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// bulkBatchSize is how many events a bulk job handles between progress updates
+const bulkBatchSize = 500
+
+// StartBulkJob godoc
+// @Summary Retry, delete, fail or requeue many events at once
+// @Description Events are selected by event_ids or by filter. The action runs in the background; poll the returned job for progress.
+// @Description With dry_run the matching events are only counted.
+// @Accept json
+// @Produce json
+// @Param request body models.BulkRequest true "Action and selection"
+// @Success 200 {object} models.BulkDryRunResponse
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/events/bulk [post]
+func (h *Handler) StartBulkJob(c *gin.Context) {
+	var req models.BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := req.Query()
+	matched, err := h.store.CountEvents(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		c.JSON(http.StatusOK, models.BulkDryRunResponse{Action: req.Action, Matched: matched, DryRun: true})
+		return
+	}
+
+	now := time.Now()
+	job := &models.BulkJob{
+		ID:        uuid.New().String(),
+		Action:    req.Action,
+		EventIDs:  req.EventIDs,
+		Filter:    req.Filter,
+		Status:    models.BulkJobRunning,
+		Matched:   matched,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.store.CreateBulkJob(c.Request.Context(), job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond with a copy; the job itself is updated as it runs
+	accepted := *job
+
+	// The job outlives the request, so it runs until done or shutdown
+	h.jobs.Add(1)
+	go func() {
+		defer h.jobs.Done()
+		h.runBulkJob(h.jobsCtx, job, query)
+	}()
+
+	c.Header("Location", "/admin/events/bulk/"+job.ID)
+	c.JSON(http.StatusAccepted, gin.H{"job": accepted})
+}
+
+// GetBulkJob godoc
+// @Summary Get the status and progress of a bulk job
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/events/bulk/{id} [get]
+func (h *Handler) GetBulkJob(c *gin.Context) {
+	id := c.Param("id")
+
+	job, err := h.store.GetBulkJob(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "bulk job not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "bulk job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// WaitForJobs blocks until the bulk jobs running in the background have
+// finished. Jobs still running when ctx is done are interrupted, and
+// WaitForJobs returns ctx's error once they have recorded that they failed.
+func (h *Handler) WaitForJobs(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		h.cancelJobs()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// runBulkJob applies a job's action to the selected events and records how
+// it ended. A job interrupted by ctx is recorded as failed.
+func (h *Handler) runBulkJob(ctx context.Context, job *models.BulkJob, query *models.EventQuery) {
+	err := h.processBulkJob(ctx, job, query)
+
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+	job.Status = models.BulkJobCompleted
+	if err != nil {
+		job.Status = models.BulkJobFailed
+		job.Error = err.Error()
+		if ctx.Err() != nil {
+			job.Error = "interrupted by shutdown"
+		}
+	}
+
+	// The job's end must be recorded even if ctx was cancelled
+	if err := h.store.UpdateBulkJob(context.WithoutCancel(ctx), job); err != nil {
+		fmt.Printf("Warning: failed to record completion of bulk job %s: %v\n", job.ID, err)
+	}
+}
+
+// processBulkJob walks the selection in batches, saving progress after each
+func (h *Handler) processBulkJob(ctx context.Context, job *models.BulkJob, query *models.EventQuery) error {
+	afterID := ""
+	for {
+		ids, err := h.store.ListEventIDs(ctx, query, afterID, bulkBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if job.Action == models.BulkActionRetry {
+			if err := h.retryEvents(ctx, job, ids); err != nil {
+				return err
+			}
+		} else {
+			changed, err := h.store.ApplyBulkAction(ctx, job.ID, job.Action, ids)
+			if err != nil {
+				return err
+			}
+			job.Succeeded += changed
+		}
+		job.Processed += len(ids)
+
+		if err := h.store.UpdateBulkJob(ctx, job); err != nil {
+			fmt.Printf("Warning: failed to record progress of bulk job %s: %v\n", job.ID, err)
+		}
+
+		if len(ids) < bulkBatchSize {
+			return nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// retryEvents publishes each failed event in ids again, as /events/:id/retry
+// does, counting publish failures against the job. The events are leased
+// first, so one being retried elsewhere or held back behind an earlier event
+// with the same partition key is skipped.
+func (h *Handler) retryEvents(ctx context.Context, job *models.BulkJob, ids []string) error {
+	subscriptions, err := h.activeSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	claimed, err := h.store.ClaimEvents(ctx, h.workerID, ids, []models.EventStatus{models.StatusFailed}, h.cfg.Publish.Lease())
+	if err != nil {
+		return err
+	}
+
+	for i := range claimed {
+		if ctx.Err() != nil {
+			// Hand back the events the job will not get to
+			remaining := make([]int, 0, len(claimed)-i)
+			for j := i; j < len(claimed); j++ {
+				remaining = append(remaining, j)
+			}
+			h.releaseEvents(ctx, claimed, remaining)
+			return ctx.Err()
+		}
+
+		if err := h.retryFailedEvent(ctx, &claimed[i], subscriptions); err != nil {
+			if ctx.Err() == nil {
+				job.Failed++
+			}
+			continue
+		}
+		job.Succeeded++
+	}
+
+	return ctx.Err()
+}
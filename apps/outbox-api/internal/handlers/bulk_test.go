@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newBulkTestRouter(h *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/events/bulk", h.StartBulkJob)
+	router.GET("/admin/events/bulk/:id", h.GetBulkJob)
+	return router
+}
+
+func postBulk(t *testing.T, router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/admin/events/bulk", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_StartBulkJob_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{name: "missing action", body: `{"event_ids": ["event-1"]}`},
+		{name: "unknown action", body: `{"action": "archive", "event_ids": ["event-1"]}`, expectedError: "unknown action: archive"},
+		{name: "no selection", body: `{"action": "delete"}`, expectedError: "event_ids or filter is required"},
+		{name: "both selections", body: `{"action": "delete", "event_ids": ["event-1"], "filter": {"type": "order.created"}}`, expectedError: "event_ids and filter cannot be combined"},
+		{name: "invalid status", body: `{"action": "delete", "filter": {"status": "done"}}`, expectedError: "invalid status: done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			router := newBulkTestRouter(newDeliveryTestHandler(mockStore))
+
+			w := postBulk(t, router, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			if tt.expectedError != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedError, response["error"])
+			}
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_StartBulkJob_DryRun(t *testing.T) {
+	status := models.StatusFailed
+	mockStore := new(MockOutboxStore)
+	mockStore.On("CountEvents", &models.EventQuery{
+		Status:   &status,
+		Statuses: []models.EventStatus{models.StatusPending, models.StatusRetrying},
+		Type:     "order.*",
+	}).Return(0, nil)
+
+	router := newBulkTestRouter(newDeliveryTestHandler(mockStore))
+	w := postBulk(t, router, `{"action": "mark-failed", "filter": {"status": "failed", "type": "order.*"}, "dry_run": true}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.BulkDryRunResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.BulkDryRunResponse{Action: models.BulkActionMarkFailed, Matched: 0, DryRun: true}, response)
+
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNotCalled(t, "CreateBulkJob", mock.Anything)
+}
+
+func TestHandler_StartBulkJob_AppliesActionInBatches(t *testing.T) {
+	ids := make([]string, bulkBatchSize)
+	for i := range ids {
+		ids[i] = "event-" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	query := &models.EventQuery{
+		Source:   "order-service",
+		Statuses: models.BulkActionRequeue.EligibleStatuses(),
+	}
+
+	var job *models.BulkJob
+	mockStore := new(MockOutboxStore)
+	mockStore.On("CountEvents", query).Return(bulkBatchSize+1, nil)
+	mockStore.On("CreateBulkJob", mock.AnythingOfType("*models.BulkJob")).
+		Run(func(args mock.Arguments) { job = args.Get(0).(*models.BulkJob) }).
+		Return(nil)
+	mockStore.On("ListEventIDs", query, "", bulkBatchSize).Return(ids, nil)
+	mockStore.On("ListEventIDs", query, ids[len(ids)-1], bulkBatchSize).Return([]string{"event-last"}, nil)
+	mockStore.On("ApplyBulkAction", mock.AnythingOfType("string"), models.BulkActionRequeue, ids).Return(bulkBatchSize-2, nil)
+	mockStore.On("ApplyBulkAction", mock.AnythingOfType("string"), models.BulkActionRequeue, []string{"event-last"}).Return(1, nil)
+	mockStore.On("UpdateBulkJob", mock.AnythingOfType("*models.BulkJob")).Return(nil).Times(3)
+
+	h := newDeliveryTestHandler(mockStore)
+	w := postBulk(t, newBulkTestRouter(h), `{"action": "requeue-as-pending", "filter": {"source": "order-service"}}`)
+	h.jobs.Wait()
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response struct {
+		Job models.BulkJob `json:"job"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.BulkJobRunning, response.Job.Status)
+	assert.Equal(t, bulkBatchSize+1, response.Job.Matched)
+	assert.Equal(t, "/admin/events/bulk/"+response.Job.ID, w.Header().Get("Location"))
+
+	require.NotNil(t, job)
+	assert.Equal(t, models.BulkJobCompleted, job.Status)
+	assert.Equal(t, bulkBatchSize+1, job.Processed)
+	assert.Equal(t, bulkBatchSize-1, job.Succeeded)
+	assert.Equal(t, 2, job.Skipped())
+	assert.NotNil(t, job.CompletedAt)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_StartBulkJob_Retry(t *testing.T) {
+	ids := []string{"event-1", "event-2", "event-3"}
+	query := &models.EventQuery{IDs: ids, Statuses: models.BulkActionRetry.EligibleStatuses()}
+
+	var job *models.BulkJob
+	mockStore := new(MockOutboxStore)
+	mockStore.On("CountEvents", query).Return(3, nil)
+	mockStore.On("CreateBulkJob", mock.AnythingOfType("*models.BulkJob")).
+		Run(func(args mock.Arguments) { job = args.Get(0).(*models.BulkJob) }).
+		Return(nil)
+	mockStore.On("ListEventIDs", query, "", bulkBatchSize).Return(ids, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	// The others were retried by someone else, deleted or are held back
+	// behind an earlier event with the same partition key
+	mockStore.On("ClaimEvents", mock.AnythingOfType("string"), ids, []models.EventStatus{models.StatusFailed}, 60*time.Second).
+		Return([]models.Event{{ID: "event-1", Type: "order.created", Status: models.StatusFailed, RetryCount: 2}}, nil)
+	mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "event-1", models.StatusFailed, "broker unavailable", 3).Return(nil)
+	mockStore.On("UpdateBulkJob", mock.AnythingOfType("*models.BulkJob")).Return(nil).Times(2)
+
+	h := newDeliveryTestHandler(mockStore)
+	WithPublisher(&fakePublisher{err: errors.New("broker unavailable")})(h)
+	w := postBulk(t, newBulkTestRouter(h), `{"action": "retry", "event_ids": ["event-1", "event-2", "event-3"]}`)
+	h.jobs.Wait()
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	require.NotNil(t, job)
+	assert.Equal(t, models.BulkJobCompleted, job.Status)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 0, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, 2, job.Skipped())
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_StartBulkJob_RecordsFailure(t *testing.T) {
+	query := &models.EventQuery{IDs: []string{"event-1"}, Statuses: models.BulkActionDelete.EligibleStatuses()}
+
+	var job *models.BulkJob
+	mockStore := new(MockOutboxStore)
+	mockStore.On("CountEvents", query).Return(1, nil)
+	mockStore.On("CreateBulkJob", mock.AnythingOfType("*models.BulkJob")).
+		Run(func(args mock.Arguments) { job = args.Get(0).(*models.BulkJob) }).
+		Return(nil)
+	mockStore.On("ListEventIDs", query, "", bulkBatchSize).Return(nil, errors.New("failed to list event IDs: connection reset"))
+	mockStore.On("UpdateBulkJob", mock.AnythingOfType("*models.BulkJob")).Return(nil).Once()
+
+	h := newDeliveryTestHandler(mockStore)
+	w := postBulk(t, newBulkTestRouter(h), `{"action": "delete", "event_ids": ["event-1"]}`)
+	h.jobs.Wait()
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	require.NotNil(t, job)
+	assert.Equal(t, models.BulkJobFailed, job.Status)
+	assert.Equal(t, "failed to list event IDs: connection reset", job.Error)
+	assert.Equal(t, 0, job.Processed)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_GetBulkJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+	}{
+		{
+			name: "found",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetBulkJob", "job-1").Return(&models.BulkJob{ID: "job-1", Action: models.BulkActionDelete, Status: models.BulkJobRunning, Matched: 10, Processed: 5}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetBulkJob", "job-1").Return(nil, errors.New("bulk job not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetBulkJob", "job-1").Return(nil, errors.New("failed to get bulk job: connection reset"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			tt.mockSetup(mockStore)
+			router := newBulkTestRouter(newDeliveryTestHandler(mockStore))
+
+			req, err := http.NewRequest("GET", "/admin/events/bulk/job-1", nil)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Job models.BulkJob `json:"job"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 5, response.Job.Processed)
+			}
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_WaitForJobs(t *testing.T) {
+	h := newDeliveryTestHandler(new(MockOutboxStore))
+	assert.NoError(t, h.WaitForJobs(context.Background()))
+}
+
+func TestHandler_WaitForJobs_InterruptsJobs(t *testing.T) {
+	ids := []string{"event-1", "event-2"}
+	query := &models.EventQuery{IDs: ids, Statuses: models.BulkActionRetry.EligibleStatuses()}
+
+	var job *models.BulkJob
+	mockStore := new(MockOutboxStore)
+	mockStore.On("CountEvents", query).Return(2, nil)
+	mockStore.On("CreateBulkJob", mock.AnythingOfType("*models.BulkJob")).
+		Run(func(args mock.Arguments) { job = args.Get(0).(*models.BulkJob) }).
+		Return(nil)
+	mockStore.On("ListEventIDs", query, "", bulkBatchSize).Return(ids, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("ClaimEvents", mock.AnythingOfType("string"), ids, []models.EventStatus{models.StatusFailed}, 60*time.Second).
+		Return([]models.Event{
+			{ID: "event-1", Type: "order.created", Status: models.StatusFailed},
+			{ID: "event-2", Type: "order.created", Status: models.StatusFailed},
+		}, nil)
+	// Both events are handed back: the one cut off mid-publish and the one
+	// the job never got to
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"event-1"}).Return(nil).Once()
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"event-2"}).Return(nil).Once()
+	mockStore.On("UpdateBulkJob", mock.AnythingOfType("*models.BulkJob")).Return(nil).Once()
+
+	publishing := make(chan struct{})
+	h := newDeliveryTestHandler(mockStore)
+	WithPublisher(&blockingPublisher{onPublish: func() { close(publishing) }})(h)
+	w := postBulk(t, newBulkTestRouter(h), `{"action": "retry", "event_ids": ["event-1", "event-2"]}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	<-publishing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.WaitForJobs(ctx), context.DeadlineExceeded)
+
+	require.NotNil(t, job)
+	assert.Equal(t, models.BulkJobFailed, job.Status)
+	assert.Equal(t, "interrupted by shutdown", job.Error)
+	assert.NotNil(t, job.CompletedAt)
+	assert.Equal(t, 0, job.Failed)
+
+	mockStore.AssertExpectations(t)
+}
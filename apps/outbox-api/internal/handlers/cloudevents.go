@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/cloudevents"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// isCloudEvent reports whether a request carries a CloudEvent in structured
+// or binary content mode rather than a plain CreateEventRequest
+func isCloudEvent(r *http.Request) bool {
+	return cloudevents.IsStructured(r.Header.Get("Content-Type")) || cloudevents.IsBinary(r.Header)
+}
+
+// bindCloudEvent reads a CloudEvent from the request and converts it into a
+// CreateEventRequest. On failure it returns the HTTP status to respond with.
+func bindCloudEvent(c *gin.Context) (models.CreateEventRequest, int, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return models.CreateEventRequest{}, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var event *cloudevents.Event
+	if cloudevents.IsStructured(c.GetHeader("Content-Type")) {
+		event, err = cloudevents.ParseStructured(body)
+	} else {
+		event, err = cloudevents.ParseBinary(c.Request.Header, body)
+	}
+	if err != nil {
+		if errors.Is(err, cloudevents.ErrUnsupportedData) {
+			return models.CreateEventRequest{}, http.StatusUnsupportedMediaType, err
+		}
+		return models.CreateEventRequest{}, http.StatusBadRequest, err
+	}
+
+	req, err := event.CreateEventRequest()
+	if err != nil {
+		return models.CreateEventRequest{}, http.StatusBadRequest, err
+	}
+
+	// An explicit Idempotency-Key takes precedence over the CloudEvent's
+	// source and id
+	if c.GetHeader(idempotencyKeyHeader) != "" {
+		req.IdempotencyKey = ""
+	}
+
+	return req, http.StatusOK, nil
+}
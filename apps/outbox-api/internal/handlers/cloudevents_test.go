@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CreateEvent_CloudEvents(t *testing.T) {
+	event := &models.Event{
+		ID:     "event-1",
+		Type:   "order.created",
+		Source: "/orders",
+		Data:   json.RawMessage(`{"order_id": "123"}`),
+		Status: models.StatusPending,
+	}
+	withRequest := func(key string) interface{} {
+		return mock.MatchedBy(func(req *models.CreateEventRequest) bool {
+			return req.Type == "order.created" &&
+				req.Source == "/orders" &&
+				string(req.Metadata) == `{"subject":"order-123"}` &&
+				req.IdempotencyKey == key
+		})
+	}
+
+	tests := []struct {
+		name           string
+		header         map[string]string
+		body           string
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:   "structured mode",
+			header: map[string]string{"Content-Type": "application/cloudevents+json"},
+			body: `{"specversion": "1.0", "id": "A234-1234", "source": "/orders", "type": "order.created",
+				"subject": "order-123", "data": {"order_id": "123"}}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withRequest("/orders A234-1234"), 24*time.Hour).Return(event, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "binary mode redelivery",
+			header: map[string]string{
+				"Content-Type":   "application/json",
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "A234-1234",
+				"Ce-Source":      "/orders",
+				"Ce-Type":        "order.created",
+				"Ce-Subject":     "order-123",
+			},
+			body: `{"order_id": "123"}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withRequest("/orders A234-1234"), 24*time.Hour).Return(event, false, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "idempotency key header overrides source and id",
+			header: map[string]string{
+				"Content-Type":    "application/cloudevents+json",
+				"Idempotency-Key": "order-123",
+			},
+			body: `{"specversion": "1.0", "id": "A234-1234", "source": "/orders", "type": "order.created",
+				"subject": "order-123", "data": {"order_id": "123"}}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withRequest("order-123"), 24*time.Hour).Return(event, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing required attribute",
+			header:         map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:           `{"specversion": "1.0", "id": "A234-1234", "type": "order.created"}`,
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "cloudevent source is required",
+		},
+		{
+			name: "non-JSON data",
+			header: map[string]string{
+				"Content-Type":   "text/plain",
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "A234-1234",
+				"Ce-Source":      "/orders",
+				"Ce-Type":        "order.created",
+			},
+			body:           "hello",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedError:  "cloudevent data must be JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			expectNoSchema(mockStore)
+			tt.mockSetup(mockStore)
+
+			router := setupTestRouter(mockStore)
+
+			req, err := http.NewRequest("POST", "/api/v1/events", bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			} else {
+				assert.Equal(t, "event-1", response["event"].(map[string]interface{})["id"])
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// ListDeadLetters godoc
+// @Summary List events that exhausted their retry budget
+// @Produce json
+// @Success 200 {object} models.DeadLettersResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/dead-letters [get]
+func (h *Handler) ListDeadLetters(c *gin.Context) {
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	deadLetters, total, err := h.store.ListDeadLetters(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeadLettersResponse{
+		DeadLetters: deadLetters,
+		Total:       total,
+		Page:        page,
+		Limit:       limit,
+	})
+}
+
+// GetDeadLetter godoc
+// @Summary Get a dead letter by event ID
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/dead-letters/{id} [get]
+func (h *Handler) GetDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	deadLetter, err := h.store.GetDeadLetter(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "dead letter not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dead_letter": deadLetter})
+}
+
+// RequeueDeadLetter godoc
+// @Summary Move a dead letter back into the outbox as a pending event
+// @Description A dead letter whose expires_at has passed cannot be requeued.
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/dead-letters/{id}/requeue [post]
+func (h *Handler) RequeueDeadLetter(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	requeued, err := h.store.RequeueDeadLetters(ctx, []string{id})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if requeued == 0 {
+		// Either missing or left in place because it has expired
+		deadLetter, err := h.store.GetDeadLetter(ctx, id)
+		if err != nil {
+			if err.Error() == "dead letter not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if deadLetter.Expired(time.Now()) {
+			c.JSON(http.StatusConflict, gin.H{"error": "dead letter has expired"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "dead letter requeued", "requeued": requeued})
+}
+
+// RequeueDeadLetters godoc
+// @Summary Move several dead letters, or all of them, back into the outbox
+// @Description Expired dead letters are skipped.
+// @Accept json
+// @Produce json
+// @Param request body models.RequeueDeadLettersRequest true "Dead letters to requeue"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/dead-letters/requeue [post]
+func (h *Handler) RequeueDeadLetters(c *gin.Context) {
+	var req models.RequeueDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.EventIDs) == 0 && !req.All {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "event_ids or all is required"})
+		return
+	}
+
+	var requeued int
+	var err error
+	if req.All {
+		requeued, err = h.store.RequeueAllDeadLetters(c.Request.Context())
+	} else {
+		requeued, err = h.store.RequeueDeadLetters(c.Request.Context(), req.EventIDs)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requeued": requeued})
+}
+
+// PurgeDeadLetter godoc
+// @Summary Permanently delete a dead letter
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/dead-letters/{id} [delete]
+func (h *Handler) PurgeDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.store.PurgeDeadLetter(c.Request.Context(), id); err != nil {
+		if err.Error() == "dead letter not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "dead letter purged"})
+}
+
+// PurgeDeadLetters godoc
+// @Summary Permanently delete dead letters, optionally only those older than a duration
+// @Produce json
+// @Param older_than query string false "Only purge dead letters older than this duration (e.g. 72h)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/dead-letters [delete]
+func (h *Handler) PurgeDeadLetters(c *gin.Context) {
+	cutoff := time.Now()
+
+	if o := c.Query("older_than"); o != "" {
+		olderThan, err := time.ParseDuration(o)
+		if err != nil || olderThan < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "older_than must be a non-negative duration such as 72h"})
+			return
+		}
+		cutoff = cutoff.Add(-olderThan)
+	}
+
+	purged, err := h.store.PurgeDeadLetters(c.Request.Context(), cutoff)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
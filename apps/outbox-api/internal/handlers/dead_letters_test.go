@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errDeadLetterNotFound = errors.New("dead letter not found")
+
+func setupDeadLetterRouter(store *MockOutboxStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	h := New(store, &config.Config{}, &MockSimulationGates{})
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/dead-letters", h.ListDeadLetters)
+		admin.DELETE("/dead-letters", h.PurgeDeadLetters)
+		admin.POST("/dead-letters/requeue", h.RequeueDeadLetters)
+		admin.GET("/dead-letters/:id", h.GetDeadLetter)
+		admin.DELETE("/dead-letters/:id", h.PurgeDeadLetter)
+		admin.POST("/dead-letters/:id/requeue", h.RequeueDeadLetter)
+	}
+
+	return router
+}
+
+func TestHandler_DeadLetters(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           interface{}
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+		expectedError  string
+	}{
+		{
+			name:   "list dead letters",
+			method: "GET",
+			url:    "/admin/dead-letters?page=2&limit=5",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				deadLetters := []models.DeadLetter{
+					{ID: "dead-1", Type: "test.event", Source: "test-service", RetryCount: 4},
+				}
+				mockStore.On("ListDeadLetters", 2, 5).Return(deadLetters, 6, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"total": float64(6), "page": float64(2), "limit": float64(5)},
+		},
+		{
+			name:   "list dead letters storage error",
+			method: "GET",
+			url:    "/admin/dead-letters",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListDeadLetters", 1, 20).Return(([]models.DeadLetter)(nil), 0, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "assert.AnError general error for testing",
+		},
+		{
+			name:   "get dead letter",
+			method: "GET",
+			url:    "/admin/dead-letters/dead-1",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetDeadLetter", "dead-1").Return(&models.DeadLetter{ID: "dead-1"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get missing dead letter",
+			method: "GET",
+			url:    "/admin/dead-letters/missing",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetDeadLetter", "missing").Return(nil, errDeadLetterNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "dead letter not found",
+		},
+		{
+			name:   "requeue single dead letter",
+			method: "POST",
+			url:    "/admin/dead-letters/dead-1/requeue",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RequeueDeadLetters", []string{"dead-1"}).Return(1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"requeued": float64(1)},
+		},
+		{
+			name:   "requeue missing dead letter",
+			method: "POST",
+			url:    "/admin/dead-letters/missing/requeue",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RequeueDeadLetters", []string{"missing"}).Return(0, nil)
+				mockStore.On("GetDeadLetter", "missing").Return(nil, errors.New("dead letter not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "dead letter not found",
+		},
+		{
+			name:   "requeue expired dead letter",
+			method: "POST",
+			url:    "/admin/dead-letters/dead-1/requeue",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				expiresAt := time.Now().Add(-time.Minute)
+				mockStore.On("RequeueDeadLetters", []string{"dead-1"}).Return(0, nil)
+				mockStore.On("GetDeadLetter", "dead-1").Return(&models.DeadLetter{ID: "dead-1", ExpiresAt: &expiresAt}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "dead letter has expired",
+		},
+		{
+			name:   "bulk requeue by IDs",
+			method: "POST",
+			url:    "/admin/dead-letters/requeue",
+			body:   models.RequeueDeadLettersRequest{EventIDs: []string{"dead-1", "dead-2"}},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RequeueDeadLetters", []string{"dead-1", "dead-2"}).Return(2, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"requeued": float64(2)},
+		},
+		{
+			name:   "bulk requeue all",
+			method: "POST",
+			url:    "/admin/dead-letters/requeue",
+			body:   models.RequeueDeadLettersRequest{All: true},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RequeueAllDeadLetters").Return(7, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"requeued": float64(7)},
+		},
+		{
+			name:           "bulk requeue without selection",
+			method:         "POST",
+			url:            "/admin/dead-letters/requeue",
+			body:           models.RequeueDeadLettersRequest{},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "event_ids or all is required",
+		},
+		{
+			name:   "purge single dead letter",
+			method: "DELETE",
+			url:    "/admin/dead-letters/dead-1",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("PurgeDeadLetter", "dead-1").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "purge missing dead letter",
+			method: "DELETE",
+			url:    "/admin/dead-letters/missing",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("PurgeDeadLetter", "missing").Return(errDeadLetterNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "dead letter not found",
+		},
+		{
+			name:   "purge dead letters older than a duration",
+			method: "DELETE",
+			url:    "/admin/dead-letters?older_than=72h",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("PurgeDeadLetters", mock.MatchedBy(func(cutoff time.Time) bool {
+					age := time.Since(cutoff)
+					return age >= 72*time.Hour && age < 73*time.Hour
+				})).Return(3, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"purged": float64(3)},
+		},
+		{
+			name:           "purge with invalid duration",
+			method:         "DELETE",
+			url:            "/admin/dead-letters?older_than=yesterday",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "older_than must be a non-negative duration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			tt.mockSetup(mockStore)
+
+			router := setupDeadLetterRouter(mockStore)
+
+			var body *bytes.Buffer
+			if tt.body != nil {
+				jsonBody, err := json.Marshal(tt.body)
+				require.NoError(t, err)
+				body = bytes.NewBuffer(jsonBody)
+			} else {
+				body = bytes.NewBuffer(nil)
+			}
+
+			req, err := http.NewRequest(tt.method, tt.url, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			}
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, response[key], key)
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/circuit"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/publisher"
+)
+
+// circuitOpenError reports that a delivery was not attempted because the
+// destination's circuit is open. It unwraps to circuit.ErrOpen.
+type circuitOpenError struct {
+	destination string
+	retryAfter  time.Time
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %v", e.destination, circuit.ErrOpen)
+}
+
+func (e *circuitOpenError) Unwrap() error {
+	return circuit.ErrOpen
+}
+
+// activeSubscriptions returns the subscriptions events are currently routed to
+func (h *Handler) activeSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	subscriptions, err := h.store.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+
+	var active []models.Subscription
+	for _, subscription := range subscriptions {
+		if subscription.Active {
+			active = append(active, subscription)
+		}
+	}
+
+	return active, nil
+}
+
+// forgetCircuit drops the breaker of a URL a subscription no longer delivers
+// to, unless another subscription or the default publisher still uses it
+func (h *Handler) forgetCircuit(ctx context.Context, url string) {
+	if url == h.defaultPublisher().Destination() {
+		return
+	}
+
+	subscriptions, err := h.store.ListSubscriptions(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to load subscriptions, keeping circuit breaker for %s: %v\n", url, err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		if subscription.URL == url {
+			return
+		}
+	}
+
+	h.circuits.Remove(url)
+}
+
+// deliverToSubscriptions delivers an event concurrently to every matching
+// subscription that has not already received it, recording the outcome per
+// subscription. A slow or failing subscriber therefore neither delays nor
+// causes redelivery to the others. An event matching no subscription is
+// treated as published.
+func (h *Handler) deliverToSubscriptions(ctx context.Context, event *models.Event, subscriptions []models.Subscription) error {
+	deliveries, err := h.store.GetDeliveries(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	delivered := make(map[string]bool, len(deliveries))
+	for _, delivery := range deliveries {
+		if delivery.Status == models.DeliveryDelivered {
+			delivered[delivery.SubscriptionID] = true
+		}
+	}
+
+	var targets []models.Subscription
+	for _, subscription := range subscriptions {
+		if subscription.Matches(event) && !delivered[subscription.ID] {
+			targets = append(targets, subscription)
+		}
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, subscription := range targets {
+		wg.Go(func() {
+			errs[i] = h.deliver(ctx, publisher.NewWebhook(subscription.URL, subscription.SigningSecrets(time.Now()), h.cfg.Publish.EventFormat), event, subscription.ID)
+		})
+	}
+	wg.Wait()
+
+	var failures []string
+	var openErr *circuitOpenError
+	for i, subscription := range targets {
+		deliveryErr := errs[i]
+
+		if open, ok := deliveryErr.(*circuitOpenError); ok {
+			// Nothing was sent, so there is no attempt to record
+			if openErr == nil || open.retryAfter.Before(openErr.retryAfter) {
+				openErr = open
+			}
+			continue
+		}
+
+		lastError := ""
+		if deliveryErr != nil {
+			lastError = deliveryErr.Error()
+			failures = append(failures, fmt.Sprintf("subscription %s: %v", subscription.ID, deliveryErr))
+		}
+
+		if err := h.store.RecordDelivery(context.WithoutCancel(ctx), event.ID, subscription.ID, lastError); err != nil {
+			fmt.Printf("Warning: failed to record delivery of event %s to subscription %s: %v\n", event.ID, subscription.ID, err)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("delivery failed for %d of %d subscriptions: %s", len(failures), len(targets), strings.Join(failures, "; "))
+	}
+
+	if openErr != nil {
+		return openErr
+	}
+
+	return nil
+}
@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/storage"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer counts the event IDs posted to it and answers with status
+type recordingServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	received []string
+}
+
+func newRecordingServer(t *testing.T, status int) *recordingServer {
+	rs := &recordingServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		rs.mutex.Lock()
+		rs.received = append(rs.received, payload.ID)
+		rs.mutex.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func newDeliveryTestHandler(mockStore *MockOutboxStore) *Handler {
+	expectAttempts(mockStore)
+
+	mockGates := &MockSimulationGates{}
+	mockGates.On("ShouldDisablePublishing").Return(false)
+	mockGates.On("ShouldSimulateWebhookFailures").Return(false)
+	mockGates.On("ShouldSimulateNetworkDelays").Return(false)
+	mockGates.On("ShouldUsePartialFailureMode").Return(false)
+	mockGates.On("CheckCircuitBreaker").Return(false)
+	mockGates.On("RecordCircuitBreakerFailure").Return()
+	mockGates.On("RecordCircuitBreakerSuccess").Return()
+
+	cfg := &config.Config{
+		Publish: config.PublishConfig{
+			BatchSize:     5,
+			RetryAttempts: 3,
+			WebhookURL:    "http://default.invalid/webhook",
+		},
+	}
+
+	return New(mockStore, cfg, mockGates)
+}
+
+func TestHandler_PublishPending_FansOutToSubscriptions(t *testing.T) {
+	billing := newRecordingServer(t, http.StatusOK)
+	audit := newRecordingServer(t, http.StatusOK)
+	users := newRecordingServer(t, http.StatusOK)
+
+	subscriptions := []models.Subscription{
+		{ID: "billing", URL: billing.URL, EventTypes: []string{"order.*"}, Active: true},
+		{ID: "audit", URL: audit.URL, Active: true},
+		{ID: "users", URL: users.URL, EventTypes: []string{"user.*"}, Active: true},
+		{ID: "paused", URL: users.URL, Active: false},
+	}
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return(subscriptions, nil)
+	mockStore.On("GetDeliveries", "event-1").Return([]models.Delivery{}, nil)
+	mockStore.On("RecordDelivery", "event-1", "billing", "").Return(nil)
+	mockStore.On("RecordDelivery", "event-1", "audit", "").Return(nil)
+	mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "event-1", models.StatusPublished, "", 0).Return(nil)
+
+	response, err := newDeliveryTestHandler(mockStore).PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Published)
+
+	assert.Equal(t, []string{"event-1"}, billing.received)
+	assert.Equal(t, []string{"event-1"}, audit.received)
+	assert.Empty(t, users.received)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_RetriesOnlyFailedSubscriptions(t *testing.T) {
+	billing := newRecordingServer(t, http.StatusOK)
+	audit := newRecordingServer(t, http.StatusServiceUnavailable)
+	analytics := newRecordingServer(t, http.StatusOK)
+
+	subscriptions := []models.Subscription{
+		{ID: "billing", URL: billing.URL, Active: true},
+		{ID: "audit", URL: audit.URL, Active: true},
+		{ID: "analytics", URL: analytics.URL, Active: true},
+	}
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusRetrying, RetryCount: 1},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return(subscriptions, nil)
+	// billing already received the event on an earlier attempt
+	mockStore.On("GetDeliveries", "event-1").Return([]models.Delivery{
+		{EventID: "event-1", SubscriptionID: "billing", Status: models.DeliveryDelivered},
+		{EventID: "event-1", SubscriptionID: "audit", Status: models.DeliveryFailed},
+	}, nil)
+	mockStore.On("RecordDelivery", "event-1", "audit", "webhook returned status 503").Return(nil)
+	mockStore.On("RecordDelivery", "event-1", "analytics", "").Return(nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", "delivery failed for 1 of 2 subscriptions: subscription audit: webhook returned status 503", 2, mock.AnythingOfType("time.Time")).Return(nil)
+
+	response, err := newDeliveryTestHandler(mockStore).PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Failed)
+
+	assert.Empty(t, billing.received)
+	assert.Equal(t, []string{"event-1"}, audit.received)
+	assert.Equal(t, []string{"event-1"}, analytics.received)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_EventWithoutMatchingSubscription(t *testing.T) {
+	subscriptions := []models.Subscription{
+		{ID: "users", URL: "http://users.invalid/hooks", EventTypes: []string{"user.*"}, Active: true},
+	}
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return(subscriptions, nil)
+	mockStore.On("GetDeliveries", "event-1").Return([]models.Delivery{}, nil)
+	mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "event-1", models.StatusPublished, "", 0).Return(nil)
+
+	response, err := newDeliveryTestHandler(mockStore).PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Published)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_SubscriptionLoadError(t *testing.T) {
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return(([]models.Subscription)(nil), assert.AnError)
+
+	response, err := newDeliveryTestHandler(mockStore).PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.Published)
+	assert.Equal(t, 0, response.Failed)
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0], "failed to load subscriptions")
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_SignsDeliveries(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	var mutex sync.Mutex
+	verified := map[string]error{}
+	newVerifyingServer := func(name string, secrets ...string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := webhook.VerifyRequest(r, webhook.DefaultTolerance, secrets...)
+			mutex.Lock()
+			verified[name] = err
+			mutex.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	// The rotating consumer still only knows the old secret
+	current := newVerifyingServer("current", "whsec_billing")
+	rotating := newVerifyingServer("rotating", "whsec_old")
+
+	subscriptions := []models.Subscription{
+		{ID: "current", URL: current.URL, Active: true, Secret: "whsec_billing"},
+		{ID: "rotating", URL: rotating.URL, Active: true, Secret: "whsec_new", PreviousSecret: "whsec_old", PreviousSecretExpiresAt: &expiresAt},
+	}
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return(subscriptions, nil)
+	mockStore.On("GetDeliveries", "event-1").Return([]models.Delivery{}, nil)
+	mockStore.On("RecordDelivery", "event-1", mock.AnythingOfType("string"), "").Return(nil)
+	mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "event-1", models.StatusPublished, "", 0).Return(nil)
+
+	response, err := newDeliveryTestHandler(mockStore).PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Published)
+
+	require.Len(t, verified, 2)
+	assert.NoError(t, verified["current"])
+	assert.NoError(t, verified["rotating"])
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_SignsDefaultWebhook(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := webhook.VerifyRequest(r, webhook.DefaultTolerance, "whsec_default")
+		assert.NoError(t, err)
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "event-1", models.StatusPublished, "", 0).Return(nil)
+
+	h := newDeliveryTestHandler(mockStore)
+	h.cfg.Publish.WebhookURL = server.URL
+	h.cfg.Publish.WebhookSecret = "whsec_default"
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Published)
+	assert.NotEmpty(t, header.Get(webhook.TimestampHeader))
+	assert.NotEmpty(t, header.Get(webhook.SignatureHeader))
+
+	mockStore.AssertExpectations(t)
+}
+
+// fakePublisher records the events it is asked to publish
+type fakePublisher struct {
+	published []string
+	err       error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event *models.Event) error {
+	p.published = append(p.published, event.ID)
+	return p.err
+}
+
+func (p *fakePublisher) Destination() string {
+	return "kafka://localhost:9092/events"
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func TestHandler_PublishPending_ConfiguredPublisher(t *testing.T) {
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+		{ID: "event-2", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", "failed to write kafka message: broker unavailable", 1, mock.AnythingOfType("time.Time")).Return(nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-2", "failed to write kafka message: broker unavailable", 1, mock.AnythingOfType("time.Time")).Return(nil)
+
+	pub := &fakePublisher{err: errors.New("failed to write kafka message: broker unavailable")}
+	h := newDeliveryTestHandler(mockStore)
+	WithPublisher(pub)(h)
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, []string{"event-1", "event-2"}, pub.published)
+
+	// Failures count against the publisher's destination, not WEBHOOK_URL
+	snapshots := h.circuits.Snapshots()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "kafka://localhost:9092/events", snapshots[0].Destination)
+
+	mockStore.AssertExpectations(t)
+}
+
+// keyedPublisher records the events it is asked to publish and fails those
+// listed in fail; it is safe for concurrent use
+type keyedPublisher struct {
+	mutex     sync.Mutex
+	published []string
+	fail      map[string]bool
+}
+
+func (p *keyedPublisher) Publish(ctx context.Context, event *models.Event) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.published = append(p.published, event.ID)
+	if p.fail[event.ID] {
+		return errors.New("broker unavailable")
+	}
+	return nil
+}
+
+func (p *keyedPublisher) Destination() string {
+	return "kafka://localhost:9092/events"
+}
+
+func (p *keyedPublisher) Close() error {
+	return nil
+}
+
+// publishedBefore reports whether first was published before second
+func (p *keyedPublisher) publishedBefore(first, second string) bool {
+	return slices.Index(p.published, first) < slices.Index(p.published, second)
+}
+
+func TestHandler_PublishPending_OrderedByPartitionKey(t *testing.T) {
+	events := []models.Event{
+		{ID: "a-1", Type: "order.created", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "b-1", Type: "order.created", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-b"},
+		{ID: "a-2", Type: "order.paid", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "c-1", Type: "user.created", Source: "user-service", Status: models.StatusPending},
+		{ID: "b-2", Type: "order.paid", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-b"},
+		{ID: "a-3", Type: "order.shipped", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "a-1", "broker unavailable", 1, mock.AnythingOfType("time.Time")).Return(nil)
+	// a-2 and a-3 must wait for a-1, so they are handed back unattempted
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"a-2", "a-3"}).Return(nil)
+	for _, id := range []string{"b-1", "b-2", "c-1"} {
+		mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), id, models.StatusPublished, "", 0).Return(nil)
+	}
+
+	pub := &keyedPublisher{fail: map[string]bool{"a-1": true}}
+	h := newDeliveryTestHandler(mockStore)
+	h.cfg.Publish.Concurrency = 4
+	WithPublisher(pub)(h)
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.Published)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, 2, response.Blocked)
+
+	assert.ElementsMatch(t, []string{"a-1", "b-1", "b-2", "c-1"}, pub.published)
+	assert.True(t, pub.publishedBefore("b-1", "b-2"))
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_LeaseLost(t *testing.T) {
+	events := []models.Event{
+		{ID: "a-1", Type: "order.created", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "a-2", Type: "order.paid", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "b-1", Type: "order.created", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-b"},
+	}
+
+	mockStore := new(MockOutboxStore)
+	// a-1's lease expired before its turn came, so another worker owns it
+	mockStore.On("RenewLease", mock.AnythingOfType("string"), "a-1", 60*time.Second).Return(storage.ErrLeaseLost)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"a-2"}).Return(nil)
+	// b-1's lease was taken over while it was being published
+	mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "b-1", models.StatusPublished, "", 0).Return(storage.ErrLeaseLost)
+
+	pub := &keyedPublisher{}
+	h := newDeliveryTestHandler(mockStore)
+	WithPublisher(pub)(h)
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.Published)
+	assert.Equal(t, 0, response.Failed)
+	assert.Equal(t, 1, response.Blocked)
+
+	assert.Equal(t, []string{"b-1"}, pub.published)
+
+	mockStore.AssertExpectations(t)
+}
+
+// blockingPublisher waits for its context to end, as a publisher does when
+// the destination does not answer. It calls onPublish first, if set.
+type blockingPublisher struct {
+	mutex     sync.Mutex
+	published []string
+	onPublish func()
+}
+
+func (p *blockingPublisher) Publish(ctx context.Context, event *models.Event) error {
+	p.mutex.Lock()
+	p.published = append(p.published, event.ID)
+	p.mutex.Unlock()
+
+	if p.onPublish != nil {
+		p.onPublish()
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (p *blockingPublisher) Destination() string {
+	return "kafka://localhost:9092/events"
+}
+
+func (p *blockingPublisher) Close() error {
+	return nil
+}
+
+func TestHandler_PublishPending_PublishTimeout(t *testing.T) {
+	events := []models.Event{
+		{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", context.DeadlineExceeded.Error(), 1, mock.AnythingOfType("time.Time")).Return(nil)
+
+	h := newDeliveryTestHandler(mockStore)
+	h.cfg.Publish.PublishTimeout = "20ms"
+	WithPublisher(&blockingPublisher{})(h)
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Failed)
+
+	// A destination that does not answer in time counts as failing
+	snapshots := h.circuits.Snapshots()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, uint32(1), snapshots[0].Failures)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_PublishPending_CancelledReleasesEvents(t *testing.T) {
+	events := []models.Event{
+		{ID: "a-1", Type: "order.created", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "a-2", Type: "order.paid", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "c-1", Type: "user.created", Source: "user-service", Status: models.StatusPending},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	// Neither the event cut short nor those not yet attempted spend a retry
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"a-1"}).Return(nil)
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"a-2"}).Return(nil)
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"c-1"}).Return(nil)
+
+	pub := &blockingPublisher{onPublish: cancel}
+	h := newDeliveryTestHandler(mockStore)
+	h.cfg.Publish.Concurrency = 1
+	WithPublisher(pub)(h)
+
+	response, err := h.PublishPending(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.Claimed)
+	assert.Zero(t, response.Published)
+	assert.Zero(t, response.Failed)
+	assert.Zero(t, response.Blocked)
+	assert.Equal(t, []string{"a-1"}, pub.published)
+
+	// Cancelling says nothing about the destination
+	snapshots := h.circuits.Snapshots()
+	require.Len(t, snapshots, 1)
+	assert.Zero(t, snapshots[0].Failures)
+
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNotCalled(t, "ScheduleRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandler_PublishPending_SkippedReleasesEvents(t *testing.T) {
+	events := []models.Event{
+		{ID: "a-1", Type: "order.created", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "a-2", Type: "order.paid", Source: "order-service", Status: models.StatusPending, PartitionKey: "order-a"},
+		{ID: "c-1", Type: "user.created", Source: "user-service", Status: models.StatusPending},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	// Skipped events stay pending but are not left leased
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"a-1"}).Return(nil).Once()
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"a-2"}).Return(nil).Once()
+	mockStore.On("ReleaseEvents", mock.AnythingOfType("string"), []string{"c-1"}).Return(nil).Once()
+
+	h := newDeliveryTestHandler(mockStore)
+	mockGates := &MockSimulationGates{}
+	mockGates.On("ShouldUsePartialFailureMode").Return(false)
+	mockGates.On("ShouldDisablePublishing").Return(true)
+	h.simulationGates = mockGates
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.Claimed)
+	assert.Zero(t, response.Published)
+	assert.Zero(t, response.Failed)
+
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNotCalled(t, "UpdateEventStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPartitionBatch(t *testing.T) {
+	events := []models.Event{
+		{ID: "a-1", PartitionKey: "order-a"},
+		{ID: "x"},
+		{ID: "b-1", PartitionKey: "order-b"},
+		{ID: "a-2", PartitionKey: "order-a"},
+		{ID: "y"},
+	}
+
+	assert.Equal(t, [][]int{{0, 3}, {1}, {2}, {4}}, partitionBatch(events))
+	assert.Empty(t, partitionBatch(nil))
+}
@@ -1,60 +1,217 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/backoff"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/circuit"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/gates"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/publisher"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/storage"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/stream"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/outbox"
 )
 
 // Special error to indicate publishing was skipped due to simulation
 var ErrPublishingSkipped = errors.New("publishing skipped due to simulation")
 
+const (
+	// idempotencyKeyHeader lets clients retry event creation safely
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// maxIdempotencyKeyLength matches the idempotency_key column
+	maxIdempotencyKeyLength = 255
+)
+
 // Handler handles HTTP requests for the outbox API
 type Handler struct {
 	store           storage.OutboxStoreInterface
 	cfg             *config.Config
 	simulationGates gates.SimulationGatesInterface
+	workerID        string
+	circuits        *circuit.Registry
+	publisher       publisher.Publisher
+	// changes feeds /api/v1/events/stream; nil when the stream is disabled
+	changes *stream.Hub
+	// schemas caches compiled event schemas; see compiledSchema
+	schemas sync.Map
+	// jobs tracks bulk jobs running in the background
+	jobs sync.WaitGroup
+	// jobsCtx outlives requests so bulk jobs can too; cancelJobs interrupts
+	// them on shutdown
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+}
+
+// Option customises a handler
+type Option func(*Handler)
+
+// WithPublisher publishes events that match no subscription with p instead
+// of posting them to the configured WEBHOOK_URL
+func WithPublisher(p publisher.Publisher) Option {
+	return func(h *Handler) {
+		h.publisher = p
+	}
+}
+
+// WithChangeStream serves /api/v1/events/stream from the changes hub
+func WithChangeStream(hub *stream.Hub) Option {
+	return func(h *Handler) {
+		h.changes = hub
+	}
 }
 
 // New creates a new handler instance
-func New(store storage.OutboxStoreInterface, cfg *config.Config, simulationGates gates.SimulationGatesInterface) *Handler {
-	return &Handler{
+func New(store storage.OutboxStoreInterface, cfg *config.Config, simulationGates gates.SimulationGatesInterface, opts ...Option) *Handler {
+	h := &Handler{
 		store:           store,
 		cfg:             cfg,
 		simulationGates: simulationGates,
+		workerID:        newWorkerID(),
+		circuits:        newCircuitRegistry(cfg.Circuit),
+	}
+	h.jobsCtx, h.cancelJobs = context.WithCancel(context.Background())
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// newCircuitRegistry builds the per-destination webhook circuit breakers
+func newCircuitRegistry(cfg config.CircuitConfig) *circuit.Registry {
+	interval, timeout := cfg.Durations()
+	return circuit.NewRegistry(circuit.Settings{
+		FailureThreshold: cfg.FailureThreshold,
+		Interval:         interval,
+		Timeout:          timeout,
+	})
+}
+
+// newWorkerID identifies this process when claiming events so that leases
+// held by different replicas can be told apart
+func newWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "outbox-api"
 	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
 }
 
 // CreateEvent godoc
 // @Summary Create a new outbox event
+// @Description An Idempotency-Key header (or idempotency_key field) makes retries safe: repeating the request returns the original event with 200
+// @Description Data is validated against the JSON Schema registered for the event type; failures return 422 with a JSON pointer per violation
+// @Description priority (0-9) publishes urgent events ahead of older, less urgent ones
+// @Description deliver_at holds the event back until then; an event not delivered by expires_at expires instead of being delivered late
+// @Description CloudEvents 1.0 are also accepted, in structured (application/cloudevents+json) or binary (ce-* headers) content mode; the source and id deduplicate them
+// @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Success 200 {object} map[string]interface{}
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 415 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/events [post]
 func (h *Handler) CreateEvent(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var req models.CreateEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if isCloudEvent(c.Request) {
+		var status int
+		var err error
+		if req, status, err = bindCloudEvent(c); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	event, err := h.store.CreateEvent(&req)
+	if len(req.PartitionKey) > outbox.MaxPartitionKeyLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("partition key must be at most %d characters", outbox.MaxPartitionKeyLength)})
+		return
+	}
+
+	if req.Priority < 0 || req.Priority > outbox.MaxPriority {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("priority must be between 0 and %d", outbox.MaxPriority)})
+		return
+	}
+
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+		if req.DeliverAt != nil && !req.ExpiresAt.After(*req.DeliverAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be after deliver_at"})
+			return
+		}
+	}
+
+	if status, body := h.validateEventData(ctx, &req); body != nil {
+		c.JSON(status, body)
+		return
+	}
+
+	if key := c.GetHeader(idempotencyKeyHeader); key != "" {
+		if req.IdempotencyKey != "" && req.IdempotencyKey != key {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key header and idempotency_key field differ"})
+			return
+		}
+		req.IdempotencyKey = key
+	}
+
+	if req.IdempotencyKey != "" {
+		if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("idempotency key must be at most %d characters", maxIdempotencyKeyLength)})
+			return
+		}
+
+		event, created, err := h.store.CreateEventIdempotent(ctx, &req, h.cfg.Server.IdempotencyWindow())
+		if err != nil {
+			if errors.Is(err, outbox.ErrIdempotencyConflict) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !created {
+			c.JSON(http.StatusOK, gin.H{"event": event})
+			return
+		}
+
+		eventsCreatedTotal.WithLabelValues(event.Type).Inc()
+		c.JSON(http.StatusCreated, gin.H{"event": event})
+		return
+	}
+
+	event, err := h.store.CreateEvent(ctx, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	eventsCreatedTotal.WithLabelValues(event.Type).Inc()
 	c.JSON(http.StatusCreated, gin.H{"event": event})
 }
 
@@ -73,7 +230,7 @@ func (h *Handler) GetEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.store.GetEvent(id)
+	event, err := h.store.GetEvent(c.Request.Context(), id)
 	if err != nil {
 		if err.Error() == "event not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
@@ -86,7 +243,9 @@ func (h *Handler) GetEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"event": event})
 }
 
-// ListEvents retrieves events with pagination and filtering
+// ListEvents retrieves events with pagination and filtering. Passing the
+// previous response's next_cursor as cursor pages by keyset instead of offset,
+// which stays stable while new events are being created.
 func (h *Handler) ListEvents(c *gin.Context) {
 	page := 1
 	limit := 20
@@ -103,15 +262,21 @@ func (h *Handler) ListEvents(c *gin.Context) {
 		}
 	}
 
-	var status *models.EventStatus
-	if s := c.Query("status"); s != "" {
-		eventStatus := models.EventStatus(s)
-		if eventStatus == models.StatusPending || eventStatus == models.StatusPublished || eventStatus == models.StatusFailed || eventStatus == models.StatusRetrying {
-			status = &eventStatus
-		}
+	query, err := parseEventQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	events, total, err := h.store.ListEvents(status, page, limit)
+	if query.Cursor == nil {
+		query.Offset = (page - 1) * limit
+	} else {
+		page = 0
+	}
+	// One extra row tells whether another page follows
+	query.Limit = limit + 1
+
+	events, total, err := h.store.ListEvents(c.Request.Context(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -123,18 +288,100 @@ func (h *Handler) ListEvents(c *gin.Context) {
 		Page:   page,
 		Limit:  limit,
 	}
+	if len(events) > limit {
+		response.Events = events[:limit]
+		response.NextCursor = models.CursorAfter(&events[limit-1]).Encode()
+	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// parseEventQuery reads the event list filters from the query string
+func parseEventQuery(c *gin.Context) (*models.EventQuery, error) {
+	query := &models.EventQuery{
+		Type:          c.Query("type"),
+		Source:        c.Query("source"),
+		PartitionKey:  c.Query("partition_key"),
+		ErrorContains: c.Query("error_contains"),
+	}
+
+	if s := c.Query("status"); s != "" {
+		status := models.EventStatus(s)
+		if !status.Valid() {
+			return nil, fmt.Errorf("invalid status: %s", s)
+		}
+		query.Status = &status
+	}
+
+	times := []struct {
+		param  string
+		target **time.Time
+	}{
+		{"created_after", &query.CreatedAfter},
+		{"created_before", &query.CreatedBefore},
+		{"published_after", &query.PublishedAfter},
+		{"published_before", &query.PublishedBefore},
+		{"deliver_after", &query.DeliverAfter},
+		{"deliver_before", &query.DeliverBefore},
+		{"expires_after", &query.ExpiresAfter},
+		{"expires_before", &query.ExpiresBefore},
+	}
+	for _, t := range times {
+		if v := c.Query(t.param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", t.param)
+			}
+			*t.target = &parsed
+		}
+	}
+
+	counts := []struct {
+		param  string
+		target **int
+	}{
+		{"min_retry_count", &query.MinRetryCount},
+		{"max_retry_count", &query.MaxRetryCount},
+	}
+	for _, n := range counts {
+		if v := c.Query(n.param); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative integer", n.param)
+			}
+			*n.target = &parsed
+		}
+	}
+
+	if m := c.Query("metadata"); m != "" {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(m), &object); err != nil || object == nil {
+			return nil, fmt.Errorf("metadata must be a JSON object")
+		}
+		query.Metadata = json.RawMessage(m)
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		parsed, err := models.ParseEventCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.Cursor = parsed
+	}
+
+	return query, nil
+}
+
 func (h *Handler) RetryEvent(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "event ID is required"})
 		return
 	}
 
-	event, err := h.store.GetEvent(id)
+	event, err := h.store.GetEvent(ctx, id)
 	if err != nil {
 		if err.Error() == "event not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
@@ -149,11 +396,33 @@ func (h *Handler) RetryEvent(c *gin.Context) {
 		return
 	}
 
+	if event.Expired(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "event has expired"})
+		return
+	}
+
+	subscriptions, err := h.activeSubscriptions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Lease the event like a publisher would, so it is not published twice
+	// or ahead of an earlier event with the same partition key
+	claimed, err := h.store.ClaimEvents(ctx, h.workerID, []string{id}, []models.EventStatus{models.StatusFailed}, h.cfg.Publish.Lease())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(claimed) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "event is being retried already or is held back behind an earlier event with the same partition key"})
+		return
+	}
+	event = &claimed[0]
+
 	// Immediately attempt to publish the event
-	err = h.publishEvent(event)
+	err = h.retryFailedEvent(ctx, event, subscriptions)
 	if err != nil {
-		// Publishing failed - update to failed status with incremented retry count
-		h.store.UpdateEventStatus(id, models.StatusFailed, err.Error(), event.RetryCount+1)
 		c.JSON(http.StatusOK, gin.H{
 			"message": "retry attempted but failed", 
 			"error": err.Error(),
@@ -162,17 +431,39 @@ func (h *Handler) RetryEvent(c *gin.Context) {
 		return
 	}
 
-	// Publishing succeeded - update to published status
-	now := time.Now()
-	h.store.UpdateEventStatus(id, models.StatusPublished, "", event.RetryCount)
-	h.store.UpdateEventPublishedAt(id, &now)
-	
 	c.JSON(http.StatusOK, gin.H{
 		"message": "event retried and published successfully",
 		"retry_count": event.RetryCount,
 	})
 }
 
+// retryFailedEvent publishes a failed event claimed by h.workerID again and
+// records the outcome: published, or still failed with its retry count
+// incremented. An event whose publish was cancelled by ctx is left as it was
+// and released.
+func (h *Handler) retryFailedEvent(ctx context.Context, event *models.Event, subscriptions []models.Subscription) error {
+	err := h.publishEvent(ctx, event, subscriptions)
+	if err != nil && ctx.Err() != nil {
+		h.releaseEvents(ctx, []models.Event{*event}, []int{0})
+		return err
+	}
+
+	// The outcome must be recorded even if ctx is cancelled meanwhile
+	recordCtx := context.WithoutCancel(ctx)
+	if err != nil {
+		if !leaseLost(event, h.store.UpdateEventStatus(recordCtx, h.workerID, event.ID, models.StatusFailed, err.Error(), event.RetryCount+1)) {
+			observeFailed(event, failureFailed)
+		}
+		return err
+	}
+
+	now := time.Now()
+	if !leaseLost(event, h.store.UpdateEventStatus(recordCtx, h.workerID, event.ID, models.StatusPublished, "", event.RetryCount)) {
+		observePublished(event, now)
+	}
+	return nil
+}
+
 func (h *Handler) DeleteEvent(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
@@ -180,7 +471,7 @@ func (h *Handler) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	err := h.store.DeleteEvent(id)
+	err := h.store.DeleteEvent(c.Request.Context(), id)
 	if err != nil {
 		if err.Error() == "event not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
@@ -194,6 +485,8 @@ func (h *Handler) DeleteEvent(c *gin.Context) {
 }
 
 func (h *Handler) PublishEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var req models.PublishRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -211,85 +504,286 @@ func (h *Handler) PublishEvents(c *gin.Context) {
 
 	// Get events to publish
 	if len(req.EventIDs) > 0 {
-		// Get specific events by ID
-		events, err = h.getEventsByIDs(req.EventIDs)
+		// Claim the requested events that are pending or retrying and not
+		// held back behind an earlier event with the same partition key
+		statuses := []models.EventStatus{models.StatusPending, models.StatusRetrying}
+		events, err = h.store.ClaimEvents(ctx, h.workerID, req.EventIDs, statuses, h.cfg.Publish.Lease())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 	} else {
-		// Get pending events
-		events, err = h.store.GetPendingEvents(batchSize)
+		// Claim pending events so concurrent publishers never see the same rows
+		events, err = h.store.ClaimPendingEvents(ctx, h.workerID, batchSize, h.cfg.Publish.Lease(), h.cfg.Publish.PriorityAgingInterval())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 	}
 
+	c.JSON(http.StatusOK, h.publishBatch(ctx, events))
+}
+
+// PublishPending publishes up to limit pending events. It is used by the
+// background relay so that events ship without a call to /admin/publish.
+func (h *Handler) PublishPending(ctx context.Context, limit int) (*models.PublishResponse, error) {
+	events, err := h.store.ClaimPendingEvents(ctx, h.workerID, limit, h.cfg.Publish.Lease(), h.cfg.Publish.PriorityAgingInterval())
+	if err != nil {
+		return nil, err
+	}
+
+	response := h.publishBatch(ctx, events)
+	return &response, nil
+}
+
+// publishBatch publishes each event and records the outcome in the store.
+// Once ctx is cancelled no further events are attempted; they and any event
+// whose publish was cut short are released to be claimed again.
+func (h *Handler) publishBatch(ctx context.Context, events []models.Event) models.PublishResponse {
 	if len(events) == 0 {
-		c.JSON(http.StatusOK, models.PublishResponse{
+		return models.PublishResponse{
 			Published: 0,
 			Failed:    0,
+		}
+	}
+
+	subscriptions, err := h.activeSubscriptions(ctx)
+	if err != nil {
+		// Without the routing table no event can be delivered; leave the batch
+		// leased so it is picked up again once the lease expires
+		return models.PublishResponse{
+			Claimed: len(events),
+			Errors:  []string{err.Error()},
+		}
+	}
+
+	// Publish each partition key's events in order on its own lane, so a
+	// failure only holds back later events with the same key
+	var (
+		mutex    sync.Mutex
+		response = models.PublishResponse{Claimed: len(events)}
+		wg       sync.WaitGroup
+	)
+	slots := make(chan struct{}, h.cfg.Publish.Parallelism())
+
+	for _, lane := range partitionBatch(events) {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+
+			for n, i := range lane {
+				if ctx.Err() != nil {
+					h.releaseEvents(ctx, events, lane[n:])
+					return
+				}
+
+				outcome := h.publishBatchEvent(ctx, i, &events[i], subscriptions)
+
+				mutex.Lock()
+				switch outcome.status {
+				case outcomePublished:
+					response.Published++
+				case outcomeFailed:
+					response.Failed++
+					response.Errors = append(response.Errors, outcome.message)
+				case outcomeDeferred:
+					response.Deferred++
+				}
+				mutex.Unlock()
+
+				if outcome.status == outcomePublished {
+					continue
+				}
+
+				// Later events for this key must wait for this one
+				if held := lane[n+1:]; len(held) > 0 {
+					if outcome.status != outcomeCancelled {
+						mutex.Lock()
+						response.Blocked += len(held)
+						mutex.Unlock()
+					}
+					h.releaseEvents(ctx, events, held)
+				}
+				return
+			}
 		})
-		return
 	}
+	wg.Wait()
+
+	return response
+}
+
+// releaseEvents drops the leases on the events at indexes of a batch so they
+// can be claimed again straight away
+func (h *Handler) releaseEvents(ctx context.Context, events []models.Event, indexes []int) {
+	ids := make([]string, len(indexes))
+	for n, i := range indexes {
+		ids[n] = events[i].ID
+	}
+
+	if err := h.store.ReleaseEvents(context.WithoutCancel(ctx), h.workerID, ids); err != nil {
+		fmt.Printf("Warning: failed to release events: %v\n", err)
+	}
+}
 
-	// Publish events
-	published := 0
-	failed := 0
-	var errorMessages []string
+// partitionBatch groups a batch into lanes of event indexes: one lane per
+// partition key holding that key's events in batch order, and one lane for
+// each event without a key
+func partitionBatch(events []models.Event) [][]int {
+	var lanes [][]int
+	laneByKey := make(map[string]int)
 
 	for i, event := range events {
-		var err error
-		
-		// Check for partial failure simulation
-		if h.simulationGates.ShouldUsePartialFailureMode() {
-			// Simulate partial failures - every 3rd event fails, others succeed
-			if i%3 == 2 {
-				err = fmt.Errorf("simulated partial batch failure (event %d in batch)", i+1)
-			} else {
-				// Simulate success without actual webhook call
-				err = nil
-				fmt.Printf("DEBUG: Simulated success for event %s (partial failure mode)\n", event.ID)
-			}
+		if event.PartitionKey == "" {
+			lanes = append(lanes, []int{i})
+			continue
+		}
+		if lane, ok := laneByKey[event.PartitionKey]; ok {
+			lanes[lane] = append(lanes[lane], i)
+			continue
+		}
+		laneByKey[event.PartitionKey] = len(lanes)
+		lanes = append(lanes, []int{i})
+	}
+
+	return lanes
+}
+
+// batchOutcome is the result of publishing one event of a batch
+type batchOutcome struct {
+	status  int
+	message string
+}
+
+const (
+	outcomePublished = iota
+	outcomeFailed
+	outcomeDeferred
+	outcomeSkipped
+	outcomeCancelled
+	outcomeLeaseLost
+)
+
+// publishBatchEvent publishes the event at index i of a batch and records the
+// outcome in the store
+func (h *Handler) publishBatchEvent(ctx context.Context, i int, event *models.Event, subscriptions []models.Subscription) batchOutcome {
+	// The event may have waited behind others in its lane since it was
+	// claimed, so its lease must cover the publish from now on
+	if err := h.store.RenewLease(ctx, h.workerID, event.ID, h.cfg.Publish.Lease()); err != nil {
+		if errors.Is(err, storage.ErrLeaseLost) {
+			fmt.Printf("Warning: lease on event %s expired before it was published\n", event.ID)
+			return batchOutcome{status: outcomeLeaseLost}
+		}
+		if ctx.Err() != nil {
+			h.releaseEvents(ctx, []models.Event{*event}, []int{0})
+			return batchOutcome{status: outcomeCancelled}
+		}
+		fmt.Printf("Warning: failed to renew lease on event %s: %v\n", event.ID, err)
+	}
+
+	var err error
+
+	// Check for partial failure simulation
+	if h.simulationGates.ShouldUsePartialFailureMode() {
+		// Simulate partial failures - every 3rd event fails, others succeed
+		if i%3 == 2 {
+			err = fmt.Errorf("simulated partial batch failure (event %d in batch)", i+1)
 		} else {
-			err = h.publishEvent(&event)
+			// Simulate success without actual webhook call
+			err = nil
 		}
-		
-		if err != nil {
-			if errors.Is(err, ErrPublishingSkipped) {
-				// Publishing was skipped due to simulation - don't count as published or failed
-				fmt.Printf("DEBUG: Event %s skipped due to simulation\n", event.ID)
-				// Event stays in pending state - no status update needed
-			} else {
-				// Actual failure
-				failed++
-				errorMessages = append(errorMessages, fmt.Sprintf("Event %s: %v", event.ID, err))
-
-				// Update event status to failed
-				h.store.UpdateEventStatus(event.ID, models.StatusFailed, err.Error(), event.RetryCount+1)
+	} else {
+		err = h.publishEvent(ctx, event, subscriptions)
+	}
+
+	// The outcome must be recorded even if ctx is cancelled meanwhile
+	recordCtx := context.WithoutCancel(ctx)
+
+	if err != nil {
+		if errors.Is(err, ErrPublishingSkipped) {
+			// Publishing was skipped due to simulation - don't count as published or failed.
+			// The event stays pending, but its lease must go or the backlog
+			// stays leased while publishing is disabled.
+			h.releaseEvents(ctx, []models.Event{*event}, []int{0})
+			return batchOutcome{status: outcomeSkipped}
+		}
+		if ctx.Err() != nil {
+			// Cut short by the caller, which says nothing about the event:
+			// let it be claimed again without spending its retry budget
+			h.releaseEvents(ctx, []models.Event{*event}, []int{0})
+			return batchOutcome{status: outcomeCancelled}
+		}
+		if openErr := (*circuitOpenError)(nil); errors.As(err, &openErr) {
+			// Destination is known to be down - hold the event back until the
+			// circuit allows a trial request without spending its retry budget
+			if leaseLost(event, h.store.ScheduleRetry(recordCtx, h.workerID, event.ID, err.Error(), event.RetryCount, openErr.retryAfter)) {
+				return batchOutcome{status: outcomeLeaseLost}
 			}
-		} else {
-			published++
+			return batchOutcome{status: outcomeDeferred}
+		}
 
-			// Update event status to published
-			now := time.Now()
-			h.store.UpdateEventStatus(event.ID, models.StatusPublished, "", event.RetryCount)
-			h.store.UpdateEventPublishedAt(event.ID, &now)
+		// Schedule a retry, or dead-letter once the retry budget is spent
+		if !h.recordFailure(recordCtx, event, err) {
+			return batchOutcome{status: outcomeLeaseLost}
 		}
+		return batchOutcome{status: outcomeFailed, message: fmt.Sprintf("Event %s: %v", event.ID, err)}
 	}
 
-	response := models.PublishResponse{
-		Published: published,
-		Failed:    failed,
-		Errors:    errorMessages,
+	// Update event status to published
+	now := time.Now()
+	if leaseLost(event, h.store.UpdateEventStatus(recordCtx, h.workerID, event.ID, models.StatusPublished, "", event.RetryCount)) {
+		return batchOutcome{status: outcomeLeaseLost}
 	}
+	observePublished(event, now)
+	return batchOutcome{status: outcomePublished}
+}
 
-	c.JSON(http.StatusOK, response)
+// recordFailure moves a failed event to retrying with an exponential backoff
+// delay, or to the dead letter queue once it has used up
+// cfg.Publish.RetryAttempts retries. It reports false if the event's lease
+// was lost, in which case its new holder records the outcome instead.
+func (h *Handler) recordFailure(ctx context.Context, event *models.Event, publishErr error) bool {
+	retryCount := event.RetryCount + 1
+	if retryCount > h.cfg.Publish.RetryAttempts {
+		err := h.store.MoveToDeadLetter(ctx, h.workerID, event.ID, publishErr.Error(), retryCount)
+		if leaseLost(event, err) {
+			return false
+		}
+		if err != nil {
+			// Fall back to failed so the event is at least taken out of rotation
+			fmt.Printf("Warning: failed to dead-letter event %s: %v\n", event.ID, err)
+			if leaseLost(event, h.store.UpdateEventStatus(ctx, h.workerID, event.ID, models.StatusFailed, publishErr.Error(), retryCount)) {
+				return false
+			}
+			observeFailed(event, failureFailed)
+			return true
+		}
+		observeFailed(event, failureDeadLettered)
+		return true
+	}
+
+	base, max := h.cfg.Publish.RetryBackoff()
+	nextAttemptAt := time.Now().Add(backoff.Delay(retryCount, base, max))
+	if leaseLost(event, h.store.ScheduleRetry(ctx, h.workerID, event.ID, publishErr.Error(), retryCount, nextAttemptAt)) {
+		return false
+	}
+	observeFailed(event, failureRetrying)
+	return true
+}
+
+// leaseLost reports whether recording an event's outcome failed because the
+// event's lease passed to another worker, which then owns the outcome
+func leaseLost(event *models.Event, err error) bool {
+	if !errors.Is(err, storage.ErrLeaseLost) {
+		return false
+	}
+
+	fmt.Printf("Warning: lease on event %s expired before its outcome was recorded\n", event.ID)
+	return true
 }
 
 func (h *Handler) GetStats(c *gin.Context) {
-	stats, err := h.store.GetStats()
+	stats, err := h.store.GetStats(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -298,50 +792,112 @@ func (h *Handler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-func (h *Handler) GetSimulationStatus(c *gin.Context) {
-	status := h.simulationGates.GetSimulationStatus()
-	c.JSON(http.StatusOK, gin.H{
-		"simulation_status": status,
-	})
+const (
+	defaultTimeseriesWindow = time.Hour
+	defaultTimeseriesBucket = time.Minute
+
+	// maxTimeseriesBuckets bounds the size of a timeseries response
+	maxTimeseriesBuckets = 1440
+)
+
+// GetTimeseries godoc
+// @Summary Get event throughput over time
+// @Description Created, published and failed counts and p50/p95 publish latency (published_at - created_at) per bucket, with breakdowns by type and source
+// @Description Buckets are aligned to multiples of the bucket length; the last one contains the current time
+// @Produce json
+// @Param window query string false "Period covered, e.g. 1h (default) or 24h"
+// @Param bucket query string false "Bucket length in whole seconds, e.g. 1m (default); window must be a multiple of it"
+// @Success 200 {object} models.TimeseriesResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/stats/timeseries [get]
+func (h *Handler) GetTimeseries(c *gin.Context) {
+	window, bucket, err := parseTimeseriesParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// End on the boundary after now so the last bucket is the current one
+	seconds := int64(bucket / time.Second)
+	end := time.Unix((time.Now().Unix()/seconds+1)*seconds, 0).UTC()
+	count := int(window / bucket)
+
+	response, err := h.store.GetTimeseries(c.Request.Context(), end.Add(-window), bucket, count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-func (h *Handler) getEventsByIDs(eventIDs []string) ([]models.Event, error) {
-	var events []models.Event
+// parseTimeseriesParams reads and validates the window and bucket parameters
+func parseTimeseriesParams(c *gin.Context) (time.Duration, time.Duration, error) {
+	window, bucket := defaultTimeseriesWindow, defaultTimeseriesBucket
 
-	for _, id := range eventIDs {
-		event, err := h.store.GetEvent(id)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get event %s: %w", id, err)
+	if w := c.Query("window"); w != "" {
+		parsed, err := time.ParseDuration(w)
+		if err != nil || parsed <= 0 {
+			return 0, 0, fmt.Errorf("window must be a positive duration")
 		}
-
-		// Only include events that are pending or retrying
-		if event.Status == models.StatusPending || event.Status == models.StatusRetrying {
-			events = append(events, *event)
+		window = parsed
+	}
+	if b := c.Query("bucket"); b != "" {
+		parsed, err := time.ParseDuration(b)
+		if err != nil || parsed < time.Second || parsed%time.Second != 0 {
+			return 0, 0, fmt.Errorf("bucket must be a whole number of seconds")
 		}
+		bucket = parsed
+	}
+
+	if window%bucket != 0 {
+		return 0, 0, fmt.Errorf("window must be a multiple of bucket")
+	}
+	if window/bucket > maxTimeseriesBuckets {
+		return 0, 0, fmt.Errorf("window must span at most %d buckets", maxTimeseriesBuckets)
 	}
 
-	return events, nil
+	return window, bucket, nil
 }
 
-func (h *Handler) publishEvent(event *models.Event) error {
+func (h *Handler) GetSimulationStatus(c *gin.Context) {
+	status := h.simulationGates.GetSimulationStatus()
+	c.JSON(http.StatusOK, gin.H{
+		"simulation_status": status,
+	})
+}
 
-	shouldDisable := h.simulationGates.ShouldDisablePublishing()
-	fmt.Printf("DEBUG: ShouldDisablePublishing() = %v for event %s\n", shouldDisable, event.ID)
-	
-	if shouldDisable {
-		fmt.Printf("DEBUG: Publishing disabled by simulation gate for event %s\n", event.ID)
+// GetCircuits godoc
+// @Summary Get the state of the webhook circuit breakers
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/circuits [get]
+func (h *Handler) GetCircuits(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"circuits": h.circuits.Snapshots(),
+	})
+}
+
+// publishEvent delivers an event to every active subscription it matches, or
+// to the default webhook when no subscriptions are configured
+func (h *Handler) publishEvent(ctx context.Context, event *models.Event, subscriptions []models.Subscription) error {
+	if h.simulationGates.ShouldDisablePublishing() {
 		return ErrPublishingSkipped
 	}
 
 	if h.simulationGates.CheckCircuitBreaker() {
 		// Circuit is open - fail fast without making request
-		fmt.Printf("DEBUG: Circuit breaker OPEN - failing fast for event %s\n", event.ID)
 		return fmt.Errorf("circuit breaker is open - request blocked")
 	}
 
 	if h.simulationGates.ShouldSimulateNetworkDelays() {
 		// Add artificial delay to simulate network issues
-		time.Sleep(2 * time.Second)
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
 	// Check for forced failures AFTER circuit breaker and delays
@@ -350,44 +906,52 @@ func (h *Handler) publishEvent(event *models.Event) error {
 		return fmt.Errorf("simulated webhook failure (forced by feature gate)")
 	}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second, // Default timeout, could be made configurable
+	if len(subscriptions) == 0 {
+		return h.deliver(ctx, h.defaultPublisher(), event, "")
 	}
 
-	payload := map[string]interface{}{
-		"id":         event.ID,
-		"type":       event.Type,
-		"source":     event.Source,
-		"data":       event.Data,
-		"metadata":   event.Metadata,
-		"created_at": event.CreatedAt,
-	}
+	return h.deliverToSubscriptions(ctx, event, subscriptions)
+}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event data: %w", err)
+// deliver publishes an event through the destination's circuit breaker and
+// records the attempt. subscriptionID is empty for the default publisher.
+// The publish may take cfg.Publish.PublishTimeout; a publish cancelled by
+// ctx is not held against the destination.
+func (h *Handler) deliver(ctx context.Context, p publisher.Publisher, event *models.Event, subscriptionID string) error {
+	breaker := h.circuits.Get(p.Destination())
+	if err := breaker.Allow(); err != nil {
+		return &circuitOpenError{destination: p.Destination(), retryAfter: breaker.RetryAfter()}
 	}
 
-	req, err := http.NewRequest("POST", h.cfg.Publish.WebhookURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
-	}
+	publishCtx, cancel := context.WithTimeout(ctx, h.cfg.Publish.DeliveryDeadline())
+	defer cancel()
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "outbox-api/1.0")
+	var resp publisher.Response
+	started := time.Now()
+	err := p.Publish(publisher.WithResponse(publishCtx, &resp), event)
+	h.recordAttempt(context.WithoutCancel(ctx), event, subscriptionID, p.Destination(), started, resp, err)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		h.simulationGates.RecordCircuitBreakerFailure()
-		return fmt.Errorf("failed to send webhook request: %w", err)
+	if err != nil && ctx.Err() != nil {
+		breaker.RecordCancelled()
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if err != nil {
+		breaker.RecordFailure()
 		h.simulationGates.RecordCircuitBreakerFailure()
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		return err
 	}
 
+	breaker.RecordSuccess()
 	h.simulationGates.RecordCircuitBreakerSuccess()
 	return nil
 }
+
+// defaultPublisher returns where events without subscriptions are published:
+// the configured publisher, or else the WEBHOOK_URL webhook
+func (h *Handler) defaultPublisher() publisher.Publisher {
+	if h.publisher != nil {
+		return h.publisher
+	}
+	return publisher.NewWebhook(h.cfg.Publish.WebhookURL, h.cfg.Publish.WebhookSecrets(), h.cfg.Publish.EventFormat)
+}
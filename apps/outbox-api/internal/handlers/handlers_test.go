@@ -2,15 +2,22 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/circuit"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/outbox"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -72,12 +79,20 @@ func (m *MockSimulationGates) GetSimulationStatus() map[string]interface{} {
 	return args.Get(0).(map[string]interface{})
 }
 
-func (m *MockOutboxStore) CreateEvent(req *models.CreateEventRequest) (*models.Event, error) {
+func (m *MockOutboxStore) CreateEvent(_ context.Context, req *models.CreateEventRequest) (*models.Event, error) {
 	args := m.Called(req)
 	return args.Get(0).(*models.Event), args.Error(1)
 }
 
-func (m *MockOutboxStore) GetEvent(id string) (*models.Event, error) {
+func (m *MockOutboxStore) CreateEventIdempotent(_ context.Context, req *models.CreateEventRequest, retention time.Duration) (*models.Event, bool, error) {
+	args := m.Called(req, retention)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Event), args.Bool(1), args.Error(2)
+}
+
+func (m *MockOutboxStore) GetEvent(_ context.Context, id string) (*models.Event, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -85,27 +100,52 @@ func (m *MockOutboxStore) GetEvent(id string) (*models.Event, error) {
 	return args.Get(0).(*models.Event), args.Error(1)
 }
 
-func (m *MockOutboxStore) ListEvents(status *models.EventStatus, page, limit int) ([]models.Event, int, error) {
-	args := m.Called(status, page, limit)
+func (m *MockOutboxStore) ListEvents(_ context.Context, query *models.EventQuery) ([]models.Event, int, error) {
+	args := m.Called(query)
 	return args.Get(0).([]models.Event), args.Int(1), args.Error(2)
 }
 
-func (m *MockOutboxStore) GetPendingEvents(limit int) ([]models.Event, error) {
-	args := m.Called(limit)
+func (m *MockOutboxStore) GetPendingEvents(_ context.Context, limit int, aging time.Duration) ([]models.Event, error) {
+	args := m.Called(limit, aging)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockOutboxStore) ClaimPendingEvents(_ context.Context, workerID string, limit int, lease, aging time.Duration) ([]models.Event, error) {
+	args := m.Called(workerID, limit, lease, aging)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockOutboxStore) ClaimEvents(_ context.Context, workerID string, ids []string, statuses []models.EventStatus, lease time.Duration) ([]models.Event, error) {
+	args := m.Called(workerID, ids, statuses, lease)
 	return args.Get(0).([]models.Event), args.Error(1)
 }
 
-func (m *MockOutboxStore) UpdateEventStatus(id string, status models.EventStatus, lastError string, retryCount int) error {
-	args := m.Called(id, status, lastError, retryCount)
+func (m *MockOutboxStore) ReleaseEvents(_ context.Context, workerID string, ids []string) error {
+	args := m.Called(workerID, ids)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) RenewLease(_ context.Context, workerID, id string, lease time.Duration) error {
+	args := m.Called(workerID, id, lease)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) UpdateEventStatus(_ context.Context, workerID, id string, status models.EventStatus, lastError string, retryCount int) error {
+	args := m.Called(workerID, id, status, lastError, retryCount)
 	return args.Error(0)
 }
 
-func (m *MockOutboxStore) DeleteEvent(id string) error {
+func (m *MockOutboxStore) ScheduleRetry(_ context.Context, workerID, id string, lastError string, retryCount int, nextAttemptAt time.Time) error {
+	args := m.Called(workerID, id, lastError, retryCount, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) DeleteEvent(_ context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockOutboxStore) GetStats() (*models.StatsResponse, error) {
+func (m *MockOutboxStore) GetStats(_ context.Context) (*models.StatsResponse, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -113,12 +153,203 @@ func (m *MockOutboxStore) GetStats() (*models.StatsResponse, error) {
 	return args.Get(0).(*models.StatsResponse), args.Error(1)
 }
 
-func (m *MockOutboxStore) UpdateEventPublishedAt(id string, publishedAt *time.Time) error {
+func (m *MockOutboxStore) CountEvents(_ context.Context, query *models.EventQuery) (int, error) {
+	args := m.Called(query)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxStore) ListEventIDs(_ context.Context, query *models.EventQuery, afterID string, limit int) ([]string, error) {
+	args := m.Called(query, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockOutboxStore) ApplyBulkAction(_ context.Context, jobID string, action models.BulkAction, ids []string) (int, error) {
+	args := m.Called(jobID, action, ids)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxStore) CreateBulkJob(_ context.Context, job *models.BulkJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) UpdateBulkJob(_ context.Context, job *models.BulkJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) GetBulkJob(_ context.Context, id string) (*models.BulkJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BulkJob), args.Error(1)
+}
+
+func (m *MockOutboxStore) MoveToDeadLetter(_ context.Context, workerID, id string, lastError string, retryCount int) error {
+	args := m.Called(workerID, id, lastError, retryCount)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) ListDeadLetters(_ context.Context, page, limit int) ([]models.DeadLetter, int, error) {
+	args := m.Called(page, limit)
+	return args.Get(0).([]models.DeadLetter), args.Int(1), args.Error(2)
+}
+
+func (m *MockOutboxStore) GetDeadLetter(_ context.Context, id string) (*models.DeadLetter, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeadLetter), args.Error(1)
+}
+
+func (m *MockOutboxStore) RequeueDeadLetters(_ context.Context, ids []string) (int, error) {
+	args := m.Called(ids)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxStore) RequeueAllDeadLetters(_ context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxStore) PurgeDeadLetter(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) PurgeDeadLetters(_ context.Context, olderThan time.Time) (int, error) {
+	args := m.Called(olderThan)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxStore) UpdateEventPublishedAt(_ context.Context, id string, publishedAt *time.Time) error {
 	args := m.Called(id, publishedAt)
 	return args.Error(0)
 }
 
+func (m *MockOutboxStore) CreateSubscription(_ context.Context, req *models.SubscriptionRequest) (*models.Subscription, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockOutboxStore) GetSubscription(_ context.Context, id string) (*models.Subscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockOutboxStore) ListSubscriptions(_ context.Context) ([]models.Subscription, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockOutboxStore) UpdateSubscription(_ context.Context, id string, req *models.SubscriptionRequest) (*models.Subscription, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockOutboxStore) RotateSubscriptionSecret(_ context.Context, id string, secret string, previousExpiresAt time.Time) (*models.Subscription, error) {
+	args := m.Called(id, secret, previousExpiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockOutboxStore) DeleteSubscription(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) GetDeliveries(_ context.Context, eventID string) ([]models.Delivery, error) {
+	args := m.Called(eventID)
+	return args.Get(0).([]models.Delivery), args.Error(1)
+}
+
+func (m *MockOutboxStore) RecordDelivery(_ context.Context, eventID, subscriptionID string, lastError string) error {
+	args := m.Called(eventID, subscriptionID, lastError)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) RecordAttempt(_ context.Context, attempt *models.DeliveryAttempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) GetTimeseries(_ context.Context, from time.Time, bucket time.Duration, count int) (*models.TimeseriesResponse, error) {
+	args := m.Called(from, bucket, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TimeseriesResponse), args.Error(1)
+}
+
+func (m *MockOutboxStore) ListEventChanges(_ context.Context, filter *models.ChangeFilter, afterID int64, limit int) ([]models.EventChange, error) {
+	args := m.Called(filter, afterID, limit)
+	return args.Get(0).([]models.EventChange), args.Error(1)
+}
+
+func (m *MockOutboxStore) ListAttempts(_ context.Context, eventID string) ([]models.DeliveryAttempt, error) {
+	args := m.Called(eventID)
+	return args.Get(0).([]models.DeliveryAttempt), args.Error(1)
+}
+
+func (m *MockOutboxStore) CreateSchema(_ context.Context, eventType string, schema json.RawMessage) (*models.EventSchema, error) {
+	args := m.Called(eventType, schema)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EventSchema), args.Error(1)
+}
+
+func (m *MockOutboxStore) GetSchema(_ context.Context, eventType string, version int) (*models.EventSchema, error) {
+	args := m.Called(eventType, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EventSchema), args.Error(1)
+}
+
+func (m *MockOutboxStore) ListSchemas(_ context.Context, eventType string) ([]models.EventSchema, error) {
+	args := m.Called(eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventSchema), args.Error(1)
+}
+
+func (m *MockOutboxStore) DeleteSchema(_ context.Context, eventType string, version int) error {
+	args := m.Called(eventType, version)
+	return args.Error(0)
+}
+
+// expectNoSchema lets event creation through schema validation in lenient mode
+func expectNoSchema(mockStore *MockOutboxStore) {
+	mockStore.On("GetSchema", mock.Anything, 0).Return(nil, errors.New("schema not found")).Maybe()
+}
+
+// expectAttempts lets tests that publish renew leases and record delivery
+// attempts without asserting on each one
+func expectAttempts(mockStore *MockOutboxStore) {
+	mockStore.On("RenewLease", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Maybe()
+	mockStore.On("RecordAttempt", mock.AnythingOfType("*models.DeliveryAttempt")).Return(nil).Maybe()
+}
+
 func setupTestRouter(store *MockOutboxStore) *gin.Engine {
+	expectAttempts(store)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -152,12 +383,17 @@ func setupTestRouter(store *MockOutboxStore) *gin.Engine {
 	{
 		admin.POST("/publish", h.PublishEvents)
 		admin.GET("/stats", h.GetStats)
+		admin.GET("/stats/timeseries", h.GetTimeseries)
 	}
 
 	return router
 }
 
 func TestHandler_CreateEvent(t *testing.T) {
+	deliverAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	expiresAt := deliverAt.Add(time.Hour)
+	expired := time.Now().Add(-time.Minute)
+
 	tests := []struct {
 		name           string
 		requestBody    interface{}
@@ -184,7 +420,7 @@ func TestHandler_CreateEvent(t *testing.T) {
 					RetryCount: 0,
 					LastError:  "",
 				}
-				mockStore.On("CreateEvent", mock.AnythingOfType("*models.CreateEventRequest")).Return(expectedEvent, nil)
+				mockStore.On("CreateEvent", mock.IsType(&models.CreateEventRequest{})).Return(expectedEvent, nil)
 			},
 			expectedStatus: http.StatusCreated,
 		},
@@ -198,6 +434,101 @@ func TestHandler_CreateEvent(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "Key: 'CreateEventRequest.Source' Error:Field validation for 'Source' failed on the 'required' tag",
 		},
+		{
+			name: "event with partition key",
+			requestBody: models.CreateEventRequest{
+				Type:         "order.paid",
+				Source:       "order-service",
+				Data:         json.RawMessage(`{"order_id": "123"}`),
+				PartitionKey: "order-123",
+			},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEvent", mock.MatchedBy(func(req *models.CreateEventRequest) bool {
+					return req.PartitionKey == "order-123"
+				})).Return(&models.Event{ID: "test-id", PartitionKey: "order-123"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "partition key too long",
+			requestBody: models.CreateEventRequest{
+				Type:         "order.paid",
+				Source:       "order-service",
+				Data:         json.RawMessage(`{"order_id": "123"}`),
+				PartitionKey: strings.Repeat("k", 256),
+			},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "partition key must be at most 255 characters",
+		},
+		{
+			name: "urgent event",
+			requestBody: models.CreateEventRequest{
+				Type:     "payment.succeeded",
+				Source:   "payment-service",
+				Data:     json.RawMessage(`{"payment_id": "123"}`),
+				Priority: 9,
+			},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEvent", mock.MatchedBy(func(req *models.CreateEventRequest) bool {
+					return req.Priority == 9
+				})).Return(&models.Event{ID: "test-id", Priority: 9}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "priority out of range",
+			requestBody: models.CreateEventRequest{
+				Type:     "payment.succeeded",
+				Source:   "payment-service",
+				Data:     json.RawMessage(`{"payment_id": "123"}`),
+				Priority: 10,
+			},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "priority must be between 0 and 9",
+		},
+		{
+			name: "scheduled event",
+			requestBody: models.CreateEventRequest{
+				Type:      "reminder.due",
+				Source:    "billing-service",
+				Data:      json.RawMessage(`{"invoice_id": "123"}`),
+				DeliverAt: &deliverAt,
+				ExpiresAt: &expiresAt,
+			},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEvent", mock.MatchedBy(func(req *models.CreateEventRequest) bool {
+					return req.DeliverAt != nil && req.DeliverAt.Equal(deliverAt) && req.ExpiresAt != nil && req.ExpiresAt.Equal(expiresAt)
+				})).Return(&models.Event{ID: "test-id", DeliverAt: &deliverAt, ExpiresAt: &expiresAt}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "already expired",
+			requestBody: models.CreateEventRequest{
+				Type:      "reminder.due",
+				Source:    "billing-service",
+				Data:      json.RawMessage(`{"invoice_id": "123"}`),
+				ExpiresAt: &expired,
+			},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "expires_at must be in the future",
+		},
+		{
+			name: "expires before delivery",
+			requestBody: models.CreateEventRequest{
+				Type:      "reminder.due",
+				Source:    "billing-service",
+				Data:      json.RawMessage(`{"invoice_id": "123"}`),
+				DeliverAt: &expiresAt,
+				ExpiresAt: &deliverAt,
+			},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "expires_at must be after deliver_at",
+		},
 		{
 			name: "storage error",
 			requestBody: models.CreateEventRequest{
@@ -207,7 +538,7 @@ func TestHandler_CreateEvent(t *testing.T) {
 				Metadata: nil,
 			},
 			mockSetup: func(mockStore *MockOutboxStore) {
-				mockStore.On("CreateEvent", mock.AnythingOfType("*models.CreateEventRequest")).Return((*models.Event)(nil), assert.AnError)
+				mockStore.On("CreateEvent", mock.IsType(&models.CreateEventRequest{})).Return((*models.Event)(nil), assert.AnError)
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  "assert.AnError general error for testing",
@@ -217,6 +548,7 @@ func TestHandler_CreateEvent(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockStore := new(MockOutboxStore)
+			expectNoSchema(mockStore)
 			tt.mockSetup(mockStore)
 
 			router := setupTestRouter(mockStore)
@@ -250,6 +582,131 @@ func TestHandler_CreateEvent(t *testing.T) {
 	}
 }
 
+func TestHandler_CreateEvent_IdempotencyKey(t *testing.T) {
+	event := &models.Event{
+		ID:     "original-id",
+		Type:   "order.created",
+		Source: "order-service",
+		Data:   json.RawMessage(`{"order_id": "123"}`),
+		Status: models.StatusPending,
+	}
+	withKey := func(key string) interface{} {
+		return mock.MatchedBy(func(req *models.CreateEventRequest) bool {
+			return req.IdempotencyKey == key
+		})
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		bodyKey        string
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:   "first request with header creates event",
+			header: "order-123",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withKey("order-123"), 24*time.Hour).Return(event, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:    "retried request with body key returns original event",
+			bodyKey: "order-123",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withKey("order-123"), 24*time.Hour).Return(event, false, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "matching header and body key",
+			header:  "order-123",
+			bodyKey: "order-123",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withKey("order-123"), 24*time.Hour).Return(event, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "conflicting header and body key",
+			header:         "order-123",
+			bodyKey:        "order-456",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Idempotency-Key header and idempotency_key field differ",
+		},
+		{
+			name:           "key too long",
+			header:         strings.Repeat("k", 256),
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "idempotency key must be at most 255 characters",
+		},
+		{
+			name:   "key reused with different payload",
+			header: "order-123",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withKey("order-123"), 24*time.Hour).Return(nil, false, fmt.Errorf("failed to create event: %w", outbox.ErrIdempotencyConflict))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "idempotency key already used with a different payload",
+		},
+		{
+			name:   "storage error",
+			header: "order-123",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateEventIdempotent", withKey("order-123"), 24*time.Hour).Return(nil, false, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			expectNoSchema(mockStore)
+			tt.mockSetup(mockStore)
+
+			router := setupTestRouter(mockStore)
+
+			jsonBody, err := json.Marshal(models.CreateEventRequest{
+				Type:           "order.created",
+				Source:         "order-service",
+				Data:           json.RawMessage(`{"order_id": "123"}`),
+				IdempotencyKey: tt.bodyKey,
+			})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("Idempotency-Key", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			} else {
+				assert.Equal(t, "original-id", response["event"].(map[string]interface{})["id"])
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_GetEvent(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -315,12 +772,18 @@ func TestHandler_GetEvent(t *testing.T) {
 }
 
 func TestHandler_ListEvents(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	retries := 3
+	cursor := &models.EventCursor{CreatedAt: since, ID: "event-9"}
+
 	tests := []struct {
-		name           string
-		queryParams    string
-		mockSetup      func(*MockOutboxStore)
-		expectedStatus int
-		expectedCount  int
+		name               string
+		queryParams        string
+		mockSetup          func(*MockOutboxStore)
+		expectedStatus     int
+		expectedCount      int
+		expectedNextCursor string
+		expectedError      string
 	}{
 		{
 			name:        "list all events",
@@ -340,7 +803,7 @@ func TestHandler_ListEvents(t *testing.T) {
 						Status: models.StatusPublished,
 					},
 				}
-				mockStore.On("ListEvents", mock.AnythingOfType("*models.EventStatus"), 1, 20).Return(events, 2, nil)
+				mockStore.On("ListEvents", &models.EventQuery{Limit: 21}).Return(events, 2, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedCount:  2,
@@ -358,7 +821,7 @@ func TestHandler_ListEvents(t *testing.T) {
 					},
 				}
 				status := models.StatusPending
-				mockStore.On("ListEvents", &status, 1, 20).Return(events, 1, nil)
+				mockStore.On("ListEvents", &models.EventQuery{Status: &status, Limit: 21}).Return(events, 1, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedCount:  1,
@@ -375,11 +838,106 @@ func TestHandler_ListEvents(t *testing.T) {
 						Status: models.StatusPending,
 					},
 				}
-				mockStore.On("ListEvents", mock.AnythingOfType("*models.EventStatus"), 2, 10).Return(events, 1, nil)
+				mockStore.On("ListEvents", &models.EventQuery{Offset: 10, Limit: 11}).Return(events, 1, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedCount:  1,
 		},
+		{
+			name:        "filters",
+			queryParams: "?type=order.*&source=billing&partition_key=order-1&created_after=2024-01-01T00:00:00Z&min_retry_count=3&error_contains=timeout&metadata=" + url.QueryEscape(`{"tenant":"acme"}`),
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListEvents", &models.EventQuery{
+					Type:          "order.*",
+					Source:        "billing",
+					PartitionKey:  "order-1",
+					CreatedAfter:  &since,
+					MinRetryCount: &retries,
+					ErrorContains: "timeout",
+					Metadata:      json.RawMessage(`{"tenant":"acme"}`),
+					Limit:         21,
+				}).Return([]models.Event{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  0,
+		},
+		{
+			name:        "scheduling filters",
+			queryParams: "?status=expired&deliver_after=2024-01-01T00:00:00Z&expires_before=2024-01-01T00:00:00Z",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				expired := models.StatusExpired
+				mockStore.On("ListEvents", &models.EventQuery{
+					Status:        &expired,
+					DeliverAfter:  &since,
+					ExpiresBefore: &since,
+					Limit:         21,
+				}).Return([]models.Event{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  0,
+		},
+		{
+			name:        "more results return a next cursor",
+			queryParams: "?limit=2",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				events := []models.Event{
+					{ID: "event-3", CreatedAt: since.Add(2 * time.Minute)},
+					{ID: "event-2", CreatedAt: since.Add(time.Minute)},
+					{ID: "event-1", CreatedAt: since},
+				}
+				mockStore.On("ListEvents", &models.EventQuery{Limit: 3}).Return(events, 3, nil)
+			},
+			expectedStatus:     http.StatusOK,
+			expectedCount:      2,
+			expectedNextCursor: (&models.EventCursor{CreatedAt: since.Add(time.Minute), ID: "event-2"}).Encode(),
+		},
+		{
+			name:        "cursor ignores page",
+			queryParams: "?page=5&cursor=" + cursor.Encode(),
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListEvents", mock.MatchedBy(func(q *models.EventQuery) bool {
+					return q.Offset == 0 && q.Limit == 21 && q.Cursor != nil &&
+						q.Cursor.ID == "event-9" && q.Cursor.CreatedAt.Equal(since)
+				})).Return([]models.Event{{ID: "event-8"}}, 1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "unknown status",
+			queryParams:    "?status=done",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid status: done",
+		},
+		{
+			name:           "invalid timestamp",
+			queryParams:    "?published_before=yesterday",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "published_before must be an RFC 3339 timestamp",
+		},
+		{
+			name:           "invalid retry count",
+			queryParams:    "?max_retry_count=-1",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "max_retry_count must be a non-negative integer",
+		},
+		{
+			name:           "metadata not an object",
+			queryParams:    "?metadata=%5B1%5D",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "metadata must be a JSON object",
+		},
+		{
+			name:           "invalid cursor",
+			queryParams:    "?cursor=bogus",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid cursor",
+		},
 	}
 
 	for _, tt := range tests {
@@ -403,6 +961,12 @@ func TestHandler_ListEvents(t *testing.T) {
 				err = json.Unmarshal(w.Body.Bytes(), &response)
 				require.NoError(t, err)
 				assert.Len(t, response.Events, tt.expectedCount)
+				assert.Equal(t, tt.expectedNextCursor, response.NextCursor)
+			} else {
+				var response map[string]interface{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedError, response["error"])
 			}
 
 			mockStore.AssertExpectations(t)
@@ -430,10 +994,32 @@ func TestHandler_RetryEvent(t *testing.T) {
 					RetryCount: 2,
 				}
 				mockStore.On("GetEvent", "test-id").Return(event, nil)
-				mockStore.On("UpdateEventStatus", "test-id", models.StatusFailed, mock.AnythingOfType("string"), 3).Return(nil)
+				mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+				mockStore.On("ClaimEvents", mock.AnythingOfType("string"), []string{"test-id"}, []models.EventStatus{models.StatusFailed}, mock.AnythingOfType("time.Duration")).
+					Return([]models.Event{*event}, nil)
+				mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "test-id", models.StatusFailed, mock.AnythingOfType("string"), 3).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:    "event claimed elsewhere or held back by its partition",
+			eventID: "test-id",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				event := &models.Event{
+					ID:           "test-id",
+					Type:         "test.event",
+					Source:       "test-service",
+					Status:       models.StatusFailed,
+					PartitionKey: "order-1",
+				}
+				mockStore.On("GetEvent", "test-id").Return(event, nil)
+				mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+				mockStore.On("ClaimEvents", mock.AnythingOfType("string"), []string{"test-id"}, []models.EventStatus{models.StatusFailed}, mock.AnythingOfType("time.Duration")).
+					Return([]models.Event{}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "held back behind an earlier event",
+		},
 		{
 			name:    "event not found",
 			eventID: "non-existent-id",
@@ -458,6 +1044,23 @@ func TestHandler_RetryEvent(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "only failed events can be retried",
 		},
+		{
+			name:    "retry expired event",
+			eventID: "test-id",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				expiresAt := time.Now().Add(-time.Minute)
+				event := &models.Event{
+					ID:        "test-id",
+					Type:      "test.event",
+					Source:    "test-service",
+					Status:    models.StatusFailed,
+					ExpiresAt: &expiresAt,
+				}
+				mockStore.On("GetEvent", "test-id").Return(event, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "event has expired",
+		},
 	}
 
 	for _, tt := range tests {
@@ -607,6 +1210,104 @@ func TestHandler_GetStats(t *testing.T) {
 	}
 }
 
+func TestHandler_GetTimeseries(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		bucket         time.Duration
+		count          int
+		storeErr       error
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "defaults to an hour of minutes",
+			bucket:         time.Minute,
+			count:          60,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "custom window and bucket",
+			query:          "?window=24h&bucket=1h",
+			bucket:         time.Hour,
+			count:          24,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid window",
+			query:          "?window=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "window must be a positive duration",
+		},
+		{
+			name:           "sub-second bucket",
+			query:          "?bucket=500ms",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "bucket must be a whole number of seconds",
+		},
+		{
+			name:           "window not a multiple of bucket",
+			query:          "?window=90s&bucket=1m",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "window must be a multiple of bucket",
+		},
+		{
+			name:           "too many buckets",
+			query:          "?window=48h&bucket=1m",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "window must span at most 1440 buckets",
+		},
+		{
+			name:           "storage error",
+			bucket:         time.Minute,
+			count:          60,
+			storeErr:       assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			if tt.count > 0 {
+				// The window ends on the bucket boundary after now
+				aligned := mock.MatchedBy(func(from time.Time) bool {
+					end := from.Add(time.Duration(tt.count) * tt.bucket)
+					return from.Unix()%int64(tt.bucket/time.Second) == 0 &&
+						end.After(time.Now()) && !end.After(time.Now().Add(tt.bucket))
+				})
+				if tt.storeErr != nil {
+					mockStore.On("GetTimeseries", aligned, tt.bucket, tt.count).Return(nil, tt.storeErr)
+				} else {
+					mockStore.On("GetTimeseries", aligned, tt.bucket, tt.count).Return(&models.TimeseriesResponse{
+						BucketSeconds: int(tt.bucket / time.Second),
+						Buckets:       make([]models.StatsBucket, tt.count),
+					}, nil)
+				}
+			}
+
+			router := setupTestRouter(mockStore)
+			req, err := http.NewRequest("GET", "/admin/stats/timeseries"+tt.query, nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response models.TimeseriesResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Len(t, response.Buckets, tt.count)
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_PublishEvents(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -637,10 +1338,10 @@ func TestHandler_PublishEvents(t *testing.T) {
 						RetryCount: 0,
 					},
 				}
-				mockStore.On("GetPendingEvents", 5).Return(events, nil)
-				// Expect failed status due to webhook connection failure
-				mockStore.On("UpdateEventStatus", "event-1", models.StatusFailed, mock.AnythingOfType("string"), 1).Return(nil)
-				mockStore.On("UpdateEventStatus", "event-2", models.StatusFailed, mock.AnythingOfType("string"), 1).Return(nil)
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+				// Expect dead-lettering due to webhook connection failure with no retries configured
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 1).Return(nil)
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-2", mock.AnythingOfType("string"), 1).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -664,11 +1365,11 @@ func TestHandler_PublishEvents(t *testing.T) {
 					Status:     models.StatusRetrying,
 					RetryCount: 1,
 				}
-				mockStore.On("GetEvent", "event-1").Return(event1, nil)
-				mockStore.On("GetEvent", "event-2").Return(event2, nil)
-				// Expect failed status due to webhook connection failure
-				mockStore.On("UpdateEventStatus", "event-1", models.StatusFailed, mock.AnythingOfType("string"), 1).Return(nil)
-				mockStore.On("UpdateEventStatus", "event-2", models.StatusFailed, mock.AnythingOfType("string"), 2).Return(nil)
+				mockStore.On("ClaimEvents", mock.AnythingOfType("string"), []string{"event-1", "event-2"}, []models.EventStatus{models.StatusPending, models.StatusRetrying}, mock.AnythingOfType("time.Duration")).
+					Return([]models.Event{*event1, *event2}, nil)
+				// Expect dead-lettering due to webhook connection failure with no retries configured
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 1).Return(nil)
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-2", mock.AnythingOfType("string"), 2).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -678,30 +1379,20 @@ func TestHandler_PublishEvents(t *testing.T) {
 				BatchSize: 5,
 			},
 			mockSetup: func(mockStore *MockOutboxStore) {
-				mockStore.On("GetPendingEvents", 5).Return([]models.Event{}, nil)
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return([]models.Event{}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name: "no specific events to publish (all non-pending)",
+			name: "no specific events to publish (none claimable)",
 			requestBody: models.PublishRequest{
 				EventIDs: []string{"event-1", "event-2"},
 			},
 			mockSetup: func(mockStore *MockOutboxStore) {
-				event1 := &models.Event{
-					ID:     "event-1",
-					Type:   "test.event",
-					Source: "test-service",
-					Status: models.StatusPublished, // Already published
-				}
-				event2 := &models.Event{
-					ID:     "event-2",
-					Type:   "test.event",
-					Source: "test-service",
-					Status: models.StatusFailed, // Failed, not retrying
-				}
-				mockStore.On("GetEvent", "event-1").Return(event1, nil)
-				mockStore.On("GetEvent", "event-2").Return(event2, nil)
+				// Already published, failed, expired, leased elsewhere or held
+				// back behind an earlier event with the same partition key
+				mockStore.On("ClaimEvents", mock.AnythingOfType("string"), []string{"event-1", "event-2"}, []models.EventStatus{models.StatusPending, models.StatusRetrying}, mock.AnythingOfType("time.Duration")).
+					Return([]models.Event{}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -719,21 +1410,22 @@ func TestHandler_PublishEvents(t *testing.T) {
 				BatchSize: 5,
 			},
 			mockSetup: func(mockStore *MockOutboxStore) {
-				mockStore.On("GetPendingEvents", 5).Return(([]models.Event)(nil), assert.AnError)
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(([]models.Event)(nil), assert.AnError)
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  "assert.AnError general error for testing",
 		},
 		{
-			name: "storage error getting specific event",
+			name: "storage error claiming specific events",
 			requestBody: models.PublishRequest{
 				EventIDs: []string{"event-1"},
 			},
 			mockSetup: func(mockStore *MockOutboxStore) {
-				mockStore.On("GetEvent", "event-1").Return((*models.Event)(nil), assert.AnError)
+				mockStore.On("ClaimEvents", mock.AnythingOfType("string"), []string{"event-1"}, []models.EventStatus{models.StatusPending, models.StatusRetrying}, mock.AnythingOfType("time.Duration")).
+					Return(([]models.Event)(nil), assert.AnError)
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedError:  "failed to get event event-1: assert.AnError general error for testing",
+			expectedError:  "assert.AnError general error for testing",
 		},
 		{
 			name: "storage error updating event status",
@@ -750,8 +1442,9 @@ func TestHandler_PublishEvents(t *testing.T) {
 						RetryCount: 0,
 					},
 				}
-				mockStore.On("GetPendingEvents", 5).Return(events, nil)
-				mockStore.On("UpdateEventStatus", "event-1", models.StatusFailed, mock.AnythingOfType("string"), 1).Return(nil)
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 1).Return(assert.AnError)
+				mockStore.On("UpdateEventStatus", mock.AnythingOfType("string"), "event-1", models.StatusFailed, mock.AnythingOfType("string"), 1).Return(nil)
 			},
 			expectedStatus: http.StatusOK, // HTTP errors are handled gracefully
 		},
@@ -759,7 +1452,7 @@ func TestHandler_PublishEvents(t *testing.T) {
 			name:        "use default batch size when not provided",
 			requestBody: models.PublishRequest{}, // Empty request
 			mockSetup: func(mockStore *MockOutboxStore) {
-				mockStore.On("GetPendingEvents", 10).Return([]models.Event{}, nil) // Default batch size is 10
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 10, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return([]models.Event{}, nil) // Default batch size is 10
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -768,6 +1461,7 @@ func TestHandler_PublishEvents(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockStore := new(MockOutboxStore)
+			mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil).Maybe()
 			tt.mockSetup(mockStore)
 
 			// Setup config with webhook URL for testing
@@ -792,6 +1486,7 @@ func TestHandler_PublishEvents(t *testing.T) {
 			mockGates.On("CheckCircuitBreaker").Return(false)
 			mockGates.On("RecordCircuitBreakerFailure").Return()
 			mockGates.On("RecordCircuitBreakerSuccess").Return()
+			expectAttempts(mockStore)
 			h := New(mockStore, cfg, mockGates)
 
 			admin := router.Group("/admin")
@@ -828,3 +1523,227 @@ func TestHandler_PublishEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_PublishPending(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockSetup         func(*MockOutboxStore)
+		expectedError     string
+		expectedPublished int
+		expectedFailed    int
+	}{
+		{
+			name: "no pending events",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return([]models.Event{}, nil)
+			},
+		},
+		{
+			name: "pending events with webhook failure",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				events := []models.Event{
+					{ID: "event-1", Type: "test.event", Source: "test-service", Status: models.StatusPending},
+				}
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 1).Return(nil)
+			},
+			expectedFailed: 1,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(([]models.Event)(nil), assert.AnError)
+			},
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil).Maybe()
+			tt.mockSetup(mockStore)
+
+			cfg := &config.Config{
+				Publish: config.PublishConfig{
+					BatchSize:  5,
+					WebhookURL: "http://localhost:3000/webhook",
+				},
+			}
+
+			mockGates := &MockSimulationGates{}
+			mockGates.On("ShouldDisablePublishing").Return(false)
+			mockGates.On("ShouldSimulateWebhookFailures").Return(false)
+			mockGates.On("ShouldSimulateNetworkDelays").Return(false)
+			mockGates.On("ShouldUsePartialFailureMode").Return(false)
+			mockGates.On("CheckCircuitBreaker").Return(false)
+			mockGates.On("RecordCircuitBreakerFailure").Return()
+			mockGates.On("RecordCircuitBreakerSuccess").Return()
+			expectAttempts(mockStore)
+			h := New(mockStore, cfg, mockGates)
+
+			response, err := h.PublishPending(context.Background(), 5)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, response)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedPublished, response.Published)
+				assert.Equal(t, tt.expectedFailed, response.Failed)
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PublishPending_RetryScheduling(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryCount int
+		mockSetup  func(*MockOutboxStore)
+	}{
+		{
+			name:       "first failure schedules a retry",
+			retryCount: 0,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 1, mock.MatchedBy(func(next time.Time) bool {
+					delay := time.Until(next)
+					return delay > 0 && delay <= time.Second
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "last allowed retry is still scheduled",
+			retryCount: 2,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 3, mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name:       "exhausted retries move the event to dead letters",
+			retryCount: 3,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("MoveToDeadLetter", mock.AnythingOfType("string"), "event-1", mock.AnythingOfType("string"), 4).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil).Maybe()
+			events := []models.Event{
+				{ID: "event-1", Type: "test.event", Source: "test-service", Status: models.StatusRetrying, RetryCount: tt.retryCount},
+			}
+			mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+			tt.mockSetup(mockStore)
+
+			cfg := &config.Config{
+				Publish: config.PublishConfig{
+					BatchSize:     5,
+					RetryAttempts: 3,
+					RetryDelay:    "1s",
+					MaxRetryDelay: "30s",
+					WebhookURL:    "http://localhost:3000/webhook",
+				},
+			}
+
+			mockGates := &MockSimulationGates{}
+			mockGates.On("ShouldDisablePublishing").Return(false)
+			mockGates.On("ShouldSimulateWebhookFailures").Return(true)
+			mockGates.On("ShouldSimulateNetworkDelays").Return(false)
+			mockGates.On("ShouldUsePartialFailureMode").Return(false)
+			mockGates.On("CheckCircuitBreaker").Return(false)
+			mockGates.On("RecordCircuitBreakerFailure").Return()
+			expectAttempts(mockStore)
+			h := New(mockStore, cfg, mockGates)
+
+			response, err := h.PublishPending(context.Background(), 5)
+			require.NoError(t, err)
+			assert.Equal(t, 1, response.Failed)
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PublishPending_CircuitBreaker(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	events := []models.Event{
+		{ID: "event-1", Type: "test.event", Source: "test-service", Status: models.StatusPending},
+		{ID: "event-2", Type: "test.event", Source: "test-service", Status: models.StatusPending},
+		{ID: "event-3", Type: "test.event", Source: "test-service", Status: models.StatusRetrying, RetryCount: 1},
+	}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+	mockStore.On("ClaimPendingEvents", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Duration"), mock.AnythingOfType("time.Duration")).Return(events, nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-1", "webhook returned status 503", 1, mock.AnythingOfType("time.Time")).Return(nil)
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-2", "webhook returned status 503", 1, mock.AnythingOfType("time.Time")).Return(nil)
+	// The open circuit defers the third event without spending a retry
+	mockStore.On("ScheduleRetry", mock.AnythingOfType("string"), "event-3", mock.AnythingOfType("string"), 1, mock.MatchedBy(func(next time.Time) bool {
+		delay := time.Until(next)
+		return delay > 0 && delay <= time.Minute
+	})).Return(nil)
+
+	cfg := &config.Config{
+		Publish: config.PublishConfig{
+			BatchSize:     5,
+			RetryAttempts: 3,
+			WebhookURL:    server.URL,
+		},
+		Circuit: config.CircuitConfig{
+			FailureThreshold: 2,
+			Interval:         "1m",
+			Timeout:          "1m",
+		},
+	}
+
+	mockGates := &MockSimulationGates{}
+	mockGates.On("ShouldDisablePublishing").Return(false)
+	mockGates.On("ShouldSimulateWebhookFailures").Return(false)
+	mockGates.On("ShouldSimulateNetworkDelays").Return(false)
+	mockGates.On("ShouldUsePartialFailureMode").Return(false)
+	mockGates.On("CheckCircuitBreaker").Return(false)
+	mockGates.On("RecordCircuitBreakerFailure").Return()
+	expectAttempts(mockStore)
+	h := New(mockStore, cfg, mockGates)
+
+	response, err := h.PublishPending(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.Published)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, 1, response.Deferred)
+	assert.Equal(t, 2, requests)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/circuits", h.GetCircuits)
+
+	req, err := http.NewRequest("GET", "/admin/circuits", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Circuits []circuit.Snapshot `json:"circuits"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Circuits, 1)
+	assert.Equal(t, server.URL, body.Circuits[0].Destination)
+	assert.Equal(t, "OPEN", body.Circuits[0].State)
+	assert.Equal(t, uint64(1), body.Circuits[0].TotalRejections)
+
+	mockStore.AssertExpectations(t)
+}
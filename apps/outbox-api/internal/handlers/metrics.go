@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	observability "github.com/jared-scarr/portfolio-monorepo/packages/observability/handlers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a failed publish, labelling outbox_events_failed_total
+const (
+	failureRetrying     = string(models.StatusRetrying)
+	failureDeadLettered = string(models.ChangeDeadLettered)
+	failureFailed       = string(models.StatusFailed)
+)
+
+var (
+	eventsCreatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_created_total",
+			Help: "Events created through the API",
+		},
+		[]string{"type"},
+	)
+
+	eventsPublishedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Events published to every destination they were routed to",
+		},
+		[]string{"type"},
+	)
+
+	// eventsFailedTotal counts failed publishes by what became of the event:
+	// retrying, dead_lettered or failed
+	eventsFailedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_failed_total",
+			Help: "Attempts to publish an event that failed",
+		},
+		[]string{"type", "outcome"},
+	)
+
+	publishLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbox_event_publish_latency_seconds",
+			Help:    "Time from creating an event to publishing it",
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 10), // 10ms..~44m
+		},
+		[]string{"type"},
+	)
+
+	publishRetries = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbox_event_publish_retries",
+			Help:    "Retries an event needed before it was published",
+			Buckets: []float64{0, 1, 2, 3, 5, 10},
+		},
+		[]string{"type"},
+	)
+)
+
+func init() {
+	observability.MustRegister(
+		eventsCreatedTotal,
+		eventsPublishedTotal,
+		eventsFailedTotal,
+		publishLatency,
+		publishRetries,
+	)
+}
+
+// observePublished records an event published at publishedAt
+func observePublished(event *models.Event, publishedAt time.Time) {
+	eventsPublishedTotal.WithLabelValues(event.Type).Inc()
+	publishLatency.WithLabelValues(event.Type).Observe(publishedAt.Sub(event.CreatedAt).Seconds())
+	publishRetries.WithLabelValues(event.Type).Observe(float64(event.RetryCount))
+}
+
+// observeFailed records a failed publish of event and what became of it
+func observeFailed(event *models.Event, outcome string) {
+	eventsFailedTotal.WithLabelValues(event.Type, outcome).Inc()
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/schema"
+)
+
+// compiledSchema compiles a registered schema once and caches it. The key
+// includes the creation time because a version number can be reused after
+// the latest version is deleted.
+func (h *Handler) compiledSchema(registered *models.EventSchema) (*schema.Schema, error) {
+	key := fmt.Sprintf("%s@%d@%d", registered.EventType, registered.Version, registered.CreatedAt.UnixNano())
+	if cached, ok := h.schemas.Load(key); ok {
+		return cached.(*schema.Schema), nil
+	}
+
+	compiled, err := schema.Compile(registered.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	h.schemas.Store(key, compiled)
+	return compiled, nil
+}
+
+// validateEventData checks an event's data against the latest schema for its
+// type. It returns a non-nil response body when the event must be rejected.
+func (h *Handler) validateEventData(ctx context.Context, req *models.CreateEventRequest) (int, gin.H) {
+	registered, err := h.store.GetSchema(ctx, req.Type, 0)
+	if err != nil {
+		if err.Error() == "schema not found" {
+			if h.cfg.Server.SchemaValidation == config.SchemaValidationStrict {
+				return http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("no schema registered for event type %q", req.Type)}
+			}
+			return 0, nil
+		}
+		return http.StatusInternalServerError, gin.H{"error": err.Error()}
+	}
+
+	compiled, err := h.compiledSchema(registered)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"error": err.Error()}
+	}
+
+	violations, err := compiled.Validate(req.Data)
+	if err != nil {
+		return http.StatusBadRequest, gin.H{"error": err.Error()}
+	}
+	if len(violations) > 0 {
+		return http.StatusUnprocessableEntity, gin.H{
+			"error":          "event data does not match schema",
+			"event_type":     registered.EventType,
+			"schema_version": registered.Version,
+			"violations":     violations,
+		}
+	}
+
+	return 0, nil
+}
+
+// schemaVersionParam parses the :version path parameter
+func schemaVersionParam(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive integer"})
+		return 0, false
+	}
+	return version, true
+}
+
+// RegisterSchema godoc
+// @Summary Register a new JSON Schema version for an event type
+// @Description Events of the type are validated against the latest version
+// @Accept json
+// @Produce json
+// @Param request body models.RegisterSchemaRequest true "Schema"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/schemas [post]
+func (h *Handler) RegisterSchema(c *gin.Context) {
+	var req models.RegisterSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := schema.Compile(req.Schema); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	registered, err := h.store.CreateSchema(c.Request.Context(), req.EventType, req.Schema)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"schema": registered})
+}
+
+// ListSchemas godoc
+// @Summary List the latest schema version of every event type
+// @Produce json
+// @Success 200 {object} models.EventSchemasResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/schemas [get]
+func (h *Handler) ListSchemas(c *gin.Context) {
+	schemas, err := h.store.ListSchemas(c.Request.Context(), "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if schemas == nil {
+		schemas = []models.EventSchema{}
+	}
+
+	c.JSON(http.StatusOK, models.EventSchemasResponse{
+		Schemas: schemas,
+		Total:   len(schemas),
+	})
+}
+
+// ListSchemaVersions godoc
+// @Summary List every schema version of an event type, newest first
+// @Produce json
+// @Success 200 {object} models.EventSchemasResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/schemas/{type} [get]
+func (h *Handler) ListSchemaVersions(c *gin.Context) {
+	schemas, err := h.store.ListSchemas(c.Request.Context(), c.Param("type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(schemas) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schema not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EventSchemasResponse{
+		Schemas: schemas,
+		Total:   len(schemas),
+	})
+}
+
+// GetSchemaVersion godoc
+// @Summary Get one schema version of an event type
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/schemas/{type}/versions/{version} [get]
+func (h *Handler) GetSchemaVersion(c *gin.Context) {
+	version, ok := schemaVersionParam(c)
+	if !ok {
+		return
+	}
+
+	registered, err := h.store.GetSchema(c.Request.Context(), c.Param("type"), version)
+	if err != nil {
+		if err.Error() == "schema not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "schema not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schema": registered})
+}
+
+// DeleteSchema godoc
+// @Summary Delete every schema version of an event type
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/schemas/{type} [delete]
+func (h *Handler) DeleteSchema(c *gin.Context) {
+	h.deleteSchema(c, 0)
+}
+
+// DeleteSchemaVersion godoc
+// @Summary Delete one schema version of an event type
+// @Description Deleting the latest version makes the previous one current
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/schemas/{type}/versions/{version} [delete]
+func (h *Handler) DeleteSchemaVersion(c *gin.Context) {
+	version, ok := schemaVersionParam(c)
+	if !ok {
+		return
+	}
+	h.deleteSchema(c, version)
+}
+
+func (h *Handler) deleteSchema(c *gin.Context, version int) {
+	if err := h.store.DeleteSchema(c.Request.Context(), c.Param("type"), version); err != nil {
+		if err.Error() == "schema not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "schema not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "schema deleted"})
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errSchemaNotFound = errors.New("schema not found")
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["order_id"],
+	"properties": {
+		"order_id": {"type": "string"},
+		"total": {"type": "number", "minimum": 0}
+	}
+}`
+
+func setupSchemaRouter(store *MockOutboxStore, mode string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	cfg := &config.Config{Server: config.ServerConfig{SchemaValidation: mode}}
+	h := New(store, cfg, &MockSimulationGates{})
+
+	router.POST("/api/v1/events", h.CreateEvent)
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/schemas", h.ListSchemas)
+		admin.POST("/schemas", h.RegisterSchema)
+		admin.GET("/schemas/:type", h.ListSchemaVersions)
+		admin.DELETE("/schemas/:type", h.DeleteSchema)
+		admin.GET("/schemas/:type/versions/:version", h.GetSchemaVersion)
+		admin.DELETE("/schemas/:type/versions/:version", h.DeleteSchemaVersion)
+	}
+
+	return router
+}
+
+func TestHandler_Schemas(t *testing.T) {
+	registered := &models.EventSchema{
+		EventType: "order.created",
+		Version:   2,
+		Schema:    json.RawMessage(orderSchema),
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           interface{}
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+		expectedError  string
+	}{
+		{
+			name:   "register schema",
+			method: "POST",
+			url:    "/admin/schemas",
+			body:   models.RegisterSchemaRequest{EventType: "order.created", Schema: json.RawMessage(orderSchema)},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateSchema", "order.created", mock.Anything).Return(registered, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "register invalid schema",
+			method:         "POST",
+			url:            "/admin/schemas",
+			body:           models.RegisterSchemaRequest{EventType: "order.created", Schema: json.RawMessage(`{"type": "invoice"}`)},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid schema",
+		},
+		{
+			name:           "register schema without event type",
+			method:         "POST",
+			url:            "/admin/schemas",
+			body:           map[string]interface{}{"schema": json.RawMessage(orderSchema)},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "list latest schemas",
+			method: "GET",
+			url:    "/admin/schemas",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListSchemas", "").Return([]models.EventSchema{*registered}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"total": float64(1)},
+		},
+		{
+			name:   "list versions of a type",
+			method: "GET",
+			url:    "/admin/schemas/order.created",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListSchemas", "order.created").Return([]models.EventSchema{*registered, {EventType: "order.created", Version: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"total": float64(2)},
+		},
+		{
+			name:   "list versions of an unregistered type",
+			method: "GET",
+			url:    "/admin/schemas/unknown",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListSchemas", "unknown").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "schema not found",
+		},
+		{
+			name:   "get version",
+			method: "GET",
+			url:    "/admin/schemas/order.created/versions/2",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "order.created", 2).Return(registered, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "get invalid version",
+			method:         "GET",
+			url:            "/admin/schemas/order.created/versions/latest",
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "version must be a positive integer",
+		},
+		{
+			name:   "get missing version",
+			method: "GET",
+			url:    "/admin/schemas/order.created/versions/9",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "order.created", 9).Return(nil, errSchemaNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "schema not found",
+		},
+		{
+			name:   "delete every version",
+			method: "DELETE",
+			url:    "/admin/schemas/order.created",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("DeleteSchema", "order.created", 0).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete one version",
+			method: "DELETE",
+			url:    "/admin/schemas/order.created/versions/2",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("DeleteSchema", "order.created", 2).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete missing version",
+			method: "DELETE",
+			url:    "/admin/schemas/order.created/versions/9",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("DeleteSchema", "order.created", 9).Return(errSchemaNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "schema not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			tt.mockSetup(mockStore)
+
+			router := setupSchemaRouter(mockStore, config.SchemaValidationLenient)
+
+			var body *bytes.Buffer
+			if tt.body != nil {
+				jsonBody, err := json.Marshal(tt.body)
+				require.NoError(t, err)
+				body = bytes.NewBuffer(jsonBody)
+			} else {
+				body = bytes.NewBuffer(nil)
+			}
+
+			req, err := http.NewRequest(tt.method, tt.url, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			}
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, response[key], key)
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CreateEvent_SchemaValidation(t *testing.T) {
+	registered := &models.EventSchema{
+		EventType: "order.created",
+		Version:   2,
+		Schema:    json.RawMessage(orderSchema),
+		CreatedAt: time.Now(),
+	}
+	event := &models.Event{ID: "event-1", Type: "order.created", Source: "order-service", Status: models.StatusPending}
+
+	tests := []struct {
+		name               string
+		mode               string
+		eventType          string
+		data               string
+		mockSetup          func(*MockOutboxStore)
+		expectedStatus     int
+		expectedError      string
+		expectedViolations []interface{}
+	}{
+		{
+			name:      "data matches schema",
+			mode:      config.SchemaValidationLenient,
+			eventType: "order.created",
+			data:      `{"order_id": "123", "total": 10}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "order.created", 0).Return(registered, nil)
+				mockStore.On("CreateEvent", mock.IsType(&models.CreateEventRequest{})).Return(event, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "data violates schema",
+			mode:      config.SchemaValidationLenient,
+			eventType: "order.created",
+			data:      `{"order_id": 123, "total": -1}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "order.created", 0).Return(registered, nil)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "event data does not match schema",
+			expectedViolations: []interface{}{
+				map[string]interface{}{"pointer": "/order_id", "message": "got number, want string"},
+				map[string]interface{}{"pointer": "/total", "message": "minimum: got -1, want 0"},
+			},
+		},
+		{
+			name:      "unregistered type in lenient mode",
+			mode:      config.SchemaValidationLenient,
+			eventType: "user.signed_up",
+			data:      `{"anything": true}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "user.signed_up", 0).Return(nil, errSchemaNotFound)
+				mockStore.On("CreateEvent", mock.IsType(&models.CreateEventRequest{})).Return(event, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "unregistered type in strict mode",
+			mode:      config.SchemaValidationStrict,
+			eventType: "user.signed_up",
+			data:      `{"anything": true}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "user.signed_up", 0).Return(nil, errSchemaNotFound)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  `no schema registered for event type "user.signed_up"`,
+		},
+		{
+			name:      "schema lookup fails",
+			mode:      config.SchemaValidationLenient,
+			eventType: "order.created",
+			data:      `{"order_id": "123"}`,
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSchema", "order.created", 0).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			tt.mockSetup(mockStore)
+
+			router := setupSchemaRouter(mockStore, tt.mode)
+
+			jsonBody, err := json.Marshal(models.CreateEventRequest{
+				Type:   tt.eventType,
+				Source: "order-service",
+				Data:   json.RawMessage(tt.data),
+			})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			}
+			if tt.expectedViolations != nil {
+				assert.Equal(t, float64(2), response["schema_version"])
+				assert.ElementsMatch(t, tt.expectedViolations, response["violations"])
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
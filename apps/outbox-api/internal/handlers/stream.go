@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+const (
+	// lastEventIDHeader is sent by EventSource clients when they reconnect
+	lastEventIDHeader = "Last-Event-ID"
+
+	// streamReplayLimit caps how many logged changes one connection replays.
+	// A client further behind is disconnected after the replay and catches
+	// up over several reconnects.
+	streamReplayLimit = 1000
+
+	// streamReplayOverlap is how many change IDs before Last-Event-ID are
+	// replayed again. A change's ID is taken when it is written but the
+	// change is only logged when its transaction commits, so it may be logged
+	// after a change with a higher ID. Clients skip changes they have seen.
+	streamReplayOverlap = 100
+
+	// streamRetry is the reconnect delay suggested to clients, in milliseconds
+	streamRetry = 1000
+)
+
+// StreamEvents godoc
+// @Summary Stream event changes as Server-Sent Events
+// @Description Pushes every create, publish, failure, retry, requeue, expiry, dead letter and delete of an event, from any replica
+// @Description Each SSE event is named after the action and carries the change as JSON. Reconnecting with Last-Event-ID (or last_event_id) replays the changes missed since, starting 100 IDs early; clients should skip change IDs they have already seen.
+// @Produce text/event-stream
+// @Param status query string false "Only changes leaving events in this status"
+// @Param type query string false "Only changes to events of this type; a trailing * matches by prefix"
+// @Param Last-Event-ID header string false "Replay changes from shortly before this ID first"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /api/v1/events/stream [get]
+func (h *Handler) StreamEvents(c *gin.Context) {
+	if h.changes == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event stream is disabled"})
+		return
+	}
+
+	filter := models.ChangeFilter{Type: c.Query("type")}
+	if s := c.Query("status"); s != "" {
+		status := models.EventStatus(s)
+		if !status.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid status: %s", s)})
+			return
+		}
+		filter.Status = &status
+	}
+
+	lastID, resume, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Subscribe before reading the log so that no change falls between the
+	// replay and the live stream; changes seen in both are sent once
+	sub := h.changes.Subscribe(filter)
+	defer h.changes.Unsubscribe(sub)
+
+	var replay []models.EventChange
+	if resume {
+		replay, err = h.store.ListEventChanges(c.Request.Context(), &filter, max(lastID-streamReplayOverlap, 0), streamReplayLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Keep nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// The stream outlives the server's write timeout, which is meant for
+	// ordinary responses
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry); err != nil {
+		return
+	}
+
+	replayed := make(map[int64]bool, len(replay))
+	for i := range replay {
+		if err := writeChange(c.Writer, &replay[i]); err != nil {
+			return
+		}
+		replayed[replay[i].ID] = true
+	}
+	c.Writer.Flush()
+
+	if len(replay) == streamReplayLimit {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.cfg.Stream.HeartbeatInterval())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case change, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind, or changes may have been missed:
+				// the client reconnects and resumes from the log
+				return
+			}
+			if replayed[change.ID] {
+				continue
+			}
+			if err := writeChange(c.Writer, &change); err != nil {
+				log.Printf("Event stream: failed to write change %d: %v", change.ID, err)
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// lastEventID returns the change ID a reconnecting client saw last, and
+// whether it sent one
+func lastEventID(c *gin.Context) (int64, bool, error) {
+	value := c.GetHeader(lastEventIDHeader)
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("Last-Event-ID must be a non-negative integer")
+	}
+	return id, true, nil
+}
+
+// writeChange writes change as an SSE event named after its action
+func writeChange(w io.Writer, change *models.EventChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.ID, change.Action, data)
+	return err
+}
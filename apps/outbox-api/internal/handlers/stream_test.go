@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStreamRouter(store *MockOutboxStore, hub *stream.Hub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var opts []Option
+	if hub != nil {
+		opts = append(opts, WithChangeStream(hub))
+	}
+	h := New(store, &config.Config{}, &MockSimulationGates{}, opts...)
+	router.GET("/api/v1/events/stream", h.StreamEvents)
+
+	return router
+}
+
+// serveStream runs a stream request in the background. The returned
+// function waits for the handler to return and yields the response.
+func serveStream(t *testing.T, router *gin.Engine, req *http.Request) func() *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(w, req)
+	}()
+
+	return func() *httptest.ResponseRecorder {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stream did not end")
+		}
+		return w
+	}
+}
+
+func TestHandler_StreamEvents_Rejected(t *testing.T) {
+	tests := []struct {
+		name           string
+		disabled       bool
+		url            string
+		lastEventID    string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "stream disabled",
+			disabled:       true,
+			url:            "/api/v1/events/stream",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedError:  "event stream is disabled",
+		},
+		{
+			name:           "invalid status",
+			url:            "/api/v1/events/stream?status=sent",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid status: sent",
+		},
+		{
+			name:           "invalid Last-Event-ID",
+			url:            "/api/v1/events/stream",
+			lastEventID:    "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Last-Event-ID must be a non-negative integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := stream.NewHub(10)
+			if tt.disabled {
+				hub = nil
+			}
+			router := setupStreamRouter(new(MockOutboxStore), hub)
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(lastEventIDHeader, tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedError)
+		})
+	}
+}
+
+func TestHandler_StreamEvents_ReplaysThenStreams(t *testing.T) {
+	hub := stream.NewHub(10)
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ListEventChanges", mock.MatchedBy(func(filter *models.ChangeFilter) bool {
+		return filter.Type == "order.*" && filter.Status == nil
+	}), int64(4), streamReplayLimit).Return([]models.EventChange{
+		{ID: 5, EventID: "event-1", Action: models.ChangeCreated, Type: "order.created", Status: models.StatusPending},
+		{ID: 6, EventID: "event-1", Action: models.ChangePublished, Type: "order.created", Status: models.StatusPublished},
+	}, nil)
+	router := setupStreamRouter(mockStore, hub)
+
+	req := httptest.NewRequest("GET", "/api/v1/events/stream?type=order.*", nil)
+	// The replay starts streamReplayOverlap IDs early, in case changes
+	// before Last-Event-ID were logged after it
+	req.Header.Set(lastEventIDHeader, "104")
+	wait := serveStream(t, router, req)
+
+	require.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, time.Millisecond)
+	// Change 6 was also replayed; user.created does not match the filter
+	hub.Publish(models.EventChange{ID: 6, EventID: "event-1", Action: models.ChangePublished, Type: "order.created", Status: models.StatusPublished})
+	hub.Publish(models.EventChange{ID: 7, EventID: "event-2", Action: models.ChangeCreated, Type: "user.created", Status: models.StatusPending})
+	hub.Publish(models.EventChange{ID: 8, EventID: "event-3", Action: models.ChangeFailed, Type: "order.created", Status: models.StatusFailed, RetryCount: 3})
+	// Dropping the subscriber ends the stream once buffered changes are sent
+	hub.Reset()
+
+	w := wait()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "retry: 1000\n\n"))
+	assert.Equal(t, 1, strings.Count(body, "id: 6\n"))
+	assert.NotContains(t, body, "id: 7\n")
+	assert.Contains(t, body, "id: 5\nevent: created\ndata: {\"id\":5,\"event_id\":\"event-1\",\"action\":\"created\"")
+	assert.Contains(t, body, "id: 8\nevent: failed\ndata: {\"id\":8,\"event_id\":\"event-3\",\"action\":\"failed\",\"type\":\"order.created\",\"status\":\"failed\",\"retry_count\":3")
+	assert.Less(t, strings.Index(body, "id: 6\n"), strings.Index(body, "id: 8\n"))
+	mockStore.AssertExpectations(t)
+}
+
+func TestHandler_StreamEvents_StatusFilter(t *testing.T) {
+	hub := stream.NewHub(10)
+	router := setupStreamRouter(new(MockOutboxStore), hub)
+
+	wait := serveStream(t, router, httptest.NewRequest("GET", "/api/v1/events/stream?status=published", nil))
+
+	require.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, time.Millisecond)
+	hub.Publish(models.EventChange{ID: 1, Action: models.ChangeRetrying, Type: "order.created", Status: models.StatusRetrying})
+	hub.Publish(models.EventChange{ID: 2, Action: models.ChangePublished, Type: "order.created", Status: models.StatusPublished})
+	hub.Reset()
+
+	body := wait().Body.String()
+	assert.NotContains(t, body, "id: 1\n")
+	assert.Contains(t, body, "id: 2\nevent: published\n")
+}
+
+func TestHandler_StreamEvents_LongReplayReconnects(t *testing.T) {
+	hub := stream.NewHub(10)
+	changes := make([]models.EventChange, streamReplayLimit)
+	for i := range changes {
+		changes[i] = models.EventChange{ID: int64(i + 1), Action: models.ChangeCreated, Status: models.StatusPending}
+	}
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ListEventChanges", mock.Anything, int64(0), streamReplayLimit).Return(changes, nil)
+	router := setupStreamRouter(mockStore, hub)
+
+	// The stream ends after a full replay so the client resumes from there
+	w := serveStream(t, router, httptest.NewRequest("GET", "/api/v1/events/stream?last_event_id=0", nil))()
+
+	assert.Equal(t, streamReplayLimit, strings.Count(w.Body.String(), "event: created\n"))
+	assert.Equal(t, 0, hub.Subscribers())
+}
+
+func TestHandler_StreamEvents_ReplayError(t *testing.T) {
+	hub := stream.NewHub(10)
+	mockStore := new(MockOutboxStore)
+	mockStore.On("ListEventChanges", mock.Anything, int64(0), streamReplayLimit).Return([]models.EventChange(nil), assert.AnError)
+	router := setupStreamRouter(mockStore, hub)
+
+	req := httptest.NewRequest("GET", "/api/v1/events/stream", nil)
+	req.Header.Set(lastEventIDHeader, "9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 0, hub.Subscribers())
+}
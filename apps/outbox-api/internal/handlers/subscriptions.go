@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/webhook"
+)
+
+// defaultSecretGracePeriod is how long a rotated-out secret keeps signing deliveries
+const defaultSecretGracePeriod = 24 * time.Hour
+
+// validateSubscriptionRequest rejects empty filter patterns and wildcards
+// anywhere other than the end of a pattern
+func validateSubscriptionRequest(req *models.SubscriptionRequest) string {
+	for _, patterns := range [][]string{req.EventTypes, req.Sources} {
+		for _, pattern := range patterns {
+			if pattern == "" {
+				return "filter patterns must not be empty"
+			}
+			if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+				return "wildcard is only supported at the end of a pattern: " + pattern
+			}
+		}
+	}
+
+	return ""
+}
+
+// CreateSubscription godoc
+// @Summary Create a webhook subscription
+// @Accept json
+// @Produce json
+// @Param request body models.SubscriptionRequest true "Subscription"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions [post]
+func (h *Handler) CreateSubscription(c *gin.Context) {
+	var req models.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := validateSubscriptionRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if req.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		req.Secret = secret
+	}
+
+	subscription, err := h.store.CreateSubscription(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The secret is only ever returned here and when it is rotated
+	c.JSON(http.StatusCreated, gin.H{
+		"subscription": subscription,
+		"secret":       subscription.Secret,
+	})
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Produce json
+// @Success 200 {object} models.SubscriptionsResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions [get]
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.store.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SubscriptionsResponse{
+		Subscriptions: subscriptions,
+		Total:         len(subscriptions),
+	})
+}
+
+// GetSubscription godoc
+// @Summary Get a webhook subscription by ID
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions/{id} [get]
+func (h *Handler) GetSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	subscription, err := h.store.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": subscription})
+}
+
+// UpdateSubscription godoc
+// @Summary Replace a webhook subscription's URL, filters and active flag
+// @Accept json
+// @Produce json
+// @Param request body models.SubscriptionRequest true "Subscription"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions/{id} [put]
+func (h *Handler) UpdateSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := validateSubscriptionRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	previous, err := h.store.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.store.UpdateSubscription(c.Request.Context(), id, &req)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if subscription.URL != previous.URL {
+		h.forgetCircuit(c.Request.Context(), previous.URL)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": subscription})
+}
+
+// RotateSubscriptionSecret godoc
+// @Summary Generate a new signing secret for a webhook subscription
+// @Description The previous secret keeps signing deliveries for the grace period (default 24h)
+// @Accept json
+// @Produce json
+// @Param request body models.RotateSecretRequest false "Grace period for the previous secret"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions/{id}/rotate-secret [post]
+func (h *Handler) RotateSubscriptionSecret(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RotateSecretRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	gracePeriod := defaultSecretGracePeriod
+	if req.GracePeriod != "" {
+		parsed, err := time.ParseDuration(req.GracePeriod)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "grace_period must be a non-negative duration such as 24h"})
+			return
+		}
+		gracePeriod = parsed
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.store.RotateSubscriptionSecret(c.Request.Context(), id, secret, time.Now().Add(gracePeriod))
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscription": subscription,
+		"secret":       subscription.Secret,
+	})
+}
+
+// DeleteSubscription godoc
+// @Summary Delete a webhook subscription
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions/{id} [delete]
+func (h *Handler) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	subscription, err := h.store.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.DeleteSubscription(c.Request.Context(), id); err != nil {
+		if err.Error() == "subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.forgetCircuit(c.Request.Context(), subscription.URL)
+
+	c.JSON(http.StatusOK, gin.H{"message": "subscription deleted"})
+}
+
+// GetEventDeliveries godoc
+// @Summary Get the per-subscription delivery status of an event
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/events/{id}/deliveries [get]
+func (h *Handler) GetEventDeliveries(c *gin.Context) {
+	id := c.Param("id")
+
+	deliveries, err := h.store.GetDeliveries(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []models.Delivery{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":   id,
+		"deliveries": deliveries,
+	})
+}
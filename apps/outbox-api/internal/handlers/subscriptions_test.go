@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errSubscriptionNotFound = errors.New("subscription not found")
+
+func setupSubscriptionRouter(store *MockOutboxStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	h := New(store, &config.Config{}, &MockSimulationGates{})
+
+	router.GET("/api/v1/events/:id/deliveries", h.GetEventDeliveries)
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/subscriptions", h.ListSubscriptions)
+		admin.POST("/subscriptions", h.CreateSubscription)
+		admin.GET("/subscriptions/:id", h.GetSubscription)
+		admin.PUT("/subscriptions/:id", h.UpdateSubscription)
+		admin.POST("/subscriptions/:id/rotate-secret", h.RotateSubscriptionSecret)
+		admin.DELETE("/subscriptions/:id", h.DeleteSubscription)
+	}
+
+	return router
+}
+
+func TestHandler_Subscriptions(t *testing.T) {
+	subscription := &models.Subscription{
+		ID:         "sub-1",
+		Name:       "billing",
+		URL:        "https://billing.example.com/hooks",
+		EventTypes: []string{"order.*"},
+		Active:     true,
+		Secret:     "whsec_test",
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           interface{}
+		mockSetup      func(*MockOutboxStore)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+		expectedError  string
+	}{
+		{
+			name:   "create subscription",
+			method: "POST",
+			url:    "/admin/subscriptions",
+			body: models.SubscriptionRequest{
+				Name:       "billing",
+				URL:        "https://billing.example.com/hooks",
+				EventTypes: []string{"order.*"},
+			},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateSubscription", mock.MatchedBy(func(req *models.SubscriptionRequest) bool {
+					return strings.HasPrefix(req.Secret, "whsec_")
+				})).Return(subscription, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   map[string]interface{}{"secret": "whsec_test"},
+		},
+		{
+			name:   "create subscription with caller-provided secret",
+			method: "POST",
+			url:    "/admin/subscriptions",
+			body: models.SubscriptionRequest{
+				Name:   "billing",
+				URL:    "https://billing.example.com/hooks",
+				Secret: "shared-secret",
+			},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("CreateSubscription", mock.MatchedBy(func(req *models.SubscriptionRequest) bool {
+					return req.Secret == "shared-secret"
+				})).Return(subscription, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create subscription without URL",
+			method:         "POST",
+			url:            "/admin/subscriptions",
+			body:           map[string]interface{}{"name": "billing"},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "create subscription with invalid URL",
+			method:         "POST",
+			url:            "/admin/subscriptions",
+			body:           models.SubscriptionRequest{Name: "billing", URL: "not a url"},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create subscription with mid-pattern wildcard",
+			method: "POST",
+			url:    "/admin/subscriptions",
+			body: models.SubscriptionRequest{
+				Name:       "billing",
+				URL:        "https://billing.example.com/hooks",
+				EventTypes: []string{"order.*.created"},
+			},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "wildcard is only supported at the end of a pattern",
+		},
+		{
+			name:   "list subscriptions",
+			method: "GET",
+			url:    "/admin/subscriptions",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("ListSubscriptions").Return([]models.Subscription{*subscription}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"total": float64(1)},
+		},
+		{
+			name:   "get subscription",
+			method: "GET",
+			url:    "/admin/subscriptions/sub-1",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSubscription", "sub-1").Return(subscription, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get missing subscription",
+			method: "GET",
+			url:    "/admin/subscriptions/missing",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSubscription", "missing").Return(nil, errSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "subscription not found",
+		},
+		{
+			name:   "update subscription",
+			method: "PUT",
+			url:    "/admin/subscriptions/sub-1",
+			body:   models.SubscriptionRequest{Name: "billing", URL: "https://billing.example.com/v2"},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSubscription", "sub-1").Return(subscription, nil)
+				mockStore.On("UpdateSubscription", "sub-1", mock.AnythingOfType("*models.SubscriptionRequest")).Return(subscription, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update missing subscription",
+			method: "PUT",
+			url:    "/admin/subscriptions/missing",
+			body:   models.SubscriptionRequest{Name: "billing", URL: "https://billing.example.com/v2"},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSubscription", "missing").Return(nil, errSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "subscription not found",
+		},
+		{
+			name:   "rotate secret with default grace period",
+			method: "POST",
+			url:    "/admin/subscriptions/sub-1/rotate-secret",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RotateSubscriptionSecret", "sub-1", mock.MatchedBy(func(secret string) bool {
+					return strings.HasPrefix(secret, "whsec_")
+				}), mock.MatchedBy(func(expiresAt time.Time) bool {
+					remaining := time.Until(expiresAt)
+					return remaining > 23*time.Hour && remaining <= 24*time.Hour
+				})).Return(subscription, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"secret": "whsec_test"},
+		},
+		{
+			name:   "rotate secret with custom grace period",
+			method: "POST",
+			url:    "/admin/subscriptions/sub-1/rotate-secret",
+			body:   models.RotateSecretRequest{GracePeriod: "1h"},
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RotateSubscriptionSecret", "sub-1", mock.AnythingOfType("string"), mock.MatchedBy(func(expiresAt time.Time) bool {
+					remaining := time.Until(expiresAt)
+					return remaining > 59*time.Minute && remaining <= time.Hour
+				})).Return(subscription, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rotate secret with invalid grace period",
+			method:         "POST",
+			url:            "/admin/subscriptions/sub-1/rotate-secret",
+			body:           models.RotateSecretRequest{GracePeriod: "tomorrow"},
+			mockSetup:      func(mockStore *MockOutboxStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "grace_period must be a non-negative duration",
+		},
+		{
+			name:   "rotate secret of missing subscription",
+			method: "POST",
+			url:    "/admin/subscriptions/missing/rotate-secret",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("RotateSubscriptionSecret", "missing", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil, errSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "subscription not found",
+		},
+		{
+			name:   "delete subscription",
+			method: "DELETE",
+			url:    "/admin/subscriptions/sub-1",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSubscription", "sub-1").Return(subscription, nil)
+				mockStore.On("DeleteSubscription", "sub-1").Return(nil)
+				mockStore.On("ListSubscriptions").Return([]models.Subscription{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete missing subscription",
+			method: "DELETE",
+			url:    "/admin/subscriptions/missing",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetSubscription", "missing").Return(nil, errSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "subscription not found",
+		},
+		{
+			name:   "event deliveries",
+			method: "GET",
+			url:    "/api/v1/events/event-1/deliveries",
+			mockSetup: func(mockStore *MockOutboxStore) {
+				mockStore.On("GetDeliveries", "event-1").Return([]models.Delivery{
+					{EventID: "event-1", SubscriptionID: "sub-1", Status: models.DeliveryDelivered, Attempts: 1},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"event_id": "event-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOutboxStore)
+			tt.mockSetup(mockStore)
+
+			router := setupSubscriptionRouter(mockStore)
+
+			var body *bytes.Buffer
+			if tt.body != nil {
+				jsonBody, err := json.Marshal(tt.body)
+				require.NoError(t, err)
+				body = bytes.NewBuffer(jsonBody)
+			} else {
+				body = bytes.NewBuffer(nil)
+			}
+
+			req, err := http.NewRequest(tt.method, tt.url, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			}
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, response[key], key)
+			}
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_SubscriptionChangesForgetCircuits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	billing := &models.Subscription{ID: "sub-1", Name: "billing", URL: "https://billing.example.com/hooks", Active: true}
+	moved := &models.Subscription{ID: "sub-1", Name: "billing", URL: "https://billing.example.com/v2", Active: true}
+	shared := models.Subscription{ID: "sub-2", Name: "audit", URL: "https://audit.example.com/hooks", Active: true}
+
+	mockStore := new(MockOutboxStore)
+	mockStore.On("GetSubscription", "sub-1").Return(billing, nil).Once()
+	mockStore.On("UpdateSubscription", "sub-1", mock.AnythingOfType("*models.SubscriptionRequest")).Return(moved, nil)
+	mockStore.On("GetSubscription", "sub-1").Return(moved, nil).Once()
+	mockStore.On("DeleteSubscription", "sub-1").Return(nil)
+	mockStore.On("GetSubscription", "sub-2").Return(&shared, nil)
+	mockStore.On("DeleteSubscription", "sub-2").Return(nil)
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{shared}, nil).Twice()
+	mockStore.On("ListSubscriptions").Return([]models.Subscription{{ID: "sub-3", URL: shared.URL}}, nil)
+
+	h := New(mockStore, &config.Config{}, &MockSimulationGates{})
+	router := gin.New()
+	router.PUT("/admin/subscriptions/:id", h.UpdateSubscription)
+	router.DELETE("/admin/subscriptions/:id", h.DeleteSubscription)
+
+	for _, url := range []string{billing.URL, moved.URL, shared.URL} {
+		h.circuits.Get(url)
+	}
+	destinations := func() []string {
+		var destinations []string
+		for _, snapshot := range h.circuits.Snapshots() {
+			destinations = append(destinations, snapshot.Destination)
+		}
+		return destinations
+	}
+
+	body, err := json.Marshal(models.SubscriptionRequest{Name: "billing", URL: moved.URL})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/subscriptions/sub-1", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{shared.URL, moved.URL}, destinations(), "the old URL's breaker is dropped")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/subscriptions/sub-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{shared.URL}, destinations(), "the deleted subscription's breaker is dropped")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/subscriptions/sub-2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{shared.URL}, destinations(), "a URL another subscription still uses keeps its breaker")
+
+	mockStore.AssertExpectations(t)
+}
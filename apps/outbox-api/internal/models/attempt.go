@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Error classes group failed delivery attempts by cause
+const (
+	ErrorClassTimeout     = "timeout"
+	ErrorClassConnection  = "connection"
+	ErrorClassClientError = "http_4xx"
+	ErrorClassServerError = "http_5xx"
+	ErrorClassHTTPStatus  = "http_status"
+	ErrorClassPublish     = "publish"
+)
+
+// DeliveryAttempt records one attempt to deliver an event to a destination:
+// a subscription's webhook, or the default publisher when SubscriptionID is
+// empty. StatusCode and ResponseBody are only set for webhooks.
+type DeliveryAttempt struct {
+	ID             int64     `json:"id" db:"id"`
+	EventID        string    `json:"event_id" db:"event_id"`
+	SubscriptionID string    `json:"subscription_id,omitempty" db:"subscription_id"`
+	Destination    string    `json:"destination" db:"destination"`
+	AttemptedAt    time.Time `json:"attempted_at" db:"attempted_at"`
+	DurationMs     int64     `json:"duration_ms" db:"duration_ms"`
+	StatusCode     int       `json:"status_code,omitempty" db:"status_code"`
+	ResponseBody   string    `json:"response_body,omitempty" db:"response_body"`
+	Error          string    `json:"error,omitempty" db:"error"`
+	ErrorClass     string    `json:"error_class,omitempty" db:"error_class"`
+}
+
+// Succeeded reports whether the destination acknowledged the event
+func (a *DeliveryAttempt) Succeeded() bool {
+	return a.Error == ""
+}
+
+// DeliveryAttemptsResponse represents the response for listing an event's
+// delivery attempts
+type DeliveryAttemptsResponse struct {
+	EventID  string            `json:"event_id"`
+	Attempts []DeliveryAttempt `json:"attempts"`
+	Total    int               `json:"total"`
+}
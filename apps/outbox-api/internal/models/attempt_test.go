@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryAttempt_JSON(t *testing.T) {
+	attempt := DeliveryAttempt{
+		ID:          1,
+		EventID:     "event-1",
+		Destination: "kafka://localhost:9092/events",
+		AttemptedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		DurationMs:  12,
+	}
+
+	jsonData, err := json.Marshal(attempt)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"id": 1,
+		"event_id": "event-1",
+		"destination": "kafka://localhost:9092/events",
+		"attempted_at": "2024-01-02T03:04:05Z",
+		"duration_ms": 12
+	}`, string(jsonData))
+}
+
+func TestDeliveryAttempt_Succeeded(t *testing.T) {
+	assert.True(t, (&DeliveryAttempt{StatusCode: 200}).Succeeded())
+	assert.False(t, (&DeliveryAttempt{StatusCode: 503, Error: "webhook returned status 503", ErrorClass: ErrorClassServerError}).Succeeded())
+}
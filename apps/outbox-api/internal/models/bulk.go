@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BulkAction is an operation applied to every event a bulk job selects
+type BulkAction string
+
+const (
+	// BulkActionRetry publishes failed events immediately, like /events/:id/retry
+	BulkActionRetry BulkAction = "retry"
+	// BulkActionDelete deletes events
+	BulkActionDelete BulkAction = "delete"
+	// BulkActionMarkFailed stops pending and retrying events from being published
+	BulkActionMarkFailed BulkAction = "mark-failed"
+	// BulkActionRequeue resets events to pending with a fresh retry budget, no
+	// expiry and no delivery status, so every subscriber receives them again
+	BulkActionRequeue BulkAction = "requeue-as-pending"
+)
+
+// EligibleStatuses returns the event statuses the action applies to, or nil
+// for an unknown action. Selected events in any other status are skipped.
+func (a BulkAction) EligibleStatuses() []EventStatus {
+	switch a {
+	case BulkActionRetry:
+		return []EventStatus{StatusFailed}
+	case BulkActionDelete:
+		return []EventStatus{StatusPending, StatusPublished, StatusFailed, StatusRetrying, StatusExpired}
+	case BulkActionMarkFailed:
+		return []EventStatus{StatusPending, StatusRetrying}
+	case BulkActionRequeue:
+		return []EventStatus{StatusPublished, StatusFailed, StatusRetrying, StatusExpired}
+	}
+	return nil
+}
+
+// BulkFilter selects events by their attributes
+type BulkFilter struct {
+	Status        *EventStatus `json:"status,omitempty"`
+	Type          string       `json:"type,omitempty"`
+	Source        string       `json:"source,omitempty"`
+	CreatedAfter  *time.Time   `json:"created_after,omitempty"`
+	CreatedBefore *time.Time   `json:"created_before,omitempty"`
+}
+
+// BulkRequest applies an action to the events named by EventIDs or matched by
+// Filter; exactly one of the two must be given. DryRun only counts them.
+type BulkRequest struct {
+	Action   BulkAction  `json:"action" binding:"required"`
+	EventIDs []string    `json:"event_ids,omitempty"`
+	Filter   *BulkFilter `json:"filter,omitempty"`
+	DryRun   bool        `json:"dry_run,omitempty"`
+}
+
+// Validate checks that the request names a known action and one selection
+func (r *BulkRequest) Validate() error {
+	if r.Action.EligibleStatuses() == nil {
+		return fmt.Errorf("unknown action: %s", r.Action)
+	}
+	if len(r.EventIDs) > 0 && r.Filter != nil {
+		return fmt.Errorf("event_ids and filter cannot be combined")
+	}
+	if len(r.EventIDs) == 0 && r.Filter == nil {
+		return fmt.Errorf("event_ids or filter is required")
+	}
+	if r.Filter != nil && r.Filter.Status != nil && !r.Filter.Status.Valid() {
+		return fmt.Errorf("invalid status: %s", *r.Filter.Status)
+	}
+	return nil
+}
+
+// Query returns the events the request's action applies to
+func (r *BulkRequest) Query() *EventQuery {
+	query := &EventQuery{
+		IDs:      r.EventIDs,
+		Statuses: r.Action.EligibleStatuses(),
+	}
+	if r.Filter != nil {
+		query.Status = r.Filter.Status
+		query.Type = r.Filter.Type
+		query.Source = r.Filter.Source
+		query.CreatedAfter = r.Filter.CreatedAfter
+		query.CreatedBefore = r.Filter.CreatedBefore
+	}
+	return query
+}
+
+// BulkJobStatus is the state of a bulk job
+type BulkJobStatus string
+
+const (
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed"
+	BulkJobFailed    BulkJobStatus = "failed"
+)
+
+// BulkJob tracks the progress of a bulk action. Matched is counted when the
+// job starts; events that change status before the job reaches them are
+// skipped, so Succeeded and Failed may add up to less.
+type BulkJob struct {
+	ID          string        `json:"id" db:"id"`
+	Action      BulkAction    `json:"action" db:"action"`
+	EventIDs    []string      `json:"event_ids,omitempty" db:"event_ids"`
+	Filter      *BulkFilter   `json:"filter,omitempty" db:"filter"`
+	Status      BulkJobStatus `json:"status" db:"status"`
+	Matched     int           `json:"matched" db:"matched"`
+	Processed   int           `json:"processed" db:"processed"`
+	Succeeded   int           `json:"succeeded" db:"succeeded"`
+	Failed      int           `json:"failed" db:"failed"`
+	Error       string        `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// Skipped counts processed events the action no longer applied to
+func (j *BulkJob) Skipped() int {
+	return j.Processed - j.Succeeded - j.Failed
+}
+
+// BulkDryRunResponse reports what a bulk request would affect
+type BulkDryRunResponse struct {
+	Action  BulkAction `json:"action"`
+	Matched int        `json:"matched"`
+	DryRun  bool       `json:"dry_run"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkAction_EligibleStatuses(t *testing.T) {
+	assert.Equal(t, []EventStatus{StatusFailed}, BulkActionRetry.EligibleStatuses())
+	assert.Len(t, BulkActionDelete.EligibleStatuses(), 5)
+	assert.Equal(t, []EventStatus{StatusPending, StatusRetrying}, BulkActionMarkFailed.EligibleStatuses())
+	assert.NotContains(t, BulkActionRequeue.EligibleStatuses(), StatusPending)
+	assert.Nil(t, BulkAction("archive").EligibleStatuses())
+}
+
+func TestBulkRequest_Validate(t *testing.T) {
+	invalid := EventStatus("done")
+
+	tests := []struct {
+		name          string
+		request       BulkRequest
+		expectedError string
+	}{
+		{name: "event IDs", request: BulkRequest{Action: BulkActionDelete, EventIDs: []string{"event-1"}}},
+		{name: "filter", request: BulkRequest{Action: BulkActionRetry, Filter: &BulkFilter{Type: "order.*"}}},
+		{name: "unknown action", request: BulkRequest{Action: "archive", EventIDs: []string{"event-1"}}, expectedError: "unknown action: archive"},
+		{name: "no selection", request: BulkRequest{Action: BulkActionDelete}, expectedError: "event_ids or filter is required"},
+		{name: "both selections", request: BulkRequest{Action: BulkActionDelete, EventIDs: []string{"event-1"}, Filter: &BulkFilter{}}, expectedError: "event_ids and filter cannot be combined"},
+		{name: "invalid status", request: BulkRequest{Action: BulkActionDelete, Filter: &BulkFilter{Status: &invalid}}, expectedError: "invalid status: done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBulkRequest_Query(t *testing.T) {
+	var request BulkRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"action": "mark-failed",
+		"filter": {"status": "retrying", "source": "billing", "created_before": "2024-01-02T00:00:00Z"}
+	}`), &request))
+
+	query := request.Query()
+	require.NotNil(t, query.Status)
+	assert.Equal(t, StatusRetrying, *query.Status)
+	assert.Equal(t, []EventStatus{StatusPending, StatusRetrying}, query.Statuses)
+	assert.Equal(t, "billing", query.Source)
+	require.NotNil(t, query.CreatedBefore)
+	assert.True(t, query.CreatedBefore.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, query.IDs)
+}
+
+func TestBulkJob_Skipped(t *testing.T) {
+	job := BulkJob{Processed: 10, Succeeded: 6, Failed: 1}
+	assert.Equal(t, 3, job.Skipped())
+}
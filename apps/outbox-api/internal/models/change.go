@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// EventChangesChannel is the PostgreSQL NOTIFY channel event changes are
+// sent on
+const EventChangesChannel = "outbox_event_changes"
+
+// ChangeAction names the transition an event went through
+type ChangeAction string
+
+const (
+	ChangeCreated   ChangeAction = "created"
+	ChangePublished ChangeAction = "published"
+	ChangeFailed    ChangeAction = "failed"
+	ChangeRetrying  ChangeAction = "retrying"
+	ChangeExpired   ChangeAction = "expired"
+	// ChangeRequeued marks an event sent back to pending by a bulk requeue.
+	// Requeued dead letters are created again instead.
+	ChangeRequeued     ChangeAction = "requeued"
+	ChangeDeadLettered ChangeAction = "dead_lettered"
+	ChangeDeleted      ChangeAction = "deleted"
+)
+
+// EventChange is one state transition of an outbox event. Changes are
+// recorded by a trigger on outbox_events with increasing IDs, so ID doubles
+// as the SSE event ID clients resume from.
+type EventChange struct {
+	ID      int64        `json:"id" db:"id"`
+	EventID string       `json:"event_id" db:"event_id"`
+	Action  ChangeAction `json:"action" db:"action"`
+	Type    string       `json:"type" db:"type"`
+	// Status is the event's status after the change, or before it was deleted
+	Status     EventStatus `json:"status" db:"status"`
+	RetryCount int         `json:"retry_count" db:"retry_count"`
+	ChangedAt  time.Time   `json:"changed_at" db:"changed_at"`
+}
+
+// ChangeFilter selects the changes a stream subscriber receives. Zero-valued
+// filters match everything; Type matches exactly, or by prefix when it ends
+// in "*".
+type ChangeFilter struct {
+	Status *EventStatus
+	Type   string
+}
+
+// Matches reports whether change passes the filter
+func (f *ChangeFilter) Matches(change *EventChange) bool {
+	if f.Status != nil && change.Status != *f.Status {
+		return false
+	}
+	if f.Type != "" && !MatchPattern(f.Type, change.Type) {
+		return false
+	}
+	return true
+}
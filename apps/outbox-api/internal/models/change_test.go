@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeFilter_Matches(t *testing.T) {
+	published := StatusPublished
+
+	tests := []struct {
+		name     string
+		filter   ChangeFilter
+		change   EventChange
+		expected bool
+	}{
+		{
+			name:     "no filters match everything",
+			filter:   ChangeFilter{},
+			change:   EventChange{Action: ChangeCreated, Type: "order.created", Status: StatusPending},
+			expected: true,
+		},
+		{
+			name:     "status",
+			filter:   ChangeFilter{Status: &published},
+			change:   EventChange{Action: ChangePublished, Type: "order.created", Status: StatusPublished},
+			expected: true,
+		},
+		{
+			name:     "status mismatch",
+			filter:   ChangeFilter{Status: &published},
+			change:   EventChange{Action: ChangeFailed, Type: "order.created", Status: StatusFailed},
+			expected: false,
+		},
+		{
+			name:     "exact type mismatch",
+			filter:   ChangeFilter{Type: "order.created"},
+			change:   EventChange{Action: ChangeCreated, Type: "order.cancelled", Status: StatusPending},
+			expected: false,
+		},
+		{
+			name:     "wildcard type",
+			filter:   ChangeFilter{Type: "order.*"},
+			change:   EventChange{Action: ChangeDeleted, Type: "order.cancelled", Status: StatusPublished},
+			expected: true,
+		},
+		{
+			name:     "status and type must both match",
+			filter:   ChangeFilter{Status: &published, Type: "order.*"},
+			change:   EventChange{Action: ChangePublished, Type: "user.created", Status: StatusPublished},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Matches(&tt.change))
+		})
+	}
+}
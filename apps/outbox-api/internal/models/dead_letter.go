@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter represents an event that was removed from the outbox after
+// exhausting its retry budget
+type DeadLetter struct {
+	ID             string          `json:"id" db:"id"`
+	Type           string          `json:"type" db:"type"`
+	Source         string          `json:"source" db:"source"`
+	Data           json.RawMessage `json:"data" db:"data"`
+	Metadata       json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	RetryCount     int             `json:"retry_count" db:"retry_count"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeadLetteredAt time.Time       `json:"dead_lettered_at" db:"dead_lettered_at"`
+	PartitionKey   string          `json:"partition_key,omitempty" db:"partition_key"`
+	DeliverAt      *time.Time      `json:"deliver_at,omitempty" db:"deliver_at"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty" db:"expires_at"`
+	Priority       int             `json:"priority" db:"priority"`
+}
+
+// Expired reports whether the dead letter's delivery deadline has passed at
+// now, in which case it can no longer be requeued
+func (d *DeadLetter) Expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !d.ExpiresAt.After(now)
+}
+
+// Event returns the dead letter as the failed event it was when it was
+// dead-lettered
+func (d *DeadLetter) Event() *Event {
+	return &Event{
+		ID:           d.ID,
+		Type:         d.Type,
+		Source:       d.Source,
+		Data:         d.Data,
+		Metadata:     d.Metadata,
+		Status:       StatusFailed,
+		RetryCount:   d.RetryCount,
+		LastError:    d.LastError,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.DeadLetteredAt,
+		PartitionKey: d.PartitionKey,
+		DeliverAt:    d.DeliverAt,
+		ExpiresAt:    d.ExpiresAt,
+		Priority:     d.Priority,
+	}
+}
+
+// DeadLettersResponse represents the response for listing dead letters
+type DeadLettersResponse struct {
+	DeadLetters []DeadLetter `json:"dead_letters"`
+	Total       int          `json:"total"`
+	Page        int          `json:"page"`
+	Limit       int          `json:"limit"`
+}
+
+// RequeueDeadLettersRequest selects dead letters to move back into the outbox.
+// Either EventIDs or All must be set.
+type RequeueDeadLettersRequest struct {
+	EventIDs []string `json:"event_ids,omitempty"`
+	All      bool     `json:"all,omitempty"`
+}
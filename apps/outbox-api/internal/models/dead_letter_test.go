@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetter_JSON(t *testing.T) {
+	now := time.Now()
+	deadLetter := DeadLetter{
+		ID:             "dead-1",
+		Type:           "order.placed",
+		Source:         "order-service",
+		Data:           json.RawMessage(`{"order_id": "456"}`),
+		RetryCount:     4,
+		LastError:      "webhook returned status 500",
+		CreatedAt:      now.Add(-time.Hour),
+		DeadLetteredAt: now,
+	}
+
+	jsonData, err := json.Marshal(deadLetter)
+	require.NoError(t, err)
+
+	var unmarshaled DeadLetter
+	err = json.Unmarshal(jsonData, &unmarshaled)
+	require.NoError(t, err)
+
+	assert.Equal(t, deadLetter.ID, unmarshaled.ID)
+	assert.JSONEq(t, string(deadLetter.Data), string(unmarshaled.Data))
+	assert.Nil(t, unmarshaled.Metadata)
+	assert.Equal(t, deadLetter.RetryCount, unmarshaled.RetryCount)
+	assert.Equal(t, deadLetter.LastError, unmarshaled.LastError)
+	assert.Equal(t, deadLetter.DeadLetteredAt.Unix(), unmarshaled.DeadLetteredAt.Unix())
+}
+
+func TestDeadLetter_Event(t *testing.T) {
+	now := time.Now()
+	deadLetter := DeadLetter{
+		ID:             "dead-1",
+		Type:           "order.placed",
+		Source:         "order-service",
+		Data:           json.RawMessage(`{"order_id": "456"}`),
+		RetryCount:     4,
+		LastError:      "webhook returned status 500",
+		CreatedAt:      now.Add(-time.Hour),
+		DeadLetteredAt: now,
+		PartitionKey:   "order-456",
+		Priority:       3,
+	}
+
+	event := deadLetter.Event()
+	assert.Equal(t, "dead-1", event.ID)
+	assert.Equal(t, StatusFailed, event.Status)
+	assert.Equal(t, 4, event.RetryCount)
+	assert.Equal(t, "webhook returned status 500", event.LastError)
+	assert.Equal(t, now, event.UpdatedAt)
+	assert.Equal(t, "order-456", event.PartitionKey)
+	assert.Equal(t, 3, event.Priority)
+}
+
+func TestRequeueDeadLettersRequest_JSON(t *testing.T) {
+	var req RequeueDeadLettersRequest
+	err := json.Unmarshal([]byte(`{"event_ids": ["a", "b"]}`), &req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, req.EventIDs)
+	assert.False(t, req.All)
+
+	err = json.Unmarshal([]byte(`{"all": true}`), &req)
+	require.NoError(t, err)
+	assert.True(t, req.All)
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/outbox"
 )
 
 // EventStatus represents the current status of an event
@@ -13,6 +15,9 @@ const (
 	StatusPublished EventStatus = "published"
 	StatusFailed    EventStatus = "failed"
 	StatusRetrying  EventStatus = "retrying"
+	// StatusExpired marks an event that reached its expires_at before it
+	// could be delivered. It is terminal: expired events are never published.
+	StatusExpired EventStatus = "expired"
 )
 
 // Event represents an outbox event
@@ -28,16 +33,29 @@ type Event struct {
 	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
 	PublishedAt *time.Time      `json:"published_at,omitempty" db:"published_at"`
+	// NextAttemptAt is when a retrying event becomes eligible for publishing again
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	// PartitionKey orders delivery: events sharing a key are published one at
+	// a time in creation order
+	PartitionKey string `json:"partition_key,omitempty" db:"partition_key"`
+	// DeliverAt holds the event back from publishers until the given time
+	DeliverAt *time.Time `json:"deliver_at,omitempty" db:"deliver_at"`
+	// ExpiresAt is when an undelivered event expires instead of being published
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	// Priority publishes urgent events first; 0 is the default and least urgent
+	Priority int `json:"priority" db:"priority"`
 }
 
-// CreateEventRequest represents the request to create a new event
-type CreateEventRequest struct {
-	Type     string          `json:"type" binding:"required"`
-	Source   string          `json:"source" binding:"required"`
-	Data     json.RawMessage `json:"data" binding:"required"`
-	Metadata json.RawMessage `json:"metadata,omitempty"`
+// Expired reports whether the event's delivery deadline has passed at now
+func (e *Event) Expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !e.ExpiresAt.After(now)
 }
 
+// CreateEventRequest represents the request to create a new event. It is
+// defined in pkg/outbox so services enqueuing events in their own
+// transactions share the same shape.
+type CreateEventRequest = outbox.CreateEventRequest
+
 // EventResponse represents the response for event operations
 type EventResponse struct {
 	Event *Event `json:"event,omitempty"`
@@ -48,8 +66,10 @@ type EventResponse struct {
 type EventsResponse struct {
 	Events []Event `json:"events"`
 	Total  int     `json:"total"`
-	Page   int     `json:"page"`
+	Page   int     `json:"page,omitempty"`
 	Limit  int     `json:"limit"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // PublishRequest represents the request to publish events
@@ -60,16 +80,28 @@ type PublishRequest struct {
 
 // PublishResponse represents the response for publish operations
 type PublishResponse struct {
-	Published int      `json:"published"`
-	Failed    int      `json:"failed"`
-	Errors    []string `json:"errors,omitempty"`
+	// Claimed counts the events the batch took, whatever became of them
+	Claimed   int `json:"claimed"`
+	Published int `json:"published"`
+	Failed    int `json:"failed"`
+	// Deferred counts events held back because their destination's circuit is open
+	Deferred int `json:"deferred,omitempty"`
+	// Blocked counts events held back behind an earlier event with the same
+	// partition key that was not published
+	Blocked int      `json:"blocked,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
 }
 
 // StatsResponse represents service statistics
 type StatsResponse struct {
-	TotalEvents     int `json:"total_events"`
-	PendingEvents   int `json:"pending_events"`
-	PublishedEvents int `json:"published_events"`
-	FailedEvents    int `json:"failed_events"`
-	RetryCount      int `json:"retry_count"`
+	TotalEvents      int `json:"total_events"`
+	PendingEvents    int `json:"pending_events"`
+	PublishedEvents  int `json:"published_events"`
+	FailedEvents     int `json:"failed_events"`
+	RetryingEvents   int `json:"retrying_events"`
+	ExpiredEvents    int `json:"expired_events"`
+	DeadLetterEvents int `json:"dead_letter_events"`
+	RetryCount       int `json:"retry_count"`
+	// PendingByPriority counts pending events per priority level
+	PendingByPriority map[int]int `json:"pending_by_priority"`
 }
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Valid reports whether s is a known event status
+func (s EventStatus) Valid() bool {
+	switch s {
+	case StatusPending, StatusPublished, StatusFailed, StatusRetrying, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// EventQuery selects and pages events for listing. Zero-valued filters match
+// everything. Type and Source match exactly, or by prefix when they end in "*".
+type EventQuery struct {
+	// IDs, when set, restricts the query to these events
+	IDs    []string
+	Status *EventStatus
+	// Statuses, when set, restricts the query to events in any of them
+	Statuses     []EventStatus
+	Type         string
+	Source       string
+	PartitionKey string
+	// After bounds are inclusive, before bounds exclusive
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	PublishedAfter  *time.Time
+	PublishedBefore *time.Time
+	DeliverAfter    *time.Time
+	DeliverBefore   *time.Time
+	ExpiresAfter    *time.Time
+	ExpiresBefore   *time.Time
+	MinRetryCount   *int
+	MaxRetryCount   *int
+	// ErrorContains is a case-insensitive substring of last_error
+	ErrorContains string
+	// Metadata is a JSON object the event's metadata must contain
+	Metadata json.RawMessage
+
+	// Cursor continues after the last event of a previous page. Unlike
+	// Offset it is unaffected by events inserted between requests.
+	Cursor *EventCursor
+	Offset int
+	Limit  int
+}
+
+// EventCursor marks a position in the newest-first event listing
+type EventCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+}
+
+// CursorAfter returns the cursor that continues a listing after event
+func CursorAfter(event *Event) *EventCursor {
+	return &EventCursor{CreatedAt: event.CreatedAt, ID: event.ID}
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c *EventCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseEventCursor decodes a token produced by EventCursor.Encode
+func ParseEventCursor(token string) (*EventCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor EventCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &cursor, nil
+}
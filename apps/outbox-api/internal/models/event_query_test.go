@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStatus_Valid(t *testing.T) {
+	assert.True(t, StatusPending.Valid())
+	assert.True(t, StatusPublished.Valid())
+	assert.True(t, StatusFailed.Valid())
+	assert.True(t, StatusRetrying.Valid())
+	assert.True(t, StatusExpired.Valid())
+	assert.False(t, EventStatus("done").Valid())
+	assert.False(t, EventStatus("").Valid())
+}
+
+func TestEventCursor_RoundTrip(t *testing.T) {
+	event := &Event{
+		ID:        "event-1",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC),
+	}
+
+	token := CursorAfter(event).Encode()
+	assert.NotContains(t, token, "=")
+
+	cursor, err := ParseEventCursor(token)
+	require.NoError(t, err)
+	assert.Equal(t, "event-1", cursor.ID)
+	assert.True(t, event.CreatedAt.Equal(cursor.CreatedAt))
+}
+
+func TestParseEventCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "not a cursor!"},
+		{name: "not JSON", token: "bm9wZQ"},
+		{name: "missing ID", token: (&EventCursor{CreatedAt: time.Now()}).Encode()},
+		{name: "missing timestamp", token: (&EventCursor{ID: "event-1"}).Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEventCursor(tt.token)
+			assert.EqualError(t, err, "invalid cursor")
+		})
+	}
+}
@@ -18,6 +18,7 @@ func TestEventStatus_String(t *testing.T) {
 		{StatusPublished, "published"},
 		{StatusFailed, "failed"},
 		{StatusRetrying, "retrying"},
+		{StatusExpired, "expired"},
 	}
 
 	for _, tt := range tests {
@@ -113,6 +114,17 @@ func TestEvent_JSON(t *testing.T) {
 	assert.Equal(t, event.PublishedAt, unmarshaled.PublishedAt)
 }
 
+func TestEvent_Expired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, (&Event{}).Expired(now))
+	assert.False(t, (&Event{ExpiresAt: &future}).Expired(now))
+	assert.True(t, (&Event{ExpiresAt: &now}).Expired(now))
+	assert.True(t, (&Event{ExpiresAt: &past}).Expired(now))
+}
+
 func TestEventsResponse_JSON(t *testing.T) {
 	now := time.Now()
 	events := []Event{
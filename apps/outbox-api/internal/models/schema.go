@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventSchema is one version of the JSON Schema that event data of a type
+// must match. Events are validated against the latest version.
+type EventSchema struct {
+	EventType string          `json:"event_type" db:"event_type"`
+	Version   int             `json:"version" db:"version"`
+	Schema    json.RawMessage `json:"schema" db:"schema"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// RegisterSchemaRequest represents the request to register a new schema
+// version for an event type
+type RegisterSchemaRequest struct {
+	EventType string          `json:"event_type" binding:"required"`
+	Schema    json.RawMessage `json:"schema" binding:"required"`
+}
+
+// EventSchemasResponse represents the response for listing schemas
+type EventSchemasResponse struct {
+	Schemas []EventSchema `json:"schemas"`
+	Total   int           `json:"total"`
+}
+
+// SchemaViolation locates one way in which event data fails its schema
+type SchemaViolation struct {
+	// Pointer is a JSON Pointer (RFC 6901) into the event data; "" is the
+	// data itself
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
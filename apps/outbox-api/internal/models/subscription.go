@@ -0,0 +1,120 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Subscription routes events to a webhook URL. An empty EventTypes or Sources
+// list matches every event; patterns may end in "*" to match by prefix, so
+// "order.*" matches "order.created" and "order.item.added".
+type Subscription struct {
+	ID         string   `json:"id" db:"id"`
+	Name       string   `json:"name" db:"name"`
+	URL        string   `json:"url" db:"url"`
+	EventTypes []string `json:"event_types" db:"event_types"`
+	Sources    []string `json:"sources" db:"sources"`
+	Active     bool     `json:"active" db:"active"`
+	// Secret signs deliveries. It is only returned when a subscription is
+	// created or its secret is rotated.
+	Secret string `json:"-" db:"secret"`
+	// PreviousSecret also signs deliveries until PreviousSecretExpiresAt so
+	// that consumers can rotate without rejecting deliveries
+	PreviousSecret          string     `json:"-" db:"previous_secret"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty" db:"previous_secret_expires_at"`
+	CreatedAt               time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SigningSecrets returns the secrets a delivery made at now is signed with
+func (s *Subscription) SigningSecrets(now time.Time) []string {
+	var secrets []string
+	if s.Secret != "" {
+		secrets = append(secrets, s.Secret)
+	}
+	if s.PreviousSecret != "" && s.PreviousSecretExpiresAt != nil && now.Before(*s.PreviousSecretExpiresAt) {
+		secrets = append(secrets, s.PreviousSecret)
+	}
+	return secrets
+}
+
+// Matches reports whether the event passes the subscription's type and source filters
+func (s *Subscription) Matches(event *Event) bool {
+	return matchesAny(s.EventTypes, event.Type) && matchesAny(s.Sources, event.Source)
+}
+
+// matchesAny reports whether value matches one of patterns, treating an empty
+// pattern list as a match-all
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if MatchPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchPattern matches value against an exact pattern or a prefix pattern
+// ending in "*"
+func MatchPattern(pattern, value string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(value, prefix)
+	}
+	return pattern == value
+}
+
+// SubscriptionRequest represents the request to create or replace a subscription
+type SubscriptionRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Sources    []string `json:"sources,omitempty"`
+	// Active defaults to true when omitted
+	Active *bool `json:"active,omitempty"`
+	// Secret sets the signing secret on create; one is generated when omitted.
+	// It is ignored on update, use the rotate-secret endpoint instead.
+	Secret string `json:"secret,omitempty"`
+}
+
+// IsActive returns the requested active flag, defaulting to true
+func (r *SubscriptionRequest) IsActive() bool {
+	return r.Active == nil || *r.Active
+}
+
+// RotateSecretRequest represents the request to rotate a subscription's signing secret
+type RotateSecretRequest struct {
+	// GracePeriod is how long the old secret keeps signing deliveries, e.g. "24h"
+	GracePeriod string `json:"grace_period,omitempty"`
+}
+
+// SubscriptionsResponse represents the response for listing subscriptions
+type SubscriptionsResponse struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	Total         int            `json:"total"`
+}
+
+// DeliveryStatus represents the outcome of delivering an event to one subscription
+type DeliveryStatus string
+
+const (
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery tracks an event's delivery to a single subscription, so that a
+// retry only resends to the subscriptions that have not yet received it
+type Delivery struct {
+	EventID        string         `json:"event_id" db:"event_id"`
+	SubscriptionID string         `json:"subscription_id" db:"subscription_id"`
+	URL            string         `json:"url" db:"url"`
+	Status         DeliveryStatus `json:"status" db:"status"`
+	Attempts       int            `json:"attempts" db:"attempts"`
+	LastError      string         `json:"last_error,omitempty" db:"last_error"`
+	LastAttemptAt  time.Time      `json:"last_attempt_at" db:"last_attempt_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" db:"delivered_at"`
+}
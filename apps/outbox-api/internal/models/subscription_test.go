@@ -0,0 +1,136 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription_Matches(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription Subscription
+		event        Event
+		expected     bool
+	}{
+		{
+			name:         "no filters match everything",
+			subscription: Subscription{},
+			event:        Event{Type: "user.created", Source: "user-service"},
+			expected:     true,
+		},
+		{
+			name:         "exact type",
+			subscription: Subscription{EventTypes: []string{"order.created"}},
+			event:        Event{Type: "order.created", Source: "order-service"},
+			expected:     true,
+		},
+		{
+			name:         "exact type mismatch",
+			subscription: Subscription{EventTypes: []string{"order.created"}},
+			event:        Event{Type: "order.cancelled", Source: "order-service"},
+			expected:     false,
+		},
+		{
+			name:         "wildcard type",
+			subscription: Subscription{EventTypes: []string{"order.*"}},
+			event:        Event{Type: "order.item.added", Source: "order-service"},
+			expected:     true,
+		},
+		{
+			name:         "wildcard type does not match other prefixes",
+			subscription: Subscription{EventTypes: []string{"order.*"}},
+			event:        Event{Type: "orders", Source: "order-service"},
+			expected:     false,
+		},
+		{
+			name:         "any of several types",
+			subscription: Subscription{EventTypes: []string{"user.*", "order.created"}},
+			event:        Event{Type: "order.created", Source: "order-service"},
+			expected:     true,
+		},
+		{
+			name:         "type and source must both match",
+			subscription: Subscription{EventTypes: []string{"order.*"}, Sources: []string{"billing-service"}},
+			event:        Event{Type: "order.created", Source: "order-service"},
+			expected:     false,
+		},
+		{
+			name:         "source filter only",
+			subscription: Subscription{Sources: []string{"order-service"}},
+			event:        Event{Type: "order.created", Source: "order-service"},
+			expected:     true,
+		},
+		{
+			name:         "catch-all pattern",
+			subscription: Subscription{EventTypes: []string{"*"}},
+			event:        Event{Type: "anything", Source: "anywhere"},
+			expected:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.subscription.Matches(&tt.event))
+		})
+	}
+}
+
+func TestSubscriptionRequest_IsActive(t *testing.T) {
+	var req SubscriptionRequest
+	err := json.Unmarshal([]byte(`{"name": "billing", "url": "https://billing.example.com/hooks"}`), &req)
+	require.NoError(t, err)
+	assert.True(t, req.IsActive())
+
+	err = json.Unmarshal([]byte(`{"name": "billing", "url": "https://billing.example.com/hooks", "active": false}`), &req)
+	require.NoError(t, err)
+	assert.False(t, req.IsActive())
+}
+
+func TestSubscription_SigningSecrets(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		subscription Subscription
+		expected     []string
+	}{
+		{
+			name:         "no secret",
+			subscription: Subscription{},
+			expected:     nil,
+		},
+		{
+			name:         "current secret only",
+			subscription: Subscription{Secret: "new"},
+			expected:     []string{"new"},
+		},
+		{
+			name:         "previous secret within its grace period",
+			subscription: Subscription{Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: &later},
+			expected:     []string{"new", "old"},
+		},
+		{
+			name:         "previous secret after its grace period",
+			subscription: Subscription{Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: &earlier},
+			expected:     []string{"new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.subscription.SigningSecrets(now))
+		})
+	}
+}
+
+func TestSubscription_JSONOmitsSecrets(t *testing.T) {
+	jsonData, err := json.Marshal(Subscription{ID: "sub-1", Secret: "whsec_new", PreviousSecret: "whsec_old"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(jsonData), "whsec_")
+}
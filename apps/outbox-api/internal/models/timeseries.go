@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ThroughputStats counts what happened to events over a period
+type ThroughputStats struct {
+	Created   int `json:"created"`
+	Published int `json:"published"`
+	// Failed counts events that were dead-lettered or marked failed
+	Failed int `json:"failed"`
+	// LatencyP50Ms and LatencyP95Ms are percentiles of the time from creation
+	// to publishing of the events published in the period; nil when none were
+	LatencyP50Ms *float64 `json:"latency_p50_ms"`
+	LatencyP95Ms *float64 `json:"latency_p95_ms"`
+}
+
+// StatsBucket is the throughput of the bucket starting at Start
+type StatsBucket struct {
+	Start time.Time `json:"start"`
+	ThroughputStats
+}
+
+// StatsBreakdown is the throughput of the events sharing a type or source
+type StatsBreakdown struct {
+	Key string `json:"key"`
+	ThroughputStats
+}
+
+// TimeseriesResponse represents throughput statistics over a window, split
+// into equal buckets and broken down by event type and source
+type TimeseriesResponse struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	BucketSeconds int             `json:"bucket_seconds"`
+	Totals        ThroughputStats `json:"totals"`
+	// Buckets covers the whole window, oldest first, including empty buckets
+	Buckets []StatsBucket `json:"buckets"`
+	// ByType and BySource are ordered busiest first
+	ByType   []StatsBreakdown `json:"by_type"`
+	BySource []StatsBreakdown `json:"by_source"`
+}
+
+// Total returns how many events were created, published or failed
+func (t *ThroughputStats) Total() int {
+	return t.Created + t.Published + t.Failed
+}
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThroughputStats_Total(t *testing.T) {
+	assert.Equal(t, 0, (&ThroughputStats{}).Total())
+	assert.Equal(t, 9, (&ThroughputStats{Created: 5, Published: 3, Failed: 1}).Total())
+}
@@ -0,0 +1,107 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaWriter is the part of *kafka.Writer the publisher uses
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Kafka produces events to a topic over the Kafka wire protocol, so it works
+// with Kafka and compatible brokers such as Redpanda. An event is
+// acknowledged once every in-sync replica has written it.
+//
+// CloudEvents follow the Kafka protocol binding: binary mode sends attributes
+// as ce_ headers.
+type Kafka struct {
+	writer      kafkaWriter
+	format      string
+	destination string
+}
+
+// NewKafka creates a Kafka publisher. Messages are keyed by partition key,
+// or by event ID when the event has none.
+func NewKafka(cfg config.KafkaConfig, format string) (*Kafka, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka publisher requires at least one broker")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka publisher requires a topic")
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// Events are written one at a time, so don't wait to fill a batch
+		BatchTimeout: 10 * time.Millisecond,
+	}
+
+	return newKafka(writer, cfg, format), nil
+}
+
+func newKafka(writer kafkaWriter, cfg config.KafkaConfig, format string) *Kafka {
+	return &Kafka{
+		writer:      writer,
+		format:      format,
+		destination: fmt.Sprintf("kafka://%s/%s", strings.Join(cfg.Brokers, ","), cfg.Topic),
+	}
+}
+
+// Publish writes the event and waits for the broker's acknowledgement
+func (k *Kafka) Publish(ctx context.Context, event *models.Event) error {
+	msg, err := Encode(event, k.format)
+	if err != nil {
+		return err
+	}
+
+	headers := []kafka.Header{
+		{Key: "event-id", Value: []byte(event.ID)},
+		{Key: "event-type", Value: []byte(event.Type)},
+		{Key: "event-source", Value: []byte(event.Source)},
+		{Key: "content-type", Value: []byte(msg.ContentType)},
+	}
+	for _, name := range slices.Sorted(maps.Keys(msg.Attributes)) {
+		headers = append(headers, kafka.Header{Key: "ce_" + name, Value: []byte(msg.Attributes[name])})
+	}
+
+	// Keyed events land on one Kafka partition so consumers see them in order
+	key := event.ID
+	if event.PartitionKey != "" {
+		key = event.PartitionKey
+	}
+
+	err = k.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(key),
+		Value:   msg.Body,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write kafka message: %w", err)
+	}
+
+	return nil
+}
+
+// Destination returns the brokers and topic as a kafka:// URL
+func (k *Kafka) Destination() string {
+	return k.destination
+}
+
+// Close flushes and closes the writer
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,138 @@
+package publisher
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafkaWriter records written messages and fails with err
+type fakeKafkaWriter struct {
+	messages []kafka.Message
+	err      error
+	closed   bool
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestKafka_Publish(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	p := newKafka(writer, config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "events"}, config.EventFormatJSON)
+
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	require.Len(t, writer.messages, 1)
+
+	message := writer.messages[0]
+	assert.Equal(t, "event-1", string(message.Key))
+	assert.Contains(t, string(message.Value), `"type":"order.created"`)
+
+	headers := map[string]string{}
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	assert.Equal(t, map[string]string{
+		"event-id":     "event-1",
+		"event-type":   "order.created",
+		"event-source": "order-service",
+		"content-type": "application/json",
+	}, headers)
+
+	require.NoError(t, p.Close())
+	assert.True(t, writer.closed)
+}
+
+func TestKafka_PublishCloudEventsBinary(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	p := newKafka(writer, config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "events"}, config.EventFormatCloudEventsBinary)
+
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	require.Len(t, writer.messages, 1)
+
+	message := writer.messages[0]
+	assert.JSONEq(t, `{"order_id":"123"}`, string(message.Value))
+
+	headers := map[string]string{}
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	assert.Equal(t, "1.0", headers["ce_specversion"])
+	assert.Equal(t, "event-1", headers["ce_id"])
+	assert.Equal(t, "order-service", headers["ce_source"])
+	assert.Equal(t, "order.created", headers["ce_type"])
+	assert.Equal(t, "2024-01-02T03:04:05Z", headers["ce_time"])
+	assert.Equal(t, "application/json", headers["content-type"])
+}
+
+func TestKafka_PublishPartitionKey(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	p := newKafka(writer, config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "events"}, config.EventFormatJSON)
+
+	event := testEvent()
+	event.PartitionKey = "order-123"
+	require.NoError(t, p.Publish(context.Background(), event))
+	require.Len(t, writer.messages, 1)
+	assert.Equal(t, "order-123", string(writer.messages[0].Key))
+}
+
+func TestKafka_PublishNotAcknowledged(t *testing.T) {
+	writer := &fakeKafkaWriter{err: kafka.NotEnoughReplicas}
+	p := newKafka(writer, config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "events"}, config.EventFormatJSON)
+
+	err := p.Publish(context.Background(), testEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write kafka message")
+	assert.ErrorIs(t, err, kafka.NotEnoughReplicas)
+}
+
+// TestKafka_Integration runs against a real broker, e.g. the Redpanda
+// container from `docker compose --profile brokers up`:
+//
+//	TEST_KAFKA_BROKERS=localhost:19092 go test ./internal/publisher -run Integration
+func TestKafka_Integration(t *testing.T) {
+	brokers := os.Getenv("TEST_KAFKA_BROKERS")
+	if brokers == "" {
+		t.Skip("TEST_KAFKA_BROKERS not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cfg := config.KafkaConfig{Brokers: strings.Split(brokers, ","), Topic: "outbox-it-" + time.Now().Format("20060102150405")}
+
+	// Create the topic up front; auto-creation is not guaranteed
+	conn, err := kafka.DialContext(ctx, "tcp", cfg.Brokers[0])
+	require.NoError(t, err)
+	require.NoError(t, conn.CreateTopics(kafka.TopicConfig{Topic: cfg.Topic, NumPartitions: 1, ReplicationFactor: 1}))
+	conn.Close()
+
+	p, err := NewKafka(cfg, config.EventFormatJSON)
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.NoError(t, p.Publish(ctx, testEvent()))
+
+	reader := kafka.NewReader(kafka.ReaderConfig{Brokers: cfg.Brokers, Topic: cfg.Topic, Partition: 0})
+	defer reader.Close()
+
+	message, err := reader.ReadMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "event-1", string(message.Key))
+}
@@ -0,0 +1,20 @@
+package publisher
+
+import (
+	observability "github.com/jared-scarr/portfolio-monorepo/packages/observability/handlers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// webhookResponsesTotal counts webhook deliveries by response status code,
+// or "error" when no response was received
+var webhookResponsesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "outbox_webhook_responses_total",
+		Help: "Webhook deliveries by response status code",
+	},
+	[]string{"code"},
+)
+
+func init() {
+	observability.MustRegister(webhookResponsesTotal)
+}
@@ -0,0 +1,118 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/cloudevents"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsStream is the part of jetstream.JetStream the publisher uses
+type natsStream interface {
+	PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+}
+
+// NATS publishes events to JetStream on "<subject>.<event type>". An event
+// is acknowledged once a stream has stored it; the event ID is sent as
+// Nats-Msg-Id so the stream drops redeliveries within its duplicate window.
+// CloudEvents binary mode sends attributes as ce- headers.
+type NATS struct {
+	conn        *nats.Conn
+	stream      natsStream
+	subject     string
+	format      string
+	destination string
+}
+
+// NewNATS connects to the NATS server in cfg
+func NewNATS(cfg config.NATSConfig, format string) (*NATS, error) {
+	if cfg.Subject == "" {
+		return nil, fmt.Errorf("nats publisher requires a subject")
+	}
+
+	conn, err := nats.Connect(cfg.URL, nats.Name("outbox-api"), nats.Timeout(5*time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	return &NATS{
+		conn:        conn,
+		stream:      js,
+		subject:     cfg.Subject,
+		format:      format,
+		destination: natsDestination(cfg),
+	}, nil
+}
+
+// natsDestination names the servers and subject without any credentials
+func natsDestination(cfg config.NATSConfig) string {
+	var servers []string
+	for _, server := range strings.Split(cfg.URL, ",") {
+		if u, err := url.Parse(strings.TrimSpace(server)); err == nil && u.Host != "" {
+			servers = append(servers, u.Host)
+		}
+	}
+	return fmt.Sprintf("nats://%s/%s", strings.Join(servers, ","), cfg.Subject)
+}
+
+// Publish sends the event and waits for the stream's acknowledgement
+func (n *NATS) Publish(ctx context.Context, event *models.Event) error {
+	encoded, err := Encode(event, n.format)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(n.subjectFor(event))
+	msg.Data = encoded.Body
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Header.Set("Event-Type", event.Type)
+	msg.Header.Set("Event-Source", event.Source)
+	msg.Header.Set("Content-Type", encoded.ContentType)
+	if event.PartitionKey != "" {
+		msg.Header.Set("Partition-Key", event.PartitionKey)
+	}
+	for name, value := range encoded.Attributes {
+		msg.Header.Set(cloudevents.HeaderPrefix+name, value)
+	}
+
+	ack, err := n.stream.PublishMsg(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish nats message: %w", err)
+	}
+	if ack == nil || ack.Stream == "" {
+		return fmt.Errorf("nats message was not stored by a stream")
+	}
+
+	return nil
+}
+
+// subjectFor appends the event type so consumers can filter by it
+func (n *NATS) subjectFor(event *models.Event) string {
+	return n.subject + "." + strings.NewReplacer(" ", "_", "*", "_", ">", "_").Replace(event.Type)
+}
+
+// Destination returns the servers and subject prefix as a nats:// URL
+func (n *NATS) Destination() string {
+	return n.destination
+}
+
+// Close drains pending messages and closes the connection
+func (n *NATS) Close() error {
+	if n.conn == nil {
+		return nil
+	}
+	return n.conn.Drain()
+}
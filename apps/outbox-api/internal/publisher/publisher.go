@@ -0,0 +1,133 @@
+// Package publisher delivers outbox events to a destination: a webhook or a
+// message broker. Publish returns nil only once the destination has
+// acknowledged the event, so a nil error is what lets the relay mark an event
+// published; any error leaves it to be retried.
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/cloudevents"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// Publisher delivers events to one destination
+type Publisher interface {
+	// Publish sends an event and waits for the destination to acknowledge it
+	Publish(ctx context.Context, event *models.Event) error
+	// Destination identifies where events go; it keys the circuit breaker
+	Destination() string
+	// Close releases connections held by the publisher
+	Close() error
+}
+
+// New creates the publisher selected by cfg.Publish.Publisher, encoding
+// events as cfg.Publish.EventFormat
+func New(ctx context.Context, cfg *config.Config) (Publisher, error) {
+	format := cfg.Publish.EventFormat
+	switch strings.ToLower(cfg.Publish.Publisher) {
+	case "", config.PublisherWebhook:
+		return NewWebhook(cfg.Publish.WebhookURL, cfg.Publish.WebhookSecrets(), format), nil
+	case config.PublisherKafka:
+		return NewKafka(cfg.Kafka, format)
+	case config.PublisherNATS:
+		return NewNATS(cfg.NATS, format)
+	case config.PublisherSQS:
+		return NewSQS(ctx, cfg.SQS, format)
+	default:
+		return nil, fmt.Errorf("unknown publisher %q", cfg.Publish.Publisher)
+	}
+}
+
+// MaxResponseBody caps how much of a destination's response body is kept
+const MaxResponseBody = 1024
+
+// Response is what a destination answered to a publish. Only webhooks
+// answer; brokers leave it empty.
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+type responseKey struct{}
+
+// WithResponse returns a copy of ctx that makes Publish record the
+// destination's response in resp, whether or not the publish succeeds
+func WithResponse(ctx context.Context, resp *Response) context.Context {
+	return context.WithValue(ctx, responseKey{}, resp)
+}
+
+// recordResponse stores a response in the Response attached to ctx, if any.
+// The body is truncated to MaxResponseBody and made valid UTF-8 so that it
+// can be stored as text.
+func recordResponse(ctx context.Context, statusCode int, body []byte) {
+	resp, ok := ctx.Value(responseKey{}).(*Response)
+	if !ok || resp == nil {
+		return
+	}
+
+	if len(body) > MaxResponseBody {
+		body = body[:MaxResponseBody]
+	}
+	resp.StatusCode = statusCode
+	resp.Body = strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+}
+
+// Payload encodes an event as the JSON message body every backend sends
+func Payload(event *models.Event) ([]byte, error) {
+	payload := map[string]interface{}{
+		"id":         event.ID,
+		"type":       event.Type,
+		"source":     event.Source,
+		"data":       event.Data,
+		"metadata":   event.Metadata,
+		"created_at": event.CreatedAt,
+	}
+	if event.PartitionKey != "" {
+		payload["partition_key"] = event.PartitionKey
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	return body, nil
+}
+
+// Message is an event encoded for sending
+type Message struct {
+	Body        []byte
+	ContentType string
+	// Attributes are the CloudEvents context attributes that binary mode
+	// sends as headers, keyed by attribute name without a prefix
+	Attributes map[string]string
+}
+
+// Encode encodes an event in one of the config.EventFormat* formats. The
+// json format, and any unrecognised one, is the Payload body.
+func Encode(event *models.Event, format string) (*Message, error) {
+	switch format {
+	case config.EventFormatCloudEventsStructured:
+		body, err := json.Marshal(cloudevents.FromEvent(event))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal cloudevent: %w", err)
+		}
+		return &Message{Body: body, ContentType: cloudevents.ContentType}, nil
+
+	case config.EventFormatCloudEventsBinary:
+		ce := cloudevents.FromEvent(event)
+		return &Message{Body: ce.Data, ContentType: ce.DataContentType, Attributes: ce.Attributes()}, nil
+
+	default:
+		body, err := Payload(event)
+		if err != nil {
+			return nil, err
+		}
+		return &Message{Body: body, ContentType: "application/json"}, nil
+	}
+}
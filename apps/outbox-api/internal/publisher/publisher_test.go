@@ -0,0 +1,201 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() *models.Event {
+	return &models.Event{
+		ID:        "event-1",
+		Type:      "order.created",
+		Source:    "order-service",
+		Data:      json.RawMessage(`{"order_id":"123"}`),
+		Status:    models.StatusPending,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name                string
+		cfg                 config.Config
+		expectedDestination string
+		expectedError       string
+	}{
+		{
+			name:                "webhook by default",
+			cfg:                 config.Config{Publish: config.PublishConfig{WebhookURL: "https://hooks.example.com/outbox"}},
+			expectedDestination: "https://hooks.example.com/outbox",
+		},
+		{
+			name: "kafka",
+			cfg: config.Config{
+				Publish: config.PublishConfig{Publisher: config.PublisherKafka},
+				Kafka:   config.KafkaConfig{Brokers: []string{"kafka-1:9092", "kafka-2:9092"}, Topic: "events"},
+			},
+			expectedDestination: "kafka://kafka-1:9092,kafka-2:9092/events",
+		},
+		{
+			name: "kafka without brokers",
+			cfg: config.Config{
+				Publish: config.PublishConfig{Publisher: config.PublisherKafka},
+				Kafka:   config.KafkaConfig{Topic: "events"},
+			},
+			expectedError: "kafka publisher requires at least one broker",
+		},
+		{
+			name: "nats without subject",
+			cfg: config.Config{
+				Publish: config.PublishConfig{Publisher: config.PublisherNATS},
+				NATS:    config.NATSConfig{URL: "nats://localhost:4222"},
+			},
+			expectedError: "nats publisher requires a subject",
+		},
+		{
+			name: "sqs",
+			cfg: config.Config{
+				Publish: config.PublishConfig{Publisher: config.PublisherSQS},
+				SQS:     config.SQSConfig{QueueURL: "http://localhost:9324/000000000000/events", Region: "us-east-1"},
+			},
+			expectedDestination: "http://localhost:9324/000000000000/events",
+		},
+		{
+			name: "sqs without queue",
+			cfg: config.Config{
+				Publish: config.PublishConfig{Publisher: config.PublisherSQS},
+			},
+			expectedError: "sqs publisher requires a queue URL",
+		},
+		{
+			name:          "unknown publisher",
+			cfg:           config.Config{Publish: config.PublishConfig{Publisher: "carrier-pigeon"}},
+			expectedError: `unknown publisher "carrier-pigeon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(context.Background(), &tt.cfg)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			defer p.Close()
+			assert.Equal(t, tt.expectedDestination, p.Destination())
+		})
+	}
+}
+
+func TestPayload(t *testing.T) {
+	body, err := Payload(testEvent())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"id": "event-1",
+		"type": "order.created",
+		"source": "order-service",
+		"data": {"order_id": "123"},
+		"metadata": null,
+		"created_at": "2024-01-02T03:04:05Z"
+	}`, string(body))
+}
+
+func TestPayload_PartitionKey(t *testing.T) {
+	event := testEvent()
+	event.PartitionKey = "order-123"
+
+	body, err := Payload(event)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"partition_key":"order-123"`)
+}
+
+func TestRecordResponse(t *testing.T) {
+	// Without WithResponse there is nowhere to record to
+	recordResponse(context.Background(), 200, []byte("ok"))
+
+	var resp Response
+	recordResponse(WithResponse(context.Background(), &resp), 502, []byte("bad\x00gateway\xff"))
+	assert.Equal(t, 502, resp.StatusCode)
+	assert.Equal(t, "badgateway", resp.Body)
+}
+
+func TestEncode(t *testing.T) {
+	event := testEvent()
+	event.Metadata = json.RawMessage(`{"subject": "order-123", "traceparent": "00-abc-def-01", "Not_An_Attribute": "x", "nested": {"a": 1}}`)
+
+	tests := []struct {
+		name                string
+		format              string
+		expectedContentType string
+		expectedBody        string
+		expectedAttributes  map[string]string
+	}{
+		{
+			name:                "json",
+			format:              config.EventFormatJSON,
+			expectedContentType: "application/json",
+			expectedBody: `{
+				"id": "event-1",
+				"type": "order.created",
+				"source": "order-service",
+				"data": {"order_id": "123"},
+				"metadata": {"subject": "order-123", "traceparent": "00-abc-def-01", "Not_An_Attribute": "x", "nested": {"a": 1}},
+				"created_at": "2024-01-02T03:04:05Z"
+			}`,
+		},
+		{
+			name:                "cloudevents structured",
+			format:              config.EventFormatCloudEventsStructured,
+			expectedContentType: "application/cloudevents+json",
+			expectedBody: `{
+				"specversion": "1.0",
+				"id": "event-1",
+				"source": "order-service",
+				"type": "order.created",
+				"time": "2024-01-02T03:04:05Z",
+				"subject": "order-123",
+				"traceparent": "00-abc-def-01",
+				"datacontenttype": "application/json",
+				"data": {"order_id": "123"}
+			}`,
+		},
+		{
+			name:                "cloudevents binary",
+			format:              config.EventFormatCloudEventsBinary,
+			expectedContentType: "application/json",
+			expectedBody:        `{"order_id": "123"}`,
+			expectedAttributes: map[string]string{
+				"specversion": "1.0",
+				"id":          "event-1",
+				"source":      "order-service",
+				"type":        "order.created",
+				"time":        "2024-01-02T03:04:05Z",
+				"subject":     "order-123",
+				"traceparent": "00-abc-def-01",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Encode(event, tt.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedContentType, msg.ContentType)
+			assert.JSONEq(t, tt.expectedBody, string(msg.Body))
+			assert.Equal(t, tt.expectedAttributes, msg.Attributes)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// sqsAPI is the part of *sqs.Client the publisher uses
+type sqsAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// SQS sends events to an SQS queue, or to an SQS-compatible stand-in such as
+// ElasticMQ or LocalStack when an endpoint is configured. An event is
+// acknowledged once SendMessage returns a message ID; the SDK also checks the
+// MD5 of the body the queue stored. FIFO queues get the event ID as the
+// deduplication ID and the event source as the message group.
+//
+// SQS caps a message at ten attributes, too few for CloudEvents binary mode,
+// so CloudEvents are always sent in structured mode.
+type SQS struct {
+	client   sqsAPI
+	queueURL string
+	format   string
+	fifo     bool
+}
+
+// NewSQS creates an SQS publisher using the default AWS credential chain
+func NewSQS(ctx context.Context, cfg config.SQSConfig, format string) (*SQS, error) {
+	if cfg.QueueURL == "" {
+		return nil, fmt.Errorf("sqs publisher requires a queue URL")
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	})
+
+	return newSQS(client, cfg.QueueURL, format), nil
+}
+
+func newSQS(client sqsAPI, queueURL string, format string) *SQS {
+	if format == config.EventFormatCloudEventsBinary {
+		format = config.EventFormatCloudEventsStructured
+	}
+	return &SQS{
+		client:   client,
+		queueURL: queueURL,
+		format:   format,
+		fifo:     strings.HasSuffix(queueURL, ".fifo"),
+	}
+}
+
+// Publish sends the event and waits for the queue to accept it
+func (s *SQS) Publish(ctx context.Context, event *models.Event) error {
+	msg, err := Encode(event, s.format)
+	if err != nil {
+		return err
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.queueURL),
+		MessageBody: aws.String(string(msg.Body)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"event-id":     {DataType: aws.String("String"), StringValue: aws.String(event.ID)},
+			"event-type":   {DataType: aws.String("String"), StringValue: aws.String(event.Type)},
+			"event-source": {DataType: aws.String("String"), StringValue: aws.String(event.Source)},
+			"content-type": {DataType: aws.String("String"), StringValue: aws.String(msg.ContentType)},
+		},
+	}
+	if s.fifo {
+		// FIFO queues order messages within a group, so keyed events use
+		// their partition key as the group
+		group := event.Source
+		if event.PartitionKey != "" {
+			group = event.PartitionKey
+		}
+		input.MessageGroupId = aws.String(group)
+		input.MessageDeduplicationId = aws.String(event.ID)
+	}
+
+	output, err := s.client.SendMessage(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to send sqs message: %w", err)
+	}
+	if output.MessageId == nil || *output.MessageId == "" {
+		return fmt.Errorf("sqs did not return a message ID")
+	}
+
+	return nil
+}
+
+// Destination returns the queue URL
+func (s *SQS) Destination() string {
+	return s.queueURL
+}
+
+// Close is a no-op; the SDK client holds no long-lived connections
+func (s *SQS) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package publisher
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sqsSendMessage is the subset of a SendMessage request the fake inspects
+type sqsSendMessage struct {
+	QueueUrl               string
+	MessageBody            string
+	MessageGroupId         string
+	MessageDeduplicationId string
+	MessageAttributes      map[string]struct {
+		DataType    string
+		StringValue string
+	}
+}
+
+// newFakeSQS serves the SQS JSON protocol's SendMessage, answering with the
+// body's MD5 unless corruptMD5 is set
+func newFakeSQS(t *testing.T, corruptMD5 bool) (*httptest.Server, *[]sqsSendMessage) {
+	var received []sqsSendMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "AmazonSQS.SendMessage", r.Header.Get("X-Amz-Target"))
+
+		var message sqsSendMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		received = append(received, message)
+
+		sum := md5.Sum([]byte(message.MessageBody))
+		if corruptMD5 {
+			sum = md5.Sum([]byte("something else"))
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		json.NewEncoder(w).Encode(map[string]string{
+			"MessageId":        "message-1",
+			"MD5OfMessageBody": hex.EncodeToString(sum[:]),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func newTestSQSClient(endpoint string) *sqs.Client {
+	return sqs.New(sqs.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(endpoint),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+}
+
+func TestSQS_Publish(t *testing.T) {
+	tests := []struct {
+		name          string
+		queueURL      string
+		format        string
+		partitionKey  string
+		corruptMD5    bool
+		expectFIFO    bool
+		expectedGroup string
+		expectedError string
+	}{
+		{
+			name:     "standard queue",
+			queueURL: "http://localhost:9324/000000000000/events",
+		},
+		{
+			name:          "fifo queue",
+			queueURL:      "http://localhost:9324/000000000000/events.fifo",
+			expectFIFO:    true,
+			expectedGroup: "order-service",
+		},
+		{
+			name:          "fifo queue groups by partition key",
+			queueURL:      "http://localhost:9324/000000000000/events.fifo",
+			partitionKey:  "order-123",
+			expectFIFO:    true,
+			expectedGroup: "order-123",
+		},
+		{
+			name:     "cloudevents binary mode falls back to structured",
+			queueURL: "http://localhost:9324/000000000000/events",
+			format:   config.EventFormatCloudEventsBinary,
+		},
+		{
+			name:          "checksum mismatch is not an acknowledgement",
+			queueURL:      "http://localhost:9324/000000000000/events",
+			corruptMD5:    true,
+			expectedError: "failed to send sqs message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := newFakeSQS(t, tt.corruptMD5)
+			p := newSQS(newTestSQSClient(server.URL), tt.queueURL, tt.format)
+			assert.Equal(t, tt.queueURL, p.Destination())
+
+			event := testEvent()
+			event.PartitionKey = tt.partitionKey
+			err := p.Publish(context.Background(), event)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, *received, 1)
+			message := (*received)[0]
+			assert.Equal(t, tt.queueURL, message.QueueUrl)
+			assert.Contains(t, message.MessageBody, `"id":"event-1"`)
+			assert.Equal(t, "order.created", message.MessageAttributes["event-type"].StringValue)
+			if tt.format != "" {
+				assert.Contains(t, message.MessageBody, `"specversion":"1.0"`)
+				assert.Equal(t, "application/cloudevents+json", message.MessageAttributes["content-type"].StringValue)
+			} else {
+				assert.Equal(t, "application/json", message.MessageAttributes["content-type"].StringValue)
+			}
+
+			if tt.expectFIFO {
+				assert.Equal(t, tt.expectedGroup, message.MessageGroupId)
+				assert.Equal(t, "event-1", message.MessageDeduplicationId)
+			} else {
+				assert.Empty(t, message.MessageGroupId)
+				assert.Empty(t, message.MessageDeduplicationId)
+			}
+		})
+	}
+}
+
+// TestSQS_Integration runs against SQS or a compatible stand-in, e.g. the
+// ElasticMQ container from `docker compose --profile brokers up`:
+//
+//	TEST_SQS_ENDPOINT=http://localhost:9324 AWS_ACCESS_KEY_ID=x AWS_SECRET_ACCESS_KEY=x \
+//	  go test ./internal/publisher -run Integration
+func TestSQS_Integration(t *testing.T) {
+	endpoint := os.Getenv("TEST_SQS_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_SQS_ENDPOINT not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client := sqs.New(sqs.Options{Region: "us-east-1", BaseEndpoint: aws.String(endpoint), Credentials: aws.AnonymousCredentials{}})
+	queue, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String("outbox-it-" + time.Now().Format("20060102150405"))})
+	require.NoError(t, err)
+	defer client.DeleteQueue(context.Background(), &sqs.DeleteQueueInput{QueueUrl: queue.QueueUrl})
+
+	p, err := NewSQS(ctx, config.SQSConfig{QueueURL: *queue.QueueUrl, Region: "us-east-1", Endpoint: endpoint}, config.EventFormatJSON)
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.NoError(t, p.Publish(ctx, testEvent()))
+
+	received, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: queue.QueueUrl, WaitTimeSeconds: 5})
+	require.NoError(t, err)
+	require.Len(t, received.Messages, 1)
+	assert.Contains(t, *received.Messages[0].Body, `"id":"event-1"`)
+}
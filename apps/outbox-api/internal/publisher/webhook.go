@@ -0,0 +1,85 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/cloudevents"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/webhook"
+)
+
+// Webhook POSTs events to a URL. A 2xx response acknowledges the event.
+type Webhook struct {
+	url     string
+	secrets []string
+	format  string
+	client  *http.Client
+}
+
+// NewWebhook creates a publisher for url that signs each body with every one
+// of secrets, or leaves it unsigned when there are none. In CloudEvents
+// binary mode the ce- headers carrying the event's attributes are signed
+// along with the body.
+// Requests have no timeout of their own: the context passed to Publish,
+// which carries the configured delivery deadline, bounds them.
+func NewWebhook(url string, secrets []string, format string) *Webhook {
+	return &Webhook{
+		url:     url,
+		secrets: secrets,
+		format:  format,
+		client:  &http.Client{},
+	}
+}
+
+// Publish posts the event and requires a 2xx response
+func (w *Webhook) Publish(ctx context.Context, event *models.Event) error {
+	msg, err := Encode(event, w.format)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewBuffer(msg.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", msg.ContentType)
+	req.Header.Set("User-Agent", "outbox-api/1.0")
+	for name, value := range msg.Attributes {
+		req.Header.Set(cloudevents.HeaderPrefix+name, value)
+	}
+	webhook.SignRequest(req, msg.Body, time.Now(), w.secrets...)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		webhookResponsesTotal.WithLabelValues("error").Inc()
+		return fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	webhookResponsesTotal.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxResponseBody))
+	recordResponse(ctx, resp.StatusCode, body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Destination returns the webhook URL
+func (w *Webhook) Destination() string {
+	return w.url
+}
+
+// Close is a no-op; connections are pooled by the HTTP client
+func (w *Webhook) Close() error {
+	return nil
+}
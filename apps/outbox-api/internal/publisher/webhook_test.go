@@ -0,0 +1,177 @@
+package publisher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Publish(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		secrets       []string
+		expectedError string
+	}{
+		{name: "2xx acknowledges the event", status: http.StatusAccepted, secrets: []string{"whsec_test"}},
+		{name: "unsigned without secrets", status: http.StatusOK},
+		{name: "non-2xx is an error", status: http.StatusServiceUnavailable, expectedError: "webhook returned status 503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var err error
+				header = r.Header.Clone()
+				if len(tt.secrets) > 0 {
+					body, err = webhook.VerifyRequest(r, webhook.DefaultTolerance, tt.secrets...)
+					assert.NoError(t, err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			p := NewWebhook(server.URL, tt.secrets, config.EventFormatJSON)
+			assert.Equal(t, server.URL, p.Destination())
+
+			responses := webhookResponsesTotal.WithLabelValues(strconv.Itoa(tt.status))
+			before := testutil.ToFloat64(responses)
+
+			err := p.Publish(context.Background(), testEvent())
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, "application/json", header.Get("Content-Type"))
+			if len(tt.secrets) > 0 {
+				assert.Contains(t, string(body), `"id":"event-1"`)
+			} else {
+				assert.Empty(t, header.Get(webhook.SignatureHeader))
+			}
+			assert.Equal(t, before+1, testutil.ToFloat64(responses))
+		})
+	}
+}
+
+func TestWebhook_PublishRecordsResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(strings.Repeat("x", MaxResponseBody+100)))
+	}))
+	defer server.Close()
+
+	var resp Response
+	err := NewWebhook(server.URL, nil, config.EventFormatJSON).Publish(WithResponse(context.Background(), &resp), testEvent())
+	require.Error(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, resp.Body, MaxResponseBody)
+}
+
+func TestWebhook_PublishCloudEvents(t *testing.T) {
+	tests := []struct {
+		name                string
+		format              string
+		expectedContentType string
+		expectedBody        string
+		expectedHeaders     map[string]string
+	}{
+		{
+			name:                "structured mode",
+			format:              config.EventFormatCloudEventsStructured,
+			expectedContentType: "application/cloudevents+json",
+			expectedBody: `{
+				"specversion": "1.0",
+				"id": "event-1",
+				"source": "order-service",
+				"type": "order.created",
+				"time": "2024-01-02T03:04:05Z",
+				"datacontenttype": "application/json",
+				"data": {"order_id": "123"}
+			}`,
+		},
+		{
+			name:                "binary mode",
+			format:              config.EventFormatCloudEventsBinary,
+			expectedContentType: "application/json",
+			expectedBody:        `{"order_id": "123"}`,
+			expectedHeaders: map[string]string{
+				"Ce-Specversion": "1.0",
+				"Ce-Id":          "event-1",
+				"Ce-Source":      "order-service",
+				"Ce-Type":        "order.created",
+				"Ce-Time":        "2024-01-02T03:04:05Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var err error
+				header = r.Header.Clone()
+				body, err = webhook.VerifyRequest(r, webhook.DefaultTolerance, "whsec_test")
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			require.NoError(t, NewWebhook(server.URL, []string{"whsec_test"}, tt.format).Publish(context.Background(), testEvent()))
+
+			assert.Equal(t, tt.expectedContentType, header.Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, string(body))
+			for name, value := range tt.expectedHeaders {
+				assert.Equal(t, value, header.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestWebhook_PublishUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	before := testutil.ToFloat64(webhookResponsesTotal.WithLabelValues("error"))
+
+	err := NewWebhook(url, nil, config.EventFormatJSON).Publish(context.Background(), testEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to send webhook request")
+	assert.Equal(t, before+1, testutil.ToFloat64(webhookResponsesTotal.WithLabelValues("error")))
+}
+
+func TestWebhook_PublishDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := NewWebhook(server.URL, nil, config.EventFormatJSON).Publish(ctx, testEvent())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,101 @@
+package relay
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// BatchPublisher publishes a batch of pending outbox events
+type BatchPublisher interface {
+	PublishPending(ctx context.Context, limit int) (*models.PublishResponse, error)
+}
+
+// Relay drains pending events from the outbox on a fixed interval
+type Relay struct {
+	publisher BatchPublisher
+	interval  time.Duration
+	batchSize int
+
+	// stopping is closed by Stop; stopOnce guards the close
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a new relay
+func New(publisher BatchPublisher, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+		stopping:  make(chan struct{}),
+	}
+}
+
+// Run polls for pending events until Stop is called or ctx is cancelled. A
+// batch that is being published is passed ctx too, so cancelling ctx stops it
+// at the next event and Run returns once its in-flight deliveries have been
+// cancelled and recorded; Stop lets it finish first.
+func (r *Relay) Run(ctx context.Context) {
+	log.Printf("Relay started (interval=%s, batch_size=%d)", r.interval, r.batchSize)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Relay stopped")
+			return
+		case <-r.stopping:
+			log.Printf("Relay stopped")
+			return
+		case <-ticker.C:
+			r.drain(ctx)
+		}
+	}
+}
+
+// Stop stops Run from claiming further batches. The batch in progress, if
+// any, is published to the end; cancel Run's context to cut it short.
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopping)
+	})
+}
+
+// stopped reports whether Stop has been called
+func (r *Relay) stopped() bool {
+	select {
+	case <-r.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// drain publishes batches back to back until a batch claims fewer events
+// than it asked for, so a backlog is cleared without waiting a full interval
+// per batch
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil && !r.stopped() {
+		response, err := r.publisher.PublishPending(ctx, r.batchSize)
+		if err != nil {
+			log.Printf("Relay: failed to publish pending events: %v", err)
+			return
+		}
+
+		if response.Published+response.Failed > 0 {
+			log.Printf("Relay: published %d, failed %d", response.Published, response.Failed)
+		}
+
+		// A full batch means more may be waiting, even if none of it was
+		// published or failed (deferred, blocked or skipped events)
+		if response.Claimed < r.batchSize {
+			return
+		}
+	}
+}
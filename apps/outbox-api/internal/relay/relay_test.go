@@ -0,0 +1,173 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePublisher returns queued responses in order, then empty batches
+type fakePublisher struct {
+	mutex     sync.Mutex
+	responses []*models.PublishResponse
+	err       error
+	calls     int
+	limits    []int
+}
+
+func (f *fakePublisher) PublishPending(_ context.Context, limit int) (*models.PublishResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.calls++
+	f.limits = append(f.limits, limit)
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	if len(f.responses) == 0 {
+		return &models.PublishResponse{}, nil
+	}
+
+	response := f.responses[0]
+	f.responses = f.responses[1:]
+	return response, nil
+}
+
+func (f *fakePublisher) callCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.calls
+}
+
+func TestRelay_DrainPublishesUntilShortBatch(t *testing.T) {
+	publisher := &fakePublisher{
+		responses: []*models.PublishResponse{
+			{Claimed: 2, Published: 2, Failed: 0},
+			{Claimed: 2, Published: 1, Failed: 1},
+			{Claimed: 1, Published: 1, Failed: 0},
+		},
+	}
+	r := New(publisher, time.Minute, 2)
+
+	r.drain(context.Background())
+
+	assert.Equal(t, 3, publisher.callCount())
+	assert.Equal(t, []int{2, 2, 2}, publisher.limits)
+}
+
+func TestRelay_DrainContinuesPastHeldBackBatch(t *testing.T) {
+	publisher := &fakePublisher{
+		responses: []*models.PublishResponse{
+			// Nothing published or failed, but the batch was full
+			{Claimed: 3, Deferred: 1, Blocked: 2},
+			{Claimed: 1, Published: 1},
+		},
+	}
+	r := New(publisher, time.Minute, 3)
+
+	r.drain(context.Background())
+
+	assert.Equal(t, 2, publisher.callCount())
+}
+
+func TestRelay_DrainStopsOnError(t *testing.T) {
+	publisher := &fakePublisher{err: errors.New("database unavailable")}
+	r := New(publisher, time.Minute, 10)
+
+	r.drain(context.Background())
+
+	assert.Equal(t, 1, publisher.callCount())
+}
+
+func TestRelay_DrainStopsWhenContextCancelled(t *testing.T) {
+	publisher := &fakePublisher{
+		responses: []*models.PublishResponse{
+			{Claimed: 5, Published: 5},
+			{Claimed: 5, Published: 5},
+		},
+	}
+	r := New(publisher, time.Minute, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.drain(ctx)
+
+	assert.Equal(t, 0, publisher.callCount())
+}
+
+func TestRelay_RunPollsOnIntervalAndStops(t *testing.T) {
+	publisher := &fakePublisher{}
+	r := New(publisher, 10*time.Millisecond, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return publisher.callCount() >= 2
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop after context was cancelled")
+	}
+}
+
+// blockingPublisher holds each batch until release is closed
+type blockingPublisher struct {
+	started chan struct{}
+	release chan struct{}
+	calls   int
+}
+
+func (b *blockingPublisher) PublishPending(ctx context.Context, limit int) (*models.PublishResponse, error) {
+	b.calls++
+	if b.calls == 1 {
+		close(b.started)
+	}
+	<-b.release
+	// A full batch, so only Stop keeps the relay from claiming another
+	return &models.PublishResponse{Claimed: limit, Published: limit}, nil
+}
+
+func TestRelay_StopFinishesBatchInProgress(t *testing.T) {
+	publisher := &blockingPublisher{started: make(chan struct{}), release: make(chan struct{})}
+	r := New(publisher, 10*time.Millisecond, 5)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(context.Background())
+	}()
+
+	<-publisher.started
+	r.Stop()
+	r.Stop()
+
+	select {
+	case <-done:
+		t.Fatal("relay stopped before the batch in progress finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(publisher.release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop after the batch in progress finished")
+	}
+	assert.Equal(t, 1, publisher.calls)
+}
@@ -0,0 +1,62 @@
+package retention
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// FileArchive appends events to gzipped NDJSON files, one file per status
+// per UTC day. Each batch is written as its own gzip member, which gzip
+// readers such as zcat concatenate transparently.
+type FileArchive struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileArchive creates an archive writing to dir
+func NewFileArchive(dir string) *FileArchive {
+	return &FileArchive{dir: dir, now: time.Now}
+}
+
+// Path returns the file events in status are archived to today
+func (a *FileArchive) Path(status models.EventStatus) string {
+	name := fmt.Sprintf("outbox-events-%s-%s.ndjson.gz", status, a.now().UTC().Format("2006-01-02"))
+	return filepath.Join(a.dir, name)
+}
+
+// Write appends events to the archive and syncs the file, so they are on
+// disk before the caller deletes them from the database
+func (a *FileArchive) Write(status models.EventStatus, events []models.Event) error {
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	file, err := os.OpenFile(a.Path(status), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive file: %w", err)
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return fmt.Errorf("failed to write event %s: %w", events[i].ID, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive file: %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,60 @@
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readArchive returns the IDs of the events in an archive file, in order
+func readArchive(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var ids []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var event models.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ID)
+	}
+	require.NoError(t, scanner.Err())
+
+	return ids
+}
+
+func TestFileArchive_Path(t *testing.T) {
+	archive := NewFileArchive("/var/lib/outbox")
+	archive.now = func() time.Time { return time.Date(2024, 6, 1, 23, 30, 0, 0, time.FixedZone("PDT", -7*3600)) }
+
+	assert.Equal(t, filepath.Join("/var/lib/outbox", "outbox-events-published-2024-06-02.ndjson.gz"), archive.Path(models.StatusPublished))
+}
+
+func TestFileArchive_WriteAppends(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	archive := NewFileArchive(dir)
+
+	require.NoError(t, archive.Write(models.StatusFailed, []models.Event{
+		{ID: "event-1", Type: "order.created", Data: json.RawMessage(`{"order_id":"1"}`)},
+		{ID: "event-2", Type: "order.created", Data: json.RawMessage(`{"order_id":"2"}`)},
+	}))
+	require.NoError(t, archive.Write(models.StatusFailed, []models.Event{
+		{ID: "event-3", Type: "order.created", Data: json.RawMessage(`{"order_id":"3"}`)},
+	}))
+
+	assert.Equal(t, []string{"event-1", "event-2", "event-3"}, readArchive(t, archive.Path(models.StatusFailed)))
+}
@@ -0,0 +1,40 @@
+package retention
+
+import (
+	observability "github.com/jared-scarr/portfolio-monorepo/packages/observability/handlers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	prunedEventsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_retention_pruned_events_total",
+			Help: "Events removed from outbox_events by the retention janitor",
+		},
+		[]string{"status", "mode"},
+	)
+
+	// pruneErrorsTotal counts change log failures with status "changes"
+	pruneErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_retention_errors_total",
+			Help: "Retention janitor batches that failed",
+		},
+		[]string{"status", "mode"},
+	)
+
+	prunedChangesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "outbox_retention_pruned_changes_total",
+			Help: "Event changes removed from outbox_event_changes by the retention janitor",
+		},
+	)
+)
+
+func init() {
+	observability.MustRegister(
+		prunedEventsTotal,
+		pruneErrorsTotal,
+		prunedChangesTotal,
+	)
+}
@@ -0,0 +1,180 @@
+// Package retention keeps outbox_events from growing without bound by
+// pruning published, failed and expired events once they are older than the
+// configured retention period. The event change log behind the event stream
+// is pruned the same way.
+package retention
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// Store removes expired events in batches
+type Store interface {
+	PruneEvents(ctx context.Context, status models.EventStatus, before time.Time, limit int) (int, error)
+	ArchiveEvents(ctx context.Context, status models.EventStatus, before time.Time, limit int) (int, error)
+	ExportEvents(ctx context.Context, status models.EventStatus, before time.Time, limit int, export func([]models.Event) error) (int, error)
+	PruneEventChanges(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// Rule expires events in Status once MaxAge has passed since their last update
+type Rule struct {
+	Status models.EventStatus
+	MaxAge time.Duration
+}
+
+// Janitor periodically prunes expired events
+type Janitor struct {
+	store     Store
+	rules     []Rule
+	interval  time.Duration
+	batchSize int
+	mode      string
+	files     *FileArchive
+	// changesMaxAge is how long the event change log is kept; zero keeps it
+	changesMaxAge time.Duration
+	now           func() time.Time
+}
+
+// New creates a janitor enforcing cfg
+func New(store Store, cfg config.RetentionConfig) *Janitor {
+	j := &Janitor{
+		store:     store,
+		interval:  cfg.PruneInterval(),
+		batchSize: cfg.BatchSize,
+		mode:      cfg.Mode,
+		now:       time.Now,
+
+		changesMaxAge: cfg.ChangesMaxAge(),
+	}
+
+	published, failed, expired := cfg.MaxAges()
+	if published > 0 {
+		j.rules = append(j.rules, Rule{Status: models.StatusPublished, MaxAge: published})
+	}
+	if failed > 0 {
+		j.rules = append(j.rules, Rule{Status: models.StatusFailed, MaxAge: failed})
+	}
+	if expired > 0 {
+		j.rules = append(j.rules, Rule{Status: models.StatusExpired, MaxAge: expired})
+	}
+
+	if j.batchSize < 1 {
+		j.batchSize = 1000
+	}
+	if j.mode != config.RetentionModeArchive && j.mode != config.RetentionModeNDJSON {
+		j.mode = config.RetentionModeDelete
+	}
+	if j.mode == config.RetentionModeNDJSON {
+		j.files = NewFileArchive(cfg.ArchiveDir)
+	}
+
+	return j
+}
+
+// Enabled reports whether any status, or the change log, has a retention
+// period
+func (j *Janitor) Enabled() bool {
+	return len(j.rules) > 0 || j.changesMaxAge > 0
+}
+
+// Run prunes expired events on every interval until ctx is cancelled
+func (j *Janitor) Run(ctx context.Context) {
+	log.Printf("Retention janitor started (interval=%s, batch_size=%d, mode=%s)", j.interval, j.batchSize, j.mode)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Retention janitor stopped")
+			return
+		case <-ticker.C:
+			j.Prune(ctx)
+		}
+	}
+}
+
+// Prune removes every currently expired event, one batch at a time so no
+// statement holds locks on many rows, and returns how many were removed
+func (j *Janitor) Prune(ctx context.Context) int {
+	total := 0
+	for _, rule := range j.rules {
+		cutoff := j.now().Add(-rule.MaxAge)
+		pruned := 0
+
+		for ctx.Err() == nil {
+			n, err := j.pruneBatch(ctx, rule.Status, cutoff)
+			if err != nil {
+				pruneErrorsTotal.WithLabelValues(string(rule.Status), j.mode).Inc()
+				log.Printf("Retention: failed to prune %s events: %v", rule.Status, err)
+				break
+			}
+
+			prunedEventsTotal.WithLabelValues(string(rule.Status), j.mode).Add(float64(n))
+			pruned += n
+
+			if n < j.batchSize {
+				break
+			}
+		}
+
+		if pruned > 0 {
+			log.Printf("Retention: pruned %d %s events older than %s (%s)", pruned, rule.Status, rule.MaxAge, j.mode)
+		}
+		total += pruned
+	}
+
+	if j.changesMaxAge > 0 {
+		j.pruneChanges(ctx)
+	}
+
+	return total
+}
+
+// pruneChanges deletes event changes older than changesMaxAge in batches.
+// Changes are always deleted, whatever the mode: they only exist so stream
+// clients can resume.
+func (j *Janitor) pruneChanges(ctx context.Context) {
+	cutoff := j.now().Add(-j.changesMaxAge)
+	pruned := 0
+
+	for ctx.Err() == nil {
+		n, err := j.store.PruneEventChanges(ctx, cutoff, j.batchSize)
+		if err != nil {
+			pruneErrorsTotal.WithLabelValues("changes", config.RetentionModeDelete).Inc()
+			log.Printf("Retention: failed to prune event changes: %v", err)
+			return
+		}
+
+		prunedChangesTotal.Add(float64(n))
+		pruned += n
+
+		if n < j.batchSize {
+			break
+		}
+	}
+
+	if pruned > 0 {
+		log.Printf("Retention: pruned %d event changes older than %s", pruned, j.changesMaxAge)
+	}
+}
+
+// pruneBatch removes one batch of expired events according to the mode
+func (j *Janitor) pruneBatch(ctx context.Context, status models.EventStatus, cutoff time.Time) (int, error) {
+	switch j.mode {
+	case config.RetentionModeArchive:
+		return j.store.ArchiveEvents(ctx, status, cutoff, j.batchSize)
+	case config.RetentionModeNDJSON:
+		return j.store.ExportEvents(ctx, status, cutoff, j.batchSize, func(events []models.Event) error {
+			return j.files.Write(status, events)
+		})
+	default:
+		return j.store.PruneEvents(ctx, status, cutoff, j.batchSize)
+	}
+}
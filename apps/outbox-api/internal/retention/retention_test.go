@@ -0,0 +1,188 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pruneCall records one batch the janitor asked the store to remove
+type pruneCall struct {
+	method string
+	status models.EventStatus
+	before time.Time
+	limit  int
+}
+
+// fakeStore returns queued batch sizes per status, then empty batches.
+// Change log batches are queued under the empty status.
+type fakeStore struct {
+	batches  map[models.EventStatus][]int
+	exported []models.Event
+	err      error
+	calls    []pruneCall
+}
+
+func (f *fakeStore) next(method string, status models.EventStatus, before time.Time, limit int) (int, error) {
+	f.calls = append(f.calls, pruneCall{method: method, status: status, before: before, limit: limit})
+	if f.err != nil {
+		return 0, f.err
+	}
+	if len(f.batches[status]) == 0 {
+		return 0, nil
+	}
+	n := f.batches[status][0]
+	f.batches[status] = f.batches[status][1:]
+	return n, nil
+}
+
+func (f *fakeStore) PruneEvents(_ context.Context, status models.EventStatus, before time.Time, limit int) (int, error) {
+	return f.next("prune", status, before, limit)
+}
+
+func (f *fakeStore) ArchiveEvents(_ context.Context, status models.EventStatus, before time.Time, limit int) (int, error) {
+	return f.next("archive", status, before, limit)
+}
+
+func (f *fakeStore) ExportEvents(_ context.Context, status models.EventStatus, before time.Time, limit int, export func([]models.Event) error) (int, error) {
+	n, err := f.next("export", status, before, limit)
+	if err != nil || n == 0 {
+		return n, err
+	}
+	if err := export(f.exported[:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (f *fakeStore) PruneEventChanges(_ context.Context, before time.Time, limit int) (int, error) {
+	return f.next("prune_changes", "", before, limit)
+}
+
+func TestNew_Rules(t *testing.T) {
+	janitor := New(&fakeStore{}, config.RetentionConfig{Published: "168h", Failed: "720h", Expired: "24h"})
+	assert.True(t, janitor.Enabled())
+	assert.Equal(t, []Rule{
+		{Status: models.StatusPublished, MaxAge: 168 * time.Hour},
+		{Status: models.StatusFailed, MaxAge: 720 * time.Hour},
+		{Status: models.StatusExpired, MaxAge: 24 * time.Hour},
+	}, janitor.rules)
+	assert.Equal(t, 1000, janitor.batchSize)
+
+	assert.False(t, New(&fakeStore{}, config.RetentionConfig{}).Enabled())
+	assert.True(t, New(&fakeStore{}, config.RetentionConfig{Changes: "24h"}).Enabled())
+}
+
+func TestJanitor_PruneInBatches(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{batches: map[models.EventStatus][]int{
+		models.StatusPublished: {2, 2, 1},
+		models.StatusFailed:    {2},
+	}}
+	janitor := New(store, config.RetentionConfig{Published: "24h", Failed: "48h", BatchSize: 2, Mode: config.RetentionModeDelete})
+	janitor.now = func() time.Time { return now }
+
+	before := testutil.ToFloat64(prunedEventsTotal.WithLabelValues("published", config.RetentionModeDelete))
+
+	pruned := janitor.Prune(context.Background())
+
+	assert.Equal(t, 7, pruned)
+	assert.Equal(t, []pruneCall{
+		{method: "prune", status: models.StatusPublished, before: now.Add(-24 * time.Hour), limit: 2},
+		{method: "prune", status: models.StatusPublished, before: now.Add(-24 * time.Hour), limit: 2},
+		{method: "prune", status: models.StatusPublished, before: now.Add(-24 * time.Hour), limit: 2},
+		{method: "prune", status: models.StatusFailed, before: now.Add(-48 * time.Hour), limit: 2},
+		{method: "prune", status: models.StatusFailed, before: now.Add(-48 * time.Hour), limit: 2},
+	}, store.calls)
+	assert.Equal(t, before+5, testutil.ToFloat64(prunedEventsTotal.WithLabelValues("published", config.RetentionModeDelete)))
+}
+
+func TestJanitor_PruneChanges(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{batches: map[models.EventStatus][]int{
+		models.StatusPublished: {1},
+		"":                     {2, 1},
+	}}
+	janitor := New(store, config.RetentionConfig{Published: "24h", Changes: "1h", BatchSize: 2, Mode: config.RetentionModeArchive})
+	janitor.now = func() time.Time { return now }
+
+	before := testutil.ToFloat64(prunedChangesTotal)
+
+	// Only events count towards the total
+	assert.Equal(t, 1, janitor.Prune(context.Background()))
+	assert.Equal(t, []pruneCall{
+		{method: "archive", status: models.StatusPublished, before: now.Add(-24 * time.Hour), limit: 2},
+		{method: "prune_changes", before: now.Add(-time.Hour), limit: 2},
+		{method: "prune_changes", before: now.Add(-time.Hour), limit: 2},
+	}, store.calls)
+	assert.Equal(t, before+3, testutil.ToFloat64(prunedChangesTotal))
+}
+
+func TestJanitor_PruneArchives(t *testing.T) {
+	store := &fakeStore{batches: map[models.EventStatus][]int{models.StatusPublished: {3}}}
+	janitor := New(store, config.RetentionConfig{Published: "24h", BatchSize: 10, Mode: config.RetentionModeArchive})
+
+	assert.Equal(t, 3, janitor.Prune(context.Background()))
+	require.Len(t, store.calls, 1)
+	assert.Equal(t, "archive", store.calls[0].method)
+}
+
+func TestJanitor_PruneExportsToFiles(t *testing.T) {
+	dir := t.TempDir()
+	store := &fakeStore{
+		batches:  map[models.EventStatus][]int{models.StatusPublished: {2}},
+		exported: []models.Event{{ID: "event-1"}, {ID: "event-2"}},
+	}
+	janitor := New(store, config.RetentionConfig{Published: "24h", BatchSize: 10, Mode: config.RetentionModeNDJSON, ArchiveDir: dir})
+
+	assert.Equal(t, 2, janitor.Prune(context.Background()))
+	assert.Equal(t, []string{"event-1", "event-2"}, readArchive(t, janitor.files.Path(models.StatusPublished)))
+}
+
+func TestJanitor_PruneStopsOnError(t *testing.T) {
+	store := &fakeStore{err: errors.New("database unavailable")}
+	janitor := New(store, config.RetentionConfig{Published: "24h", Failed: "48h", Mode: config.RetentionModeDelete})
+
+	before := testutil.ToFloat64(pruneErrorsTotal.WithLabelValues("published", config.RetentionModeDelete))
+
+	assert.Equal(t, 0, janitor.Prune(context.Background()))
+	// One failed batch per status; the next status is still attempted
+	assert.Len(t, store.calls, 2)
+	assert.Equal(t, before+1, testutil.ToFloat64(pruneErrorsTotal.WithLabelValues("published", config.RetentionModeDelete)))
+}
+
+func TestJanitor_PruneStopsWhenContextCancelled(t *testing.T) {
+	store := &fakeStore{batches: map[models.EventStatus][]int{models.StatusPublished: {5, 5}}}
+	janitor := New(store, config.RetentionConfig{Published: "24h", BatchSize: 5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, 0, janitor.Prune(ctx))
+	assert.Empty(t, store.calls)
+}
+
+func TestJanitor_RunStopsWhenContextCancelled(t *testing.T) {
+	janitor := New(&fakeStore{}, config.RetentionConfig{Published: "24h", Interval: "1ms"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		janitor.Run(ctx)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not stop")
+	}
+}
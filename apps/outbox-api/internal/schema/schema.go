@@ -0,0 +1,85 @@
+// Package schema compiles and applies the JSON Schemas registered for event
+// types. Schemas default to draft 2020-12 when they do not declare $schema.
+// References are resolved within the schema only; nothing is fetched from
+// the network or the filesystem.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaURL names the schema being compiled; it only needs to be unique
+// within a compiler
+const schemaURL = "urn:outbox:schema"
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	compiled *jsonschema.Schema
+}
+
+// noLoader refuses to load external references
+type noLoader struct{}
+
+func (noLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("external schema references are not supported: %s", url)
+}
+
+// Compile parses and compiles a JSON Schema document
+func Compile(raw json.RawMessage) (*Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON in schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(noLoader{})
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	return &Schema{compiled: compiled}, nil
+}
+
+// Validate checks data against the schema and returns every violation, or
+// none when the data is valid
+func (s *Schema) Validate(data json.RawMessage) ([]models.SchemaViolation, error) {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON in data field: %w", err)
+	}
+
+	err = s.compiled.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, fmt.Errorf("failed to validate data: %w", err)
+	}
+
+	var violations []models.SchemaViolation
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		violations = append(violations, models.SchemaViolation{
+			Pointer: unit.InstanceLocation,
+			Message: unit.Error.String(),
+		})
+	}
+
+	return violations, nil
+}
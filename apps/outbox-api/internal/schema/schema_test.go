@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["order_id", "items"],
+	"properties": {
+		"order_id": {"type": "string"},
+		"total": {"type": "number", "minimum": 0},
+		"items": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["sku"],
+				"properties": {"sku": {"type": "string"}}
+			}
+		}
+	}
+}`
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name          string
+		schema        string
+		expectedError string
+	}{
+		{name: "valid schema", schema: orderSchema},
+		{name: "declared draft", schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`},
+		{name: "boolean schema", schema: `true`},
+		{name: "not JSON", schema: `{"type":`, expectedError: "invalid JSON in schema"},
+		{name: "invalid keyword value", schema: `{"type": "invoice"}`, expectedError: "invalid schema"},
+		{name: "external reference", schema: `{"$ref": "https://example.com/order.json"}`, expectedError: "invalid schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := Compile(json.RawMessage(tt.schema))
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, compiled)
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	compiled, err := Compile(json.RawMessage(orderSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		data             string
+		expectedPointers []string
+	}{
+		{
+			name: "valid data",
+			data: `{"order_id": "123", "total": 9.5, "items": [{"sku": "abc"}]}`,
+		},
+		{
+			name:             "wrong type at the root",
+			data:             `[]`,
+			expectedPointers: []string{""},
+		},
+		{
+			name:             "missing required field",
+			data:             `{"order_id": "123"}`,
+			expectedPointers: []string{""},
+		},
+		{
+			name:             "nested failures",
+			data:             `{"order_id": 123, "total": -1, "items": [{"sku": "abc"}, {"sku": 7}]}`,
+			expectedPointers: []string{"/order_id", "/total", "/items/1/sku"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := compiled.Validate(json.RawMessage(tt.data))
+			require.NoError(t, err)
+
+			var pointers []string
+			for _, violation := range violations {
+				pointers = append(pointers, violation.Pointer)
+				assert.NotEmpty(t, violation.Message)
+			}
+			assert.ElementsMatch(t, tt.expectedPointers, pointers)
+		})
+	}
+}
+
+func TestSchema_ValidateMessages(t *testing.T) {
+	compiled, err := Compile(json.RawMessage(orderSchema))
+	require.NoError(t, err)
+
+	violations, err := compiled.Validate(json.RawMessage(`{"items": []}`))
+	require.NoError(t, err)
+	assert.Equal(t, []models.SchemaViolation{
+		{Pointer: "", Message: "missing property 'order_id'"},
+	}, violations)
+}
+
+func TestSchema_ValidateInvalidJSON(t *testing.T) {
+	compiled, err := Compile(json.RawMessage(`true`))
+	require.NoError(t, err)
+
+	_, err = compiled.Validate(json.RawMessage(`{"order_id":`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid JSON in data field")
+}
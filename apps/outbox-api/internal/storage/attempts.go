@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// RecordAttempt stores one delivery attempt of an event
+func (s *OutboxStore) RecordAttempt(ctx context.Context, attempt *models.DeliveryAttempt) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	var statusCode interface{}
+	if attempt.StatusCode != 0 {
+		statusCode = attempt.StatusCode
+	}
+
+	query := `
+		INSERT INTO outbox_delivery_attempts (event_id, subscription_id, destination, attempted_at, duration_ms, status_code, response_body, error, error_class)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := s.db.conn.ExecContext(ctx, query, attempt.EventID, nullString(attempt.SubscriptionID), attempt.Destination, attempt.AttemptedAt,
+		attempt.DurationMs, statusCode, nullString(attempt.ResponseBody), nullString(attempt.Error), nullString(attempt.ErrorClass))
+	if err != nil {
+		return fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttempts retrieves an event's delivery attempts, oldest first
+func (s *OutboxStore) ListAttempts(ctx context.Context, eventID string) ([]models.DeliveryAttempt, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, event_id, subscription_id, destination, attempted_at, duration_ms, status_code, response_body, error, error_class
+		FROM outbox_delivery_attempts
+		WHERE event_id = $1
+		ORDER BY attempted_at, id
+	`
+
+	rows, err := s.db.conn.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []models.DeliveryAttempt
+	for rows.Next() {
+		var attempt models.DeliveryAttempt
+		var subscriptionID, responseBody, errorStr, errorClass sql.NullString
+		var statusCode sql.NullInt64
+		err := rows.Scan(&attempt.ID, &attempt.EventID, &subscriptionID, &attempt.Destination, &attempt.AttemptedAt,
+			&attempt.DurationMs, &statusCode, &responseBody, &errorStr, &errorClass)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery attempt: %w", err)
+		}
+
+		attempt.SubscriptionID = subscriptionID.String
+		attempt.StatusCode = int(statusCode.Int64)
+		attempt.ResponseBody = responseBody.String
+		attempt.Error = errorStr.String
+		attempt.ErrorClass = errorClass.String
+
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read delivery attempts: %w", err)
+	}
+
+	return attempts, nil
+}
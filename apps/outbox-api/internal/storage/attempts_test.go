@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxStore_RecordAttempt(t *testing.T) {
+	query := `INSERT INTO outbox_delivery_attempts (event_id, subscription_id, destination, attempted_at, duration_ms, status_code, response_body, error, error_class)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		attempt       models.DeliveryAttempt
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "records a failed webhook attempt",
+			attempt: models.DeliveryAttempt{
+				EventID:        "event-1",
+				SubscriptionID: "sub-1",
+				Destination:    "https://billing.example.com/hooks",
+				AttemptedAt:    now,
+				DurationMs:     42,
+				StatusCode:     503,
+				ResponseBody:   "upstream unavailable",
+				Error:          "webhook returned status 503",
+				ErrorClass:     models.ErrorClassServerError,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("event-1", "sub-1", "https://billing.example.com/hooks", now, int64(42), 503, "upstream unavailable", "webhook returned status 503", "http_5xx").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "stores empty fields as NULL",
+			attempt: models.DeliveryAttempt{
+				EventID:     "event-1",
+				Destination: "kafka://localhost:9092/events",
+				AttemptedAt: now,
+				DurationMs:  3,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("event-1", nil, "kafka://localhost:9092/events", now, int64(3), nil, nil, nil, nil).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+			},
+		},
+		{
+			name:    "database error",
+			attempt: models.DeliveryAttempt{EventID: "event-1", Destination: "https://billing.example.com/hooks", AttemptedAt: now},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to record delivery attempt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			err := store.RecordAttempt(context.Background(), &tt.attempt)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_ListAttempts(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, event_id, subscription_id, destination, attempted_at, duration_ms, status_code, response_body, error, error_class
+		FROM outbox_delivery_attempts
+		WHERE event_id = $1
+		ORDER BY attempted_at, id`).
+		WithArgs("event-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "subscription_id", "destination", "attempted_at", "duration_ms", "status_code", "response_body", "error", "error_class"}).
+			AddRow(1, "event-1", "sub-1", "https://billing.example.com/hooks", now.Add(-time.Minute), 30000, nil, nil, "context deadline exceeded", "timeout").
+			AddRow(2, "event-1", "sub-1", "https://billing.example.com/hooks", now, 120, 200, "ok", nil, nil))
+
+	attempts, err := store.ListAttempts(context.Background(), "event-1")
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+
+	assert.Equal(t, "sub-1", attempts[0].SubscriptionID)
+	assert.Equal(t, 0, attempts[0].StatusCode)
+	assert.Equal(t, models.ErrorClassTimeout, attempts[0].ErrorClass)
+	assert.False(t, attempts[0].Succeeded())
+
+	assert.Equal(t, int64(120), attempts[1].DurationMs)
+	assert.Equal(t, 200, attempts[1].StatusCode)
+	assert.Equal(t, "ok", attempts[1].ResponseBody)
+	assert.True(t, attempts[1].Succeeded())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ListAttempts_DatabaseError(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+	mock.ExpectQuery(`SELECT id, event_id, subscription_id, destination, attempted_at, duration_ms, status_code, response_body, error, error_class
+		FROM outbox_delivery_attempts
+		WHERE event_id = $1
+		ORDER BY attempted_at, id`).
+		WillReturnError(sql.ErrConnDone)
+
+	_, err := store.ListAttempts(context.Background(), "event-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list delivery attempts")
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// bulkJobColumns lists the columns read back for every bulk job query
+const bulkJobColumns = "id, action, event_ids, filter, status, matched, processed, succeeded, failed, error, created_at, updated_at, completed_at"
+
+// bulkActionCondition restricts a bulk action to events that are still
+// eligible for it and not leased by a publisher
+const bulkActionCondition = `id = ANY($1) AND status = ANY($2)
+		AND (locked_until IS NULL OR locked_until < NOW())`
+
+// CountEvents counts the events matching query's filters
+func (s *OutboxStore) CountEvents(ctx context.Context, query *models.EventQuery) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	filter := newEventFilter(query)
+
+	var total int
+	err := s.db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM outbox_events "+filter.where(), filter.args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+
+	return total, nil
+}
+
+// ListEventIDs returns up to limit IDs of events matching query's filters, in
+// ID order starting after afterID, so a large selection can be walked in
+// batches while it is being modified
+func (s *OutboxStore) ListEventIDs(ctx context.Context, query *models.EventQuery, afterID string, limit int) ([]string, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	filter := newEventFilter(query)
+	if afterID != "" {
+		filter.add("id > %s", afterID)
+	}
+
+	listQuery := "SELECT id FROM outbox_events " + filter.where() + " ORDER BY id LIMIT " + filter.arg(limit)
+
+	rows, err := s.db.conn.QueryContext(ctx, listQuery, filter.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan event ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ApplyBulkAction deletes, fails or requeues the given events on behalf of a
+// bulk job and returns how many were changed. Events no longer eligible for
+// the action are left alone. Retries publish each event and are not applied
+// here.
+func (s *OutboxStore) ApplyBulkAction(ctx context.Context, jobID string, action models.BulkAction, ids []string) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	statuses := make([]string, 0, len(action.EligibleStatuses()))
+	for _, status := range action.EligibleStatuses() {
+		statuses = append(statuses, string(status))
+	}
+	args := []interface{}{pq.Array(ids), pq.Array(statuses)}
+
+	var query string
+	switch action {
+	case models.BulkActionDelete:
+		query = "DELETE FROM outbox_events WHERE " + bulkActionCondition
+	case models.BulkActionMarkFailed:
+		query = `
+		UPDATE outbox_events
+		SET status = $3, last_error = $4, next_attempt_at = NULL, updated_at = NOW()
+		WHERE ` + bulkActionCondition
+		args = append(args, models.StatusFailed, "marked failed by bulk job "+jobID)
+	case models.BulkActionRequeue:
+		// A requeued event is delivered afresh to every subscriber, including
+		// those that received it already, so its delivery status is cleared
+		query = `
+		WITH cleared AS (
+			DELETE FROM outbox_deliveries
+			WHERE event_id IN (SELECT id FROM outbox_events WHERE ` + bulkActionCondition + `)
+		)
+		UPDATE outbox_events
+		SET status = $3, retry_count = 0, last_error = NULL, published_at = NULL, next_attempt_at = NULL, expires_at = NULL, updated_at = NOW()
+		WHERE ` + bulkActionCondition
+		args = append(args, models.StatusPending)
+	default:
+		return 0, fmt.Errorf("unsupported bulk action: %s", action)
+	}
+
+	result, err := s.db.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply bulk action %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// scanBulkJob scans a row selected with bulkJobColumns into a bulk job
+func scanBulkJob(row rowScanner) (*models.BulkJob, error) {
+	var job models.BulkJob
+	var filter []byte
+	var errorStr sql.NullString
+	var completedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Action, pq.Array(&job.EventIDs), &filter, &job.Status, &job.Matched, &job.Processed,
+		&job.Succeeded, &job.Failed, &errorStr, &job.CreatedAt, &job.UpdatedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filter) > 0 {
+		if err := json.Unmarshal(filter, &job.Filter); err != nil {
+			return nil, fmt.Errorf("failed to decode bulk job filter: %w", err)
+		}
+	}
+	job.Error = errorStr.String
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	return &job, nil
+}
+
+// CreateBulkJob stores a new bulk job
+func (s *OutboxStore) CreateBulkJob(ctx context.Context, job *models.BulkJob) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	var filter interface{}
+	if job.Filter != nil {
+		encoded, err := json.Marshal(job.Filter)
+		if err != nil {
+			return fmt.Errorf("failed to encode bulk job filter: %w", err)
+		}
+		filter = encoded
+	}
+
+	var eventIDs interface{}
+	if len(job.EventIDs) > 0 {
+		eventIDs = pq.Array(job.EventIDs)
+	}
+
+	query := `
+		INSERT INTO outbox_bulk_jobs (id, action, event_ids, filter, status, matched, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := s.db.conn.ExecContext(ctx, query, job.ID, job.Action, eventIDs, filter, job.Status, job.Matched, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bulk job: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBulkJob saves a bulk job's status and progress
+func (s *OutboxStore) UpdateBulkJob(ctx context.Context, job *models.BulkJob) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	job.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE outbox_bulk_jobs
+		SET status = $1, processed = $2, succeeded = $3, failed = $4, error = $5, updated_at = $6, completed_at = $7
+		WHERE id = $8
+	`
+
+	_, err := s.db.conn.ExecContext(ctx, query, job.Status, job.Processed, job.Succeeded, job.Failed, nullString(job.Error),
+		job.UpdatedAt, job.CompletedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update bulk job: %w", err)
+	}
+
+	return nil
+}
+
+// GetBulkJob retrieves a bulk job by ID
+func (s *OutboxStore) GetBulkJob(ctx context.Context, id string) (*models.BulkJob, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := "SELECT " + bulkJobColumns + " FROM outbox_bulk_jobs WHERE id = $1"
+
+	job, err := scanBulkJob(s.db.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("bulk job not found")
+		}
+		return nil, fmt.Errorf("failed to get bulk job: %w", err)
+	}
+
+	return job, nil
+}
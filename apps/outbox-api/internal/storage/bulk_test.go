@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxStore_CountEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM outbox_events WHERE id = ANY($1) AND status = ANY($2)").
+		WithArgs(pq.Array([]string{"event-1", "event-2"}), pq.Array([]string{"failed"})).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	store := NewOutboxStore(db)
+	total, err := store.CountEvents(context.Background(), &models.EventQuery{
+		IDs:      []string{"event-1", "event-2"},
+		Statuses: []models.EventStatus{models.StatusFailed},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ListEventIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		afterID   string
+		mockSetup func(sqlmock.Sqlmock)
+		expected  []string
+	}{
+		{
+			name: "first batch",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id FROM outbox_events WHERE source = $1 ORDER BY id LIMIT $2").
+					WithArgs("order-service", 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("event-1").AddRow("event-2"))
+			},
+			expected: []string{"event-1", "event-2"},
+		},
+		{
+			name:    "after the previous batch",
+			afterID: "event-2",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id FROM outbox_events WHERE source = $1 AND id > $2 ORDER BY id LIMIT $3").
+					WithArgs("order-service", "event-2", 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			ids, err := store.ListEventIDs(context.Background(), &models.EventQuery{Source: "order-service"}, tt.afterID, 2)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_ApplyBulkAction(t *testing.T) {
+	ids := []string{"event-1", "event-2"}
+	condition := `id = ANY($1) AND status = ANY($2)
+		AND (locked_until IS NULL OR locked_until < NOW())`
+
+	tests := []struct {
+		name          string
+		action        models.BulkAction
+		mockSetup     func(sqlmock.Sqlmock)
+		expected      int
+		expectedError string
+	}{
+		{
+			name:   "delete",
+			action: models.BulkActionDelete,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM outbox_events WHERE "+condition).
+					WithArgs(pq.Array(ids), pq.Array([]string{"pending", "published", "failed", "retrying", "expired"})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			expected: 2,
+		},
+		{
+			name:   "mark failed",
+			action: models.BulkActionMarkFailed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE outbox_events
+		SET status = $3, last_error = $4, next_attempt_at = NULL, updated_at = NOW()
+		WHERE `+condition).
+					WithArgs(pq.Array(ids), pq.Array([]string{"pending", "retrying"}), "failed", "marked failed by bulk job job-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected: 1,
+		},
+		{
+			name:   "requeue as pending",
+			action: models.BulkActionRequeue,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`WITH cleared AS (
+			DELETE FROM outbox_deliveries
+			WHERE event_id IN (SELECT id FROM outbox_events WHERE `+condition+`)
+		)
+		UPDATE outbox_events
+		SET status = $3, retry_count = 0, last_error = NULL, published_at = NULL, next_attempt_at = NULL, expires_at = NULL, updated_at = NOW()
+		WHERE `+condition).
+					WithArgs(pq.Array(ids), pq.Array([]string{"published", "failed", "retrying", "expired"}), "pending").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			expected: 2,
+		},
+		{
+			name:          "retry is not a storage action",
+			action:        models.BulkActionRetry,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "unsupported bulk action: retry",
+		},
+		{
+			name:   "database error",
+			action: models.BulkActionDelete,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM outbox_events WHERE " + condition).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to apply bulk action delete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			changed, err := store.ApplyBulkAction(context.Background(), "job-1", tt.action, ids)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, changed)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_BulkJobs(t *testing.T) {
+	now := time.Now()
+	status := models.StatusFailed
+
+	t.Run("create", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT INTO outbox_bulk_jobs (id, action, event_ids, filter, status, matched, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`).
+			WithArgs("job-1", "delete", nil, []byte(`{"status":"failed","type":"order.*"}`), "running", 12, now, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		store := NewOutboxStore(db)
+		err := store.CreateBulkJob(context.Background(), &models.BulkJob{
+			ID:        "job-1",
+			Action:    models.BulkActionDelete,
+			Filter:    &models.BulkFilter{Status: &status, Type: "order.*"},
+			Status:    models.BulkJobRunning,
+			Matched:   12,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("update", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE outbox_bulk_jobs
+		SET status = $1, processed = $2, succeeded = $3, failed = $4, error = $5, updated_at = $6, completed_at = $7
+		WHERE id = $8`).
+			WithArgs("completed", 12, 10, 0, nil, sqlmock.AnyArg(), now, "job-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		store := NewOutboxStore(db)
+		err := store.UpdateBulkJob(context.Background(), &models.BulkJob{ID: "job-1", Status: models.BulkJobCompleted, Processed: 12, Succeeded: 10, CompletedAt: &now})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("get", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT " + bulkJobColumns + " FROM outbox_bulk_jobs WHERE id = $1").
+			WithArgs("job-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "action", "event_ids", "filter", "status", "matched", "processed", "succeeded", "failed", "error", "created_at", "updated_at", "completed_at"}).
+				AddRow("job-1", "retry", "{event-1,event-2}", nil, "running", 2, 1, 1, 0, nil, now, now, nil))
+
+		store := NewOutboxStore(db)
+		job, err := store.GetBulkJob(context.Background(), "job-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.BulkActionRetry, job.Action)
+		assert.Equal(t, []string{"event-1", "event-2"}, job.EventIDs)
+		assert.Nil(t, job.Filter)
+		assert.Equal(t, 1, job.Processed)
+		assert.Nil(t, job.CompletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT " + bulkJobColumns + " FROM outbox_bulk_jobs WHERE id = $1").
+			WithArgs("job-1").
+			WillReturnError(sql.ErrNoRows)
+
+		store := NewOutboxStore(db)
+		_, err := store.GetBulkJob(context.Background(), "job-1")
+
+		assert.EqualError(t, err, "bulk job not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
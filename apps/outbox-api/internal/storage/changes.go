@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// ListEventChanges retrieves up to limit changes matching filter that were
+// recorded after afterID, oldest first
+func (s *OutboxStore) ListEventChanges(ctx context.Context, filter *models.ChangeFilter, afterID int64, limit int) ([]models.EventChange, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	f := &eventFilter{}
+	f.add("id > %s", afterID)
+	if filter.Status != nil {
+		f.add("status = %s", string(*filter.Status))
+	}
+	if filter.Type != "" {
+		f.match("type", filter.Type)
+	}
+
+	query := `
+		SELECT id, event_id, action, type, status, retry_count, changed_at
+		FROM outbox_event_changes
+		` + f.where() + `
+		ORDER BY id
+		LIMIT ` + f.arg(limit)
+
+	rows, err := s.db.conn.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []models.EventChange
+	for rows.Next() {
+		var change models.EventChange
+		err := rows.Scan(&change.ID, &change.EventID, &change.Action, &change.Type, &change.Status, &change.RetryCount, &change.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// PruneEventChanges deletes up to limit changes recorded before cutoff and
+// returns how many were deleted
+func (s *OutboxStore) PruneEventChanges(ctx context.Context, before time.Time, limit int) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		DELETE FROM outbox_event_changes
+		WHERE id IN (
+			SELECT id FROM outbox_event_changes
+			WHERE changed_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+
+	result, err := s.db.conn.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune event changes: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
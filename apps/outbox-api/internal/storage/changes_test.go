@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxStore_ListEventChanges(t *testing.T) {
+	columns := []string{"id", "event_id", "action", "type", "status", "retry_count", "changed_at"}
+	now := time.Now()
+	failed := models.StatusFailed
+
+	tests := []struct {
+		name          string
+		filter        models.ChangeFilter
+		mockSetup     func(sqlmock.Sqlmock)
+		expected      []models.EventChange
+		expectedError string
+	}{
+		{
+			name: "all changes after an ID",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, event_id, action, type, status, retry_count, changed_at
+					FROM outbox_event_changes
+					WHERE id > $1
+					ORDER BY id
+					LIMIT $2`).
+					WithArgs(int64(41), 100).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(42, "event-1", "created", "order.created", "pending", 0, now).
+						AddRow(43, "event-1", "published", "order.created", "published", 0, now))
+			},
+			expected: []models.EventChange{
+				{ID: 42, EventID: "event-1", Action: models.ChangeCreated, Type: "order.created", Status: models.StatusPending, ChangedAt: now},
+				{ID: 43, EventID: "event-1", Action: models.ChangePublished, Type: "order.created", Status: models.StatusPublished, ChangedAt: now},
+			},
+		},
+		{
+			name:   "filtered by status and type prefix",
+			filter: models.ChangeFilter{Status: &failed, Type: "order.*"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, event_id, action, type, status, retry_count, changed_at
+					FROM outbox_event_changes
+					WHERE id > $1 AND status = $2 AND type LIKE $3
+					ORDER BY id
+					LIMIT $4`).
+					WithArgs(int64(41), "failed", "order.%", 100).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(44, "event-2", "failed", "order.created", "failed", 3, now))
+			},
+			expected: []models.EventChange{
+				{ID: 44, EventID: "event-2", Action: models.ChangeFailed, Type: "order.created", Status: models.StatusFailed, RetryCount: 3, ChangedAt: now},
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, event_id, action, type, status, retry_count, changed_at
+					FROM outbox_event_changes
+					WHERE id > $1
+					ORDER BY id
+					LIMIT $2`).
+					WithArgs(int64(41), 100).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to list event changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			changes, err := store.ListEventChanges(context.Background(), &tt.filter, 41, 100)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, changes)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_PruneEventChanges(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	mock.ExpectExec(`DELETE FROM outbox_event_changes
+		WHERE id IN (
+			SELECT id FROM outbox_event_changes
+			WHERE changed_at < $1
+			ORDER BY id
+			LIMIT $2
+		)`).
+		WithArgs(cutoff, 1000).
+		WillReturnResult(sqlmock.NewResult(0, 250))
+
+	store := NewOutboxStore(db)
+	pruned, err := store.PruneEventChanges(context.Background(), cutoff, 1000)
+
+	require.NoError(t, err)
+	assert.Equal(t, 250, pruned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
 	_ "github.com/lib/pq"
@@ -11,27 +14,69 @@ import (
 // DB wraps a database connection
 type DB struct {
 	conn *sql.DB
+	// queryTimeout bounds every store operation; zero leaves it to the
+	// caller's context
+	queryTimeout time.Duration
 }
 
-// NewDB creates a new database connection
+// NewDB creates a new database connection, applying pending schema
+// migrations when cfg.AutoMigrate is set
 func NewDB(cfg config.DatabaseConfig) (*DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoMigrate {
+		if err := db.Migrate(context.Background()); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// Open connects to the database without touching the schema
+func Open(cfg config.DatabaseConfig) (*DB, error) {
 	conn, err := sql.Open("postgres", cfg.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	if err := conn.Ping(); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db := &DB{conn: conn}
+	return &DB{conn: conn, queryTimeout: cfg.QueryDeadline()}, nil
+}
 
-	// Create tables if they don't exist
-	if err := db.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
+// Migrate applies every pending schema migration
+func (db *DB) Migrate(ctx context.Context) error {
+	migrator, err := NewMigrator(db.conn)
+	if err != nil {
+		return err
 	}
 
-	return db, nil
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		log.Printf("Applied migration %s", migration)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return nil
+}
+
+// withTimeout bounds ctx by the query timeout, so a slow statement cannot
+// hold up a request or a publisher indefinitely
+func (db *DB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, db.queryTimeout)
 }
 
 // Close closes the database connection
@@ -43,30 +88,3 @@ func (db *DB) Close() error {
 func (db *DB) Conn() *sql.DB {
 	return db.conn
 }
-
-// createTables creates the necessary database tables
-func (db *DB) createTables() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS outbox_events (
-		id VARCHAR(255) PRIMARY KEY,
-		type VARCHAR(255) NOT NULL,
-		source VARCHAR(255) NOT NULL,
-		data JSONB NOT NULL,
-		metadata JSONB,
-		status VARCHAR(50) NOT NULL DEFAULT 'pending',
-		retry_count INTEGER NOT NULL DEFAULT 0,
-		last_error TEXT,
-		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-		published_at TIMESTAMP WITH TIME ZONE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_outbox_events_status ON outbox_events(status);
-	CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events(created_at);
-	CREATE INDEX IF NOT EXISTS idx_outbox_events_type ON outbox_events(type);
-	CREATE INDEX IF NOT EXISTS idx_outbox_events_source ON outbox_events(source);
-	`
-
-	_, err := db.conn.Exec(query)
-	return err
-}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// deadLetterColumns lists the columns read back for every dead letter query
+const deadLetterColumns = "id, type, source, data, metadata, retry_count, last_error, created_at, dead_lettered_at, partition_key, deliver_at, expires_at, priority"
+
+// scanDeadLetter scans a row selected with deadLetterColumns into a dead letter
+func scanDeadLetter(row rowScanner) (*models.DeadLetter, error) {
+	var deadLetter models.DeadLetter
+	var metadataStr sql.NullString
+	var lastErrorStr sql.NullString
+	var partitionKey sql.NullString
+	var deliverAt, expiresAt sql.NullTime
+	var dataStr string
+	err := row.Scan(&deadLetter.ID, &deadLetter.Type, &deadLetter.Source, &dataStr, &metadataStr, &deadLetter.RetryCount, &lastErrorStr, &deadLetter.CreatedAt, &deadLetter.DeadLetteredAt, &partitionKey, &deliverAt, &expiresAt, &deadLetter.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetter.Data = json.RawMessage(dataStr)
+
+	if metadataStr.Valid {
+		deadLetter.Metadata = json.RawMessage(metadataStr.String)
+	}
+
+	if lastErrorStr.Valid {
+		deadLetter.LastError = lastErrorStr.String
+	}
+
+	if partitionKey.Valid {
+		deadLetter.PartitionKey = partitionKey.String
+	}
+
+	if deliverAt.Valid {
+		deadLetter.DeliverAt = &deliverAt.Time
+	}
+
+	if expiresAt.Valid {
+		deadLetter.ExpiresAt = &expiresAt.Time
+	}
+
+	return &deadLetter, nil
+}
+
+// MoveToDeadLetter removes an event from the outbox and records it as a dead
+// letter in a single statement, so the event is never in both tables. Its
+// per-subscription delivery status and idempotency key move with it. The event must be leased to
+// workerID, or ErrLeaseLost is returned.
+func (s *OutboxStore) MoveToDeadLetter(ctx context.Context, workerID, id string, lastError string, retryCount int) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH moved AS (
+			DELETE FROM outbox_events
+			WHERE id = $1 AND locked_by = $5
+			RETURNING id, type, source, data, metadata, created_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash
+		), deliveries AS (
+			INSERT INTO outbox_dead_letter_deliveries (event_id, subscription_id, status, attempts, last_error, last_attempt_at, delivered_at)
+			SELECT d.event_id, d.subscription_id, d.status, d.attempts, d.last_error, d.last_attempt_at, d.delivered_at
+			FROM outbox_deliveries d
+			JOIN moved ON moved.id = d.event_id
+		)
+		INSERT INTO outbox_dead_letters (id, type, source, data, metadata, retry_count, last_error, created_at, dead_lettered_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		SELECT id, type, source, data, metadata, $2, $3, created_at, $4, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash
+		FROM moved
+	`
+
+	result, err := s.db.conn.ExecContext(ctx, query, id, retryCount, lastError, time.Now(), workerID)
+	if err != nil {
+		return fmt.Errorf("failed to move event %s to dead letters: %w", id, err)
+	}
+
+	return leaseHeld(result)
+}
+
+// ListDeadLetters retrieves dead letters, most recent first
+func (s *OutboxStore) ListDeadLetters(ctx context.Context, page, limit int) ([]models.DeadLetter, int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * limit
+
+	var total int
+	err := s.db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM outbox_dead_letters").Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	query := `
+		SELECT ` + deadLetterColumns + `
+		FROM outbox_dead_letters
+		ORDER BY dead_lettered_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := s.db.conn.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters []models.DeadLetter
+	for rows.Next() {
+		deadLetter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		deadLetters = append(deadLetters, *deadLetter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+
+	return deadLetters, total, nil
+}
+
+// GetDeadLetter retrieves a dead letter by event ID
+func (s *OutboxStore) GetDeadLetter(ctx context.Context, id string) (*models.DeadLetter, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + deadLetterColumns + `
+		FROM outbox_dead_letters
+		WHERE id = $1
+	`
+
+	deadLetter, err := scanDeadLetter(s.db.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("dead letter not found")
+		}
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	return deadLetter, nil
+}
+
+// requeueQuery moves dead letters back into the outbox as fresh pending
+// events, keeping their original ID, creation time, partition key, delivery
+// window and idempotency key. Their delivery status comes back too, so
+// subscribers that received them are not sent them again. Expired dead
+// letters would only expire again, so they are left where they are.
+//
+// A key is dropped rather than failing the requeue if an event created while
+// the dead letter was being moved took it meanwhile.
+const requeueQuery = `
+		WITH moved AS (
+			DELETE FROM outbox_dead_letters
+			WHERE (expires_at IS NULL OR expires_at > NOW())%s
+			RETURNING id, type, source, data, metadata, created_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash,
+				EXISTS (SELECT 1 FROM outbox_events e WHERE e.idempotency_key = outbox_dead_letters.idempotency_key) AS key_taken
+		), deliveries AS (
+			INSERT INTO outbox_deliveries (event_id, subscription_id, status, attempts, last_error, last_attempt_at, delivered_at)
+			SELECT d.event_id, d.subscription_id, d.status, d.attempts, d.last_error, d.last_attempt_at, d.delivered_at
+			FROM outbox_dead_letter_deliveries d
+			JOIN moved ON moved.id = d.event_id
+		)
+		INSERT INTO outbox_events (id, type, source, data, metadata, status, retry_count, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		SELECT id, type, source, data, metadata, 'pending', 0, created_at, NOW(), partition_key, deliver_at, expires_at, priority,
+			CASE WHEN NOT key_taken THEN idempotency_key END, CASE WHEN NOT key_taken THEN request_hash END
+		FROM moved
+	`
+
+// RequeueDeadLetters moves the given dead letters back into the outbox and
+// returns how many were requeued. Expired dead letters are not requeued.
+func (s *OutboxStore) RequeueDeadLetters(ctx context.Context, ids []string) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := fmt.Sprintf(requeueQuery, " AND id = ANY($1)")
+	result, err := s.db.conn.ExecContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead letters: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// RequeueAllDeadLetters moves every dead letter that has not expired back
+// into the outbox and returns how many were requeued
+func (s *OutboxStore) RequeueAllDeadLetters(ctx context.Context) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.conn.ExecContext(ctx, fmt.Sprintf(requeueQuery, ""))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead letters: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// PurgeDeadLetter permanently deletes a dead letter
+func (s *OutboxStore) PurgeDeadLetter(ctx context.Context, id string) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.conn.ExecContext(ctx, "DELETE FROM outbox_dead_letters WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to purge dead letter: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("dead letter not found")
+	}
+
+	return nil
+}
+
+// PurgeDeadLetters permanently deletes dead letters recorded before olderThan
+// and returns how many were removed
+func (s *OutboxStore) PurgeDeadLetters(ctx context.Context, olderThan time.Time) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.conn.ExecContext(ctx, "DELETE FROM outbox_dead_letters WHERE dead_lettered_at < $1", olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
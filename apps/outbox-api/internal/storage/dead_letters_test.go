@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var deadLetterRowColumns = []string{"id", "type", "source", "data", "metadata", "retry_count", "last_error", "created_at", "dead_lettered_at", "partition_key", "deliver_at", "expires_at", "priority"}
+
+func TestOutboxStore_MoveToDeadLetter(t *testing.T) {
+	query := `WITH moved AS (
+			DELETE FROM outbox_events
+			WHERE id = $1 AND locked_by = $5
+			RETURNING id, type, source, data, metadata, created_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash
+		), deliveries AS (
+			INSERT INTO outbox_dead_letter_deliveries (event_id, subscription_id, status, attempts, last_error, last_attempt_at, delivered_at)
+			SELECT d.event_id, d.subscription_id, d.status, d.attempts, d.last_error, d.last_attempt_at, d.delivered_at
+			FROM outbox_deliveries d
+			JOIN moved ON moved.id = d.event_id
+		)
+		INSERT INTO outbox_dead_letters (id, type, source, data, metadata, retry_count, last_error, created_at, dead_lettered_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		SELECT id, type, source, data, metadata, $2, $3, created_at, $4, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash
+		FROM moved`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "moves event",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("event-1", 4, "webhook returned status 500", sqlmock.AnyArg(), "worker-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "lease lost",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("event-1", 4, "webhook returned status 500", sqlmock.AnyArg(), "worker-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: "lease lost",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			err := store.MoveToDeadLetter(context.Background(), "worker-1", "event-1", "webhook returned status 500", 4)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_ListDeadLetters(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT COUNT(*) FROM outbox_dead_letters").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT id, type, source, data, metadata, retry_count, last_error, created_at, dead_lettered_at, partition_key, deliver_at, expires_at, priority
+		FROM outbox_dead_letters
+		ORDER BY dead_lettered_at DESC
+		LIMIT $1 OFFSET $2`).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+			AddRow("dead-1", "test.event", "test-service", `{"id": 1}`, nil, 4, "timeout", now.Add(-time.Hour), now, nil, nil, nil, 0))
+
+	store := NewOutboxStore(db)
+	deadLetters, total, err := store.ListDeadLetters(context.Background(), 2, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "dead-1", deadLetters[0].ID)
+	assert.Equal(t, "timeout", deadLetters[0].LastError)
+	assert.Nil(t, deadLetters[0].Metadata)
+	assert.Empty(t, deadLetters[0].PartitionKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_GetDeadLetter(t *testing.T) {
+	query := `SELECT id, type, source, data, metadata, retry_count, last_error, created_at, dead_lettered_at, partition_key, deliver_at, expires_at, priority
+		FROM outbox_dead_letters
+		WHERE id = $1`
+
+	t.Run("found", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WithArgs("dead-1").
+			WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+				AddRow("dead-1", "test.event", "test-service", `{"id": 1}`, `{"v": 1}`, 4, nil, now, now, "order-1", nil, now.Add(time.Hour), 7))
+
+		deadLetter, err := NewOutboxStore(db).GetDeadLetter(context.Background(), "dead-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "dead-1", deadLetter.ID)
+		assert.Equal(t, 4, deadLetter.RetryCount)
+		assert.Equal(t, "order-1", deadLetter.PartitionKey)
+		assert.Nil(t, deadLetter.DeliverAt)
+		require.NotNil(t, deadLetter.ExpiresAt)
+		assert.False(t, deadLetter.Expired(now))
+		assert.Equal(t, 7, deadLetter.Priority)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery(query).
+			WithArgs("missing").
+			WillReturnError(sql.ErrNoRows)
+
+		deadLetter, err := NewOutboxStore(db).GetDeadLetter(context.Background(), "missing")
+
+		assert.Nil(t, deadLetter)
+		assert.EqualError(t, err, "dead letter not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// expectedRequeueQuery is the requeue statement for unexpired dead letters
+// matching the extra condition where
+func expectedRequeueQuery(where string) string {
+	return `WITH moved AS (
+			DELETE FROM outbox_dead_letters
+			WHERE (expires_at IS NULL OR expires_at > NOW())` + where + `
+			RETURNING id, type, source, data, metadata, created_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash,
+				EXISTS (SELECT 1 FROM outbox_events e WHERE e.idempotency_key = outbox_dead_letters.idempotency_key) AS key_taken
+		), deliveries AS (
+			INSERT INTO outbox_deliveries (event_id, subscription_id, status, attempts, last_error, last_attempt_at, delivered_at)
+			SELECT d.event_id, d.subscription_id, d.status, d.attempts, d.last_error, d.last_attempt_at, d.delivered_at
+			FROM outbox_dead_letter_deliveries d
+			JOIN moved ON moved.id = d.event_id
+		)
+		INSERT INTO outbox_events (id, type, source, data, metadata, status, retry_count, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		SELECT id, type, source, data, metadata, 'pending', 0, created_at, NOW(), partition_key, deliver_at, expires_at, priority,
+			CASE WHEN NOT key_taken THEN idempotency_key END, CASE WHEN NOT key_taken THEN request_hash END
+		FROM moved`
+}
+
+func TestOutboxStore_RequeueDeadLetters(t *testing.T) {
+	t.Run("by IDs", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(expectedRequeueQuery(" AND id = ANY($1)")).
+			WithArgs(pq.Array([]string{"dead-1", "dead-2"})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		requeued, err := NewOutboxStore(db).RequeueDeadLetters(context.Background(), []string{"dead-1", "dead-2"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, requeued)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty selection is a no-op", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		requeued, err := NewOutboxStore(db).RequeueDeadLetters(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, requeued)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("all", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(expectedRequeueQuery("")).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+
+		requeued, err := NewOutboxStore(db).RequeueAllDeadLetters(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, requeued)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOutboxStore_PurgeDeadLetters(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec("DELETE FROM outbox_dead_letters WHERE id = $1").
+			WithArgs("dead-1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := NewOutboxStore(db).PurgeDeadLetter(context.Background(), "dead-1")
+
+		assert.EqualError(t, err, "dead letter not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("older than cutoff", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		cutoff := time.Now().Add(-72 * time.Hour)
+		mock.ExpectExec("DELETE FROM outbox_dead_letters WHERE dead_lettered_at < $1").
+			WithArgs(cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		purged, err := NewOutboxStore(db).PurgeDeadLetters(context.Background(), cutoff)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, purged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,18 +1,60 @@
 package storage
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
 	"time"
 )
 
 // OutboxStoreInterface defines the interface for outbox event storage operations
 type OutboxStoreInterface interface {
-	CreateEvent(req *models.CreateEventRequest) (*models.Event, error)
-	GetEvent(id string) (*models.Event, error)
-	ListEvents(status *models.EventStatus, page, limit int) ([]models.Event, int, error)
-	GetPendingEvents(limit int) ([]models.Event, error)
-	UpdateEventStatus(id string, status models.EventStatus, lastError string, retryCount int) error
-	UpdateEventPublishedAt(id string, publishedAt *time.Time) error
-	DeleteEvent(id string) error
-	GetStats() (*models.StatsResponse, error)
+	CreateEvent(ctx context.Context, req *models.CreateEventRequest) (*models.Event, error)
+	CreateEventIdempotent(ctx context.Context, req *models.CreateEventRequest, retention time.Duration) (*models.Event, bool, error)
+	GetEvent(ctx context.Context, id string) (*models.Event, error)
+	ListEvents(ctx context.Context, query *models.EventQuery) ([]models.Event, int, error)
+	GetPendingEvents(ctx context.Context, limit int, aging time.Duration) ([]models.Event, error)
+	ClaimPendingEvents(ctx context.Context, workerID string, limit int, lease, aging time.Duration) ([]models.Event, error)
+	ClaimEvents(ctx context.Context, workerID string, ids []string, statuses []models.EventStatus, lease time.Duration) ([]models.Event, error)
+	ReleaseEvents(ctx context.Context, workerID string, ids []string) error
+	RenewLease(ctx context.Context, workerID, id string, lease time.Duration) error
+	UpdateEventStatus(ctx context.Context, workerID, id string, status models.EventStatus, lastError string, retryCount int) error
+	ScheduleRetry(ctx context.Context, workerID, id string, lastError string, retryCount int, nextAttemptAt time.Time) error
+	UpdateEventPublishedAt(ctx context.Context, id string, publishedAt *time.Time) error
+	DeleteEvent(ctx context.Context, id string) error
+	GetStats(ctx context.Context) (*models.StatsResponse, error)
+	GetTimeseries(ctx context.Context, from time.Time, bucket time.Duration, count int) (*models.TimeseriesResponse, error)
+	ListEventChanges(ctx context.Context, filter *models.ChangeFilter, afterID int64, limit int) ([]models.EventChange, error)
+
+	CountEvents(ctx context.Context, query *models.EventQuery) (int, error)
+	ListEventIDs(ctx context.Context, query *models.EventQuery, afterID string, limit int) ([]string, error)
+	ApplyBulkAction(ctx context.Context, jobID string, action models.BulkAction, ids []string) (int, error)
+	CreateBulkJob(ctx context.Context, job *models.BulkJob) error
+	UpdateBulkJob(ctx context.Context, job *models.BulkJob) error
+	GetBulkJob(ctx context.Context, id string) (*models.BulkJob, error)
+
+	MoveToDeadLetter(ctx context.Context, workerID, id string, lastError string, retryCount int) error
+	ListDeadLetters(ctx context.Context, page, limit int) ([]models.DeadLetter, int, error)
+	GetDeadLetter(ctx context.Context, id string) (*models.DeadLetter, error)
+	RequeueDeadLetters(ctx context.Context, ids []string) (int, error)
+	RequeueAllDeadLetters(ctx context.Context) (int, error)
+	PurgeDeadLetter(ctx context.Context, id string) error
+	PurgeDeadLetters(ctx context.Context, olderThan time.Time) (int, error)
+
+	CreateSubscription(ctx context.Context, req *models.SubscriptionRequest) (*models.Subscription, error)
+	GetSubscription(ctx context.Context, id string) (*models.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.Subscription, error)
+	UpdateSubscription(ctx context.Context, id string, req *models.SubscriptionRequest) (*models.Subscription, error)
+	RotateSubscriptionSecret(ctx context.Context, id string, secret string, previousExpiresAt time.Time) (*models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, eventID string) ([]models.Delivery, error)
+	RecordDelivery(ctx context.Context, eventID, subscriptionID string, lastError string) error
+	RecordAttempt(ctx context.Context, attempt *models.DeliveryAttempt) error
+	ListAttempts(ctx context.Context, eventID string) ([]models.DeliveryAttempt, error)
+
+	CreateSchema(ctx context.Context, eventType string, schema json.RawMessage) (*models.EventSchema, error)
+	GetSchema(ctx context.Context, eventType string, version int) (*models.EventSchema, error)
+	ListSchemas(ctx context.Context, eventType string) ([]models.EventSchema, error)
+	DeleteSchema(ctx context.Context, eventType string, version int) error
 }
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	observability "github.com/jared-scarr/portfolio-monorepo/packages/observability/handlers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_queue_depth",
+			Help: "Events waiting to be published, by status",
+		},
+		[]string{"status"},
+	)
+
+	oldestPendingAge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_oldest_pending_age_seconds",
+			Help: "How long the oldest pending event due for delivery has been waiting",
+		},
+	)
+)
+
+func init() {
+	observability.MustRegister(queueDepth, oldestPendingAge)
+}
+
+// queueMetricsQuery counts pending and retrying events and measures how long
+// the oldest pending event has been due: since its deliver_at, or since it
+// was created when it was not scheduled
+const queueMetricsQuery = `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'retrying'),
+			COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(COALESCE(deliver_at, created_at)) FILTER (
+				WHERE status = 'pending' AND (deliver_at IS NULL OR deliver_at <= NOW())
+			)), 0)
+		FROM outbox_events
+		WHERE status IN ('pending', 'retrying')
+	`
+
+// SampleQueueMetrics sets the queue depth and oldest pending age gauges from
+// outbox_events. It is run by an observability.Sampler.
+func (s *OutboxStore) SampleQueueMetrics(ctx context.Context) error {
+	var pending, retrying int
+	var age float64
+	err := s.db.conn.QueryRowContext(ctx, queueMetricsQuery).Scan(&pending, &retrying, &age)
+	if err != nil {
+		return fmt.Errorf("failed to sample queue metrics: %w", err)
+	}
+
+	queueDepth.WithLabelValues(string(models.StatusPending)).Set(float64(pending))
+	queueDepth.WithLabelValues(string(models.StatusRetrying)).Set(float64(retrying))
+	oldestPendingAge.Set(age)
+	return nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxStore_SampleQueueMetrics(t *testing.T) {
+	tests := []struct {
+		name             string
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedPending  float64
+		expectedRetrying float64
+		expectedAge      float64
+		expectedError    string
+	}{
+		{
+			name: "sets gauges",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(queueMetricsQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pending", "retrying", "age"}).AddRow(42, 7, 93.5))
+			},
+			expectedPending:  42,
+			expectedRetrying: 7,
+			expectedAge:      93.5,
+		},
+		{
+			name: "database error keeps the last values",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(queueMetricsQuery).WillReturnError(sql.ErrConnDone)
+			},
+			expectedPending:  42,
+			expectedRetrying: 7,
+			expectedAge:      93.5,
+			expectedError:    "failed to sample queue metrics",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			err := store.SampleQueueMetrics(context.Background())
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPending, testutil.ToFloat64(queueDepth.WithLabelValues("pending")))
+			assert.Equal(t, tt.expectedRetrying, testutil.ToFloat64(queueDepth.WithLabelValues("retrying")))
+			assert.Equal(t, tt.expectedAge, testutil.ToFloat64(oldestPendingAge))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            fstest.MapFS
+		expectedVersions []int
+		expectedError    string
+	}{
+		{
+			name: "orders migrations by version",
+			files: fstest.MapFS{
+				"0010_add_index.up.sql":      {Data: []byte("CREATE INDEX i ON t(c);")},
+				"0010_add_index.down.sql":    {Data: []byte("DROP INDEX i;")},
+				"0002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+				"0002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+			expectedVersions: []int{2, 10},
+		},
+		{
+			name: "missing down migration",
+			files: fstest.MapFS{
+				"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+			},
+			expectedError: "migration 0001_create_table needs both an up and a down file",
+		},
+		{
+			name: "invalid file name",
+			files: fstest.MapFS{
+				"create_table.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+			},
+			expectedError: `invalid migration file name "create_table.sql"`,
+		},
+		{
+			name: "version used twice",
+			files: fstest.MapFS{
+				"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+				"0001_create_other.up.sql": {Data: []byte("CREATE TABLE o (c INT);")},
+			},
+			expectedError: "migration version 1 is used by both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			var versions []int
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			assert.Equal(t, tt.expectedVersions, versions)
+		})
+	}
+}
+
+func TestNewMigrator_EmbeddedMigrations(t *testing.T) {
+	migrator, err := NewMigrator(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrator.migrations)
+
+	for i, migration := range migrator.migrations {
+		assert.Equal(t, i+1, migration.Version, "migration versions must be consecutive")
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+// expectMigrationLock expects the lock, bookkeeping table and applied
+// versions read that start every migrator operation
+func expectMigrationLock(mock sqlmock.Sqlmock, appliedVersions ...int) {
+	mock.ExpectExec("SELECT pg_advisory_lock($1)").WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range appliedVersions {
+		rows.AddRow(version, time.Now())
+	}
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(rows)
+}
+
+func expectMigrationUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("SELECT pg_advisory_unlock($1)").WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_table", Up: "CREATE TABLE t (c INT);", Down: "DROP TABLE t;"},
+		{Version: 2, Name: "add_column", Up: "ALTER TABLE t ADD COLUMN d INT;", Down: "ALTER TABLE t DROP COLUMN d;"},
+		{Version: 3, Name: "add_index", Up: "CREATE INDEX i ON t(d);", Down: "DROP INDEX i;"},
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	tests := []struct {
+		name            string
+		appliedVersions []int
+		mockSetup       func(sqlmock.Sqlmock)
+		expectedApplied []string
+		expectedError   string
+	}{
+		{
+			name:            "applies pending migrations in order",
+			appliedVersions: []int{1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				for _, migration := range testMigrations()[1:] {
+					mock.ExpectBegin()
+					mock.ExpectExec(migration.Up).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec("INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, NOW())").
+						WithArgs(migration.Version, migration.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				}
+			},
+			expectedApplied: []string{"0002_add_column", "0003_add_index"},
+		},
+		{
+			name:            "nothing pending",
+			appliedVersions: []int{1, 2, 3},
+			mockSetup:       func(mock sqlmock.Sqlmock) {},
+		},
+		{
+			name:            "stops at a failing migration",
+			appliedVersions: []int{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				first := testMigrations()[0]
+				mock.ExpectBegin()
+				mock.ExpectExec(first.Up).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, NOW())").
+					WithArgs(first.Version, first.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				mock.ExpectBegin()
+				mock.ExpectExec(testMigrations()[1].Up).WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			expectedApplied: []string{"0001_create_table"},
+			expectedError:   "failed to apply migration 0002_add_column",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			expectMigrationLock(mock, tt.appliedVersions...)
+			tt.mockSetup(mock)
+			expectMigrationUnlock(mock)
+
+			migrator := &Migrator{conn: db.conn, migrations: testMigrations()}
+			applied, err := migrator.Up(context.Background())
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var names []string
+			for _, migration := range applied {
+				names = append(names, migration.String())
+			}
+			assert.Equal(t, tt.expectedApplied, names)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	migrations := testMigrations()
+	expectMigrationLock(mock, 1, 2, 3)
+	for _, migration := range []Migration{migrations[2], migrations[1]} {
+		mock.ExpectBegin()
+		mock.ExpectExec(migration.Down).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM schema_migrations WHERE version = $1").
+			WithArgs(migration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	expectMigrationUnlock(mock)
+
+	migrator := &Migrator{conn: db.conn, migrations: migrations}
+	rolledBack, err := migrator.Down(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, rolledBack, 2)
+	assert.Equal(t, 3, rolledBack[0].Version)
+	assert.Equal(t, 2, rolledBack[1].Version)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	expectMigrationLock(mock, 1, 2)
+	expectMigrationUnlock(mock)
+
+	migrator := &Migrator{conn: db.conn, migrations: testMigrations()}
+	statuses, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.NotNil(t, statuses[1].AppliedAt)
+	assert.Nil(t, statuses[2].AppliedAt)
+	assert.Equal(t, "add_index", statuses[2].Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
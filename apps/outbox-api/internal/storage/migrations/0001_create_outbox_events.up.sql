@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+	id VARCHAR(255) PRIMARY KEY,
+	type VARCHAR(255) NOT NULL,
+	source VARCHAR(255) NOT NULL,
+	data JSONB NOT NULL,
+	metadata JSONB,
+	status VARCHAR(50) NOT NULL DEFAULT 'pending',
+	retry_count INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status ON outbox_events(status);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_type ON outbox_events(type);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_source ON outbox_events(source);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_events_locked_until;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS locked_by;
@@ -0,0 +1,4 @@
+-- Lease columns used to claim events for a single publisher
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS locked_by VARCHAR(255);
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_locked_until ON outbox_events(locked_until);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_events_next_attempt_at;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS next_attempt_at;
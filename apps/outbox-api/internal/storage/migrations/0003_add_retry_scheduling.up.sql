@@ -0,0 +1,3 @@
+-- Retry scheduling
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_next_attempt_at ON outbox_events(next_attempt_at);
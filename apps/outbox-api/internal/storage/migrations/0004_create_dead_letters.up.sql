@@ -0,0 +1,14 @@
+-- Events that used up their retry budget
+CREATE TABLE IF NOT EXISTS outbox_dead_letters (
+	id VARCHAR(255) PRIMARY KEY,
+	type VARCHAR(255) NOT NULL,
+	source VARCHAR(255) NOT NULL,
+	data JSONB NOT NULL,
+	metadata JSONB,
+	retry_count INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	dead_lettered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_dead_letters_dead_lettered_at ON outbox_dead_letters(dead_lettered_at);
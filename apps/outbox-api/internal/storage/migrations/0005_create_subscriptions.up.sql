@@ -0,0 +1,24 @@
+-- Webhook subscriptions and their per-event delivery status
+CREATE TABLE IF NOT EXISTS outbox_subscriptions (
+	id VARCHAR(255) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	url TEXT NOT NULL,
+	event_types TEXT[] NOT NULL DEFAULT '{}',
+	sources TEXT[] NOT NULL DEFAULT '{}',
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+	event_id VARCHAR(255) NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+	subscription_id VARCHAR(255) NOT NULL REFERENCES outbox_subscriptions(id) ON DELETE CASCADE,
+	status VARCHAR(50) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	last_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	delivered_at TIMESTAMP WITH TIME ZONE,
+	PRIMARY KEY (event_id, subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_deliveries_subscription_id ON outbox_deliveries(subscription_id);
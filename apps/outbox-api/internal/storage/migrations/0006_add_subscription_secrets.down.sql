@@ -0,0 +1,3 @@
+ALTER TABLE outbox_subscriptions DROP COLUMN IF EXISTS previous_secret_expires_at;
+ALTER TABLE outbox_subscriptions DROP COLUMN IF EXISTS previous_secret;
+ALTER TABLE outbox_subscriptions DROP COLUMN IF EXISTS secret;
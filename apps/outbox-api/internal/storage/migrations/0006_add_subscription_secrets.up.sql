@@ -0,0 +1,4 @@
+-- Webhook signing secrets, with the previous secret kept during rotation
+ALTER TABLE outbox_subscriptions ADD COLUMN IF NOT EXISTS secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox_subscriptions ADD COLUMN IF NOT EXISTS previous_secret TEXT;
+ALTER TABLE outbox_subscriptions ADD COLUMN IF NOT EXISTS previous_secret_expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_events_idempotency_key;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS request_hash;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,4 @@
+-- Idempotency keys for event creation, with a fingerprint of the original request
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS request_hash VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_idempotency_key ON outbox_events(idempotency_key) WHERE idempotency_key IS NOT NULL;
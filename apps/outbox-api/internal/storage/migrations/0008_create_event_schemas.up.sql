@@ -0,0 +1,8 @@
+-- JSON Schemas for event data, versioned per event type
+CREATE TABLE IF NOT EXISTS outbox_event_schemas (
+	event_type VARCHAR(255) NOT NULL,
+	version INTEGER NOT NULL,
+	schema JSONB NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (event_type, version)
+);
@@ -0,0 +1,5 @@
+-- Events sharing a partition key are published strictly in creation order
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS partition_key VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_partition_key ON outbox_events(partition_key, created_at)
+	WHERE partition_key IS NOT NULL;
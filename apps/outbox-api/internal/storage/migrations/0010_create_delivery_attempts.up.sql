@@ -0,0 +1,16 @@
+-- One row per attempt to deliver an event to a destination. Rows outlive the
+-- event so the history of a dead-lettered event can still be inspected.
+CREATE TABLE IF NOT EXISTS outbox_delivery_attempts (
+	id BIGSERIAL PRIMARY KEY,
+	event_id VARCHAR(255) NOT NULL,
+	subscription_id VARCHAR(255),
+	destination TEXT NOT NULL,
+	attempted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	status_code INTEGER,
+	response_body TEXT,
+	error TEXT,
+	error_class VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_delivery_attempts_event_id ON outbox_delivery_attempts(event_id, attempted_at);
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_outbox_events_last_error_trgm;
+DROP INDEX IF EXISTS idx_outbox_events_metadata;
+DROP INDEX IF EXISTS idx_outbox_events_retry_count;
+DROP INDEX IF EXISTS idx_outbox_events_published_at;
+
+DROP INDEX IF EXISTS idx_outbox_events_source_pattern;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_source ON outbox_events(source);
+
+DROP INDEX IF EXISTS idx_outbox_events_type_pattern;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_type ON outbox_events(type);
+
+DROP INDEX IF EXISTS idx_outbox_events_status_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status ON outbox_events(status);
+
+DROP INDEX IF EXISTS idx_outbox_events_created_at_id;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events(created_at);
@@ -0,0 +1,27 @@
+-- Indexes backing GET /events: keyset pagination walks (created_at, id)
+-- newest first, optionally within one status
+DROP INDEX IF EXISTS idx_outbox_events_created_at;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at_id ON outbox_events(created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_outbox_events_status;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status_created_at_id ON outbox_events(status, created_at DESC, id DESC);
+
+-- Pattern ops serve both exact and prefix ("order.*") matches
+DROP INDEX IF EXISTS idx_outbox_events_type;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_type_pattern ON outbox_events(type varchar_pattern_ops);
+
+DROP INDEX IF EXISTS idx_outbox_events_source;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_source_pattern ON outbox_events(source varchar_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(published_at)
+	WHERE published_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_retry_count ON outbox_events(retry_count)
+	WHERE retry_count > 0;
+
+-- Containment (metadata @> '{"tenant": "acme"}')
+CREATE INDEX IF NOT EXISTS idx_outbox_events_metadata ON outbox_events USING GIN (metadata jsonb_path_ops);
+
+-- Substring search in last_error
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_last_error_trgm ON outbox_events USING GIN (last_error gin_trgm_ops);
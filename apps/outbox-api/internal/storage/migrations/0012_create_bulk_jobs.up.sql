@@ -0,0 +1,16 @@
+-- Progress of asynchronous bulk actions started via POST /admin/events/bulk
+CREATE TABLE IF NOT EXISTS outbox_bulk_jobs (
+	id VARCHAR(255) PRIMARY KEY,
+	action VARCHAR(50) NOT NULL,
+	event_ids TEXT[],
+	filter JSONB,
+	status VARCHAR(50) NOT NULL,
+	matched INTEGER NOT NULL DEFAULT 0,
+	processed INTEGER NOT NULL DEFAULT 0,
+	succeeded INTEGER NOT NULL DEFAULT 0,
+	failed INTEGER NOT NULL DEFAULT 0,
+	error TEXT,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	completed_at TIMESTAMP WITH TIME ZONE
+);
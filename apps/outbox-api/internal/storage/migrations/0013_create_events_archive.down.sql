@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS outbox_events_archive;
+DROP INDEX IF EXISTS idx_outbox_events_status_updated_at;
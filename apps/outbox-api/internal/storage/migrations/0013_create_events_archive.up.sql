@@ -0,0 +1,23 @@
+-- Supports the retention janitor's scan for expired events
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status_updated_at ON outbox_events(status, updated_at);
+
+-- Events pruned by the retention janitor in archive mode. IDs are not unique:
+-- a requeued dead letter keeps its ID and may be archived twice.
+CREATE TABLE IF NOT EXISTS outbox_events_archive (
+	id VARCHAR(255) NOT NULL,
+	type VARCHAR(255) NOT NULL,
+	source VARCHAR(255) NOT NULL,
+	data JSONB NOT NULL,
+	metadata JSONB,
+	status VARCHAR(50) NOT NULL,
+	retry_count INTEGER NOT NULL,
+	last_error TEXT,
+	partition_key VARCHAR(255),
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	published_at TIMESTAMP WITH TIME ZONE,
+	archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_archive_id ON outbox_events_archive(id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_archive_archived_at ON outbox_events_archive(archived_at);
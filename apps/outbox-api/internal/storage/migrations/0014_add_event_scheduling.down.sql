@@ -0,0 +1,6 @@
+ALTER TABLE outbox_events_archive DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE outbox_events_archive DROP COLUMN IF EXISTS deliver_at;
+DROP INDEX IF EXISTS idx_outbox_events_expires_at;
+DROP INDEX IF EXISTS idx_outbox_events_deliver_at;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS deliver_at;
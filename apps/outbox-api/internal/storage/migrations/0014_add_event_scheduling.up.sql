@@ -0,0 +1,12 @@
+-- Scheduled delivery: events are held back until deliver_at, and expire
+-- instead of being delivered late once expires_at has passed
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_deliver_at ON outbox_events(deliver_at)
+	WHERE deliver_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_expires_at ON outbox_events(expires_at)
+	WHERE expires_at IS NOT NULL;
+
+ALTER TABLE outbox_events_archive ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE outbox_events_archive ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_events_archive DROP COLUMN IF EXISTS priority;
+DROP INDEX IF EXISTS idx_outbox_events_pending_priority;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS priority;
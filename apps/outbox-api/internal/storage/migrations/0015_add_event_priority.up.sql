@@ -0,0 +1,8 @@
+-- Urgent events are claimed ahead of older, less urgent ones
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+
+-- Supports the per-priority pending counts in the stats
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending_priority ON outbox_events(priority)
+	WHERE status = 'pending';
+
+ALTER TABLE outbox_events_archive ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
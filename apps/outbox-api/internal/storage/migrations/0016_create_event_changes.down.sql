@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS outbox_event_changes ON outbox_events;
+DROP FUNCTION IF EXISTS record_outbox_event_change();
+DROP TABLE IF EXISTS outbox_event_changes;
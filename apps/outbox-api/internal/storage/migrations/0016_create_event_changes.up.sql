@@ -0,0 +1,50 @@
+-- A log of event state transitions, written by a trigger so every path that
+-- touches outbox_events is covered. Each change is also sent with NOTIFY on
+-- the outbox_event_changes channel, which feeds /api/v1/events/stream on
+-- every replica; the log lets clients resume from a Last-Event-ID.
+CREATE TABLE IF NOT EXISTS outbox_event_changes (
+	id BIGSERIAL PRIMARY KEY,
+	event_id VARCHAR(255) NOT NULL,
+	action VARCHAR(50) NOT NULL,
+	type VARCHAR(255) NOT NULL,
+	status VARCHAR(50) NOT NULL,
+	retry_count INTEGER NOT NULL,
+	changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_event_changes_changed_at ON outbox_event_changes(changed_at);
+
+CREATE OR REPLACE FUNCTION record_outbox_event_change() RETURNS trigger AS $$
+DECLARE
+	change outbox_event_changes;
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		INSERT INTO outbox_event_changes (event_id, action, type, status, retry_count)
+		VALUES (NEW.id, 'created', NEW.type, NEW.status, NEW.retry_count)
+		RETURNING * INTO change;
+	ELSIF TG_OP = 'DELETE' THEN
+		-- Dead lettering deletes the event and inserts its dead letter in one
+		-- statement, and AFTER triggers only run once both are done
+		INSERT INTO outbox_event_changes (event_id, action, type, status, retry_count)
+		VALUES (OLD.id, CASE WHEN EXISTS (SELECT 1 FROM outbox_dead_letters WHERE id = OLD.id) THEN 'dead_lettered' ELSE 'deleted' END,
+			OLD.type, OLD.status, OLD.retry_count)
+		RETURNING * INTO change;
+	ELSIF NEW.status IS DISTINCT FROM OLD.status OR NEW.retry_count IS DISTINCT FROM OLD.retry_count THEN
+		-- An event going back to pending has been requeued; otherwise the
+		-- action is the status it moved to
+		INSERT INTO outbox_event_changes (event_id, action, type, status, retry_count)
+		VALUES (NEW.id, CASE WHEN NEW.status = 'pending' THEN 'requeued' ELSE NEW.status END, NEW.type, NEW.status, NEW.retry_count)
+		RETURNING * INTO change;
+	ELSE
+		RETURN NULL;
+	END IF;
+
+	PERFORM pg_notify('outbox_event_changes', row_to_json(change)::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_event_changes ON outbox_events;
+CREATE TRIGGER outbox_event_changes
+	AFTER INSERT OR UPDATE OF status, retry_count OR DELETE ON outbox_events
+	FOR EACH ROW EXECUTE FUNCTION record_outbox_event_change();
@@ -0,0 +1,15 @@
+-- Per-subscription delivery status of dead-lettered events. Rows move here
+-- with their event and back when it is requeued, so a requeued event is not
+-- sent again to subscribers that already received it.
+CREATE TABLE IF NOT EXISTS outbox_dead_letter_deliveries (
+	event_id VARCHAR(255) NOT NULL REFERENCES outbox_dead_letters(id) ON DELETE CASCADE,
+	subscription_id VARCHAR(255) NOT NULL REFERENCES outbox_subscriptions(id) ON DELETE CASCADE,
+	status VARCHAR(50) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	last_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	delivered_at TIMESTAMP WITH TIME ZONE,
+	PRIMARY KEY (event_id, subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_dead_letter_deliveries_subscription_id ON outbox_dead_letter_deliveries(subscription_id);
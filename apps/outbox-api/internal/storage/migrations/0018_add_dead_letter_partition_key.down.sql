@@ -0,0 +1 @@
+ALTER TABLE outbox_dead_letters DROP COLUMN IF EXISTS partition_key;
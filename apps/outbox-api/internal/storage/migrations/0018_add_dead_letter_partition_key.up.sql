@@ -0,0 +1,3 @@
+-- Dead letters keep their partition key so that a requeued event is ordered
+-- with the rest of its partition again
+ALTER TABLE outbox_dead_letters ADD COLUMN IF NOT EXISTS partition_key VARCHAR(255);
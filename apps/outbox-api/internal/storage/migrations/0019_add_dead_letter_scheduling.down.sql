@@ -0,0 +1,2 @@
+ALTER TABLE outbox_dead_letters DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE outbox_dead_letters DROP COLUMN IF EXISTS deliver_at;
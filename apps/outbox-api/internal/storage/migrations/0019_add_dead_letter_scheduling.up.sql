@@ -0,0 +1,4 @@
+-- Dead letters keep their delivery window so that a requeued event is still
+-- held back until deliver_at and is not delivered after expires_at
+ALTER TABLE outbox_dead_letters ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE outbox_dead_letters ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
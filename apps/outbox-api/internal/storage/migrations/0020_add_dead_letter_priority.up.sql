@@ -0,0 +1,3 @@
+-- Dead letters keep their priority so that a requeued event is as urgent as
+-- it was before
+ALTER TABLE outbox_dead_letters ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
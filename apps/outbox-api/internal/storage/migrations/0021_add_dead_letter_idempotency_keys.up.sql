@@ -0,0 +1,6 @@
+-- Dead letters keep their event's idempotency key, so that a retried create
+-- returns the dead-lettered event instead of a duplicate, and a requeued
+-- event is still found by its key
+ALTER TABLE outbox_dead_letters ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE outbox_dead_letters ADD COLUMN IF NOT EXISTS request_hash VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_dead_letters_idempotency_key ON outbox_dead_letters(idempotency_key) WHERE idempotency_key IS NOT NULL;
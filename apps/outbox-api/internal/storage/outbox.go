@@ -1,15 +1,169 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/pkg/outbox"
+	"github.com/lib/pq"
 )
 
+// eventColumns lists the columns read back for every event query
+const eventColumns = "id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority"
+
+// ErrLeaseLost is returned when an event's outcome is recorded by a worker
+// that no longer holds its lease: the lease expired and the event was claimed
+// again, or it was taken out of the outbox meanwhile
+var ErrLeaseLost = errors.New("lease lost")
+
+// claimLockID is the Postgres advisory lock key held while claiming events, so
+// that concurrent publishers never see the same partition key as unblocked
+const claimLockID int64 = 727166184
+
+// dueCondition matches events that may be attempted now, whatever their
+// status: due for delivery, past their scheduled retry time, not expired and
+// not leased by another worker
+const dueCondition = `(deliver_at IS NULL OR deliver_at <= NOW())
+		AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+		AND (expires_at IS NULL OR expires_at > NOW())
+		AND (locked_until IS NULL OR locked_until < NOW())`
+
+// readyCondition matches events that a publisher may pick up now: pending or
+// retrying and due
+const readyCondition = `status IN ('pending', 'retrying')
+		AND ` + dueCondition
+
+// expireEventsQuery moves unleased pending and retrying events whose
+// expires_at has passed to the expired status
+const expireEventsQuery = `
+		UPDATE outbox_events
+		SET status = 'expired', next_attempt_at = NULL, updated_at = NOW()
+		WHERE status IN ('pending', 'retrying')
+			AND expires_at <= NOW()
+			AND (locked_until IS NULL OR locked_until < NOW())`
+
+// urgency ranks events for publishing: their priority plus one level for
+// every aging interval they have been due, so that low-priority events are
+// not starved by a steady stream of urgent ones. rateParam is the
+// placeholder for the levels gained per second.
+func urgency(rateParam string) string {
+	return "priority + EXTRACT(EPOCH FROM NOW() - COALESCE(deliver_at, created_at)) * " + rateParam
+}
+
+// agingRate converts an aging interval to priority levels gained per second;
+// zero disables aging
+func agingRate(aging time.Duration) float64 {
+	if aging <= 0 {
+		return 0
+	}
+	return 1 / aging.Seconds()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// rowScannerFunc adapts a function to rowScanner, e.g. to scan extra columns
+// selected after eventColumns
+type rowScannerFunc func(dest ...interface{}) error
+
+func (f rowScannerFunc) Scan(dest ...interface{}) error {
+	return f(dest...)
+}
+
+// scanEvent scans a row selected with eventColumns into an event
+func scanEvent(row rowScanner) (*models.Event, error) {
+	var event models.Event
+	var metadataStr sql.NullString
+	var lastErrorStr sql.NullString
+	var publishedAt sql.NullTime
+	var nextAttemptAt sql.NullTime
+	var partitionKey sql.NullString
+	var deliverAt sql.NullTime
+	var expiresAt sql.NullTime
+	var dataStr string
+	err := row.Scan(&event.ID, &event.Type, &event.Source, &dataStr, &metadataStr, &event.Status, &event.RetryCount, &lastErrorStr, &event.CreatedAt, &event.UpdatedAt, &publishedAt, &nextAttemptAt, &partitionKey, &deliverAt, &expiresAt, &event.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	event.Data = json.RawMessage(dataStr)
+
+	if metadataStr.Valid {
+		event.Metadata = json.RawMessage(metadataStr.String)
+	}
+
+	if lastErrorStr.Valid {
+		event.LastError = lastErrorStr.String
+	}
+
+	if publishedAt.Valid {
+		event.PublishedAt = &publishedAt.Time
+	}
+
+	if nextAttemptAt.Valid {
+		event.NextAttemptAt = &nextAttemptAt.Time
+	}
+
+	if partitionKey.Valid {
+		event.PartitionKey = partitionKey.String
+	}
+
+	if deliverAt.Valid {
+		event.DeliverAt = &deliverAt.Time
+	}
+
+	if expiresAt.Valid {
+		event.ExpiresAt = &expiresAt.Time
+	}
+
+	return &event, nil
+}
+
+// scanEvents scans all rows selected with eventColumns
+func scanEvents(rows *sql.Rows) ([]models.Event, error) {
+	var events []models.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, *event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	return events, nil
+}
+
+// nullString returns value, or NULL when it is empty
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullTime returns *value, or NULL when it is nil
+func nullTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
 // OutboxStore handles outbox event storage operations
 type OutboxStore struct {
 	db *DB
@@ -21,85 +175,152 @@ func NewOutboxStore(db *DB) *OutboxStore {
 }
 
 // CreateEvent creates a new outbox event
-func (s *OutboxStore) CreateEvent(req *models.CreateEventRequest) (*models.Event, error) {
+func (s *OutboxStore) CreateEvent(ctx context.Context, req *models.CreateEventRequest) (*models.Event, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
 	id := uuid.New().String()
 	now := time.Now()
 
-	// Validate that data is valid JSON
-	var data interface{}
-	if err := json.Unmarshal(req.Data, &data); err != nil {
-		return nil, fmt.Errorf("invalid JSON in data field: %w", err)
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Handle metadata - validate JSON if present
+	// Use the raw JSON for PostgreSQL, NULL when there is no metadata
 	var metadata interface{}
 	if len(req.Metadata) > 0 {
-		if err := json.Unmarshal(req.Metadata, &metadata); err != nil {
-			return nil, fmt.Errorf("invalid JSON in metadata field: %w", err)
-		}
-		metadata = req.Metadata // Use the raw JSON for PostgreSQL
-	} else {
-		metadata = nil
+		metadata = req.Metadata
 	}
 
 	query := `
-		INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
-	`
-
-	var event models.Event
-	var metadataStr sql.NullString
-	var lastErrorStr sql.NullString
-	var publishedAt sql.NullTime
-	var dataStr string
-	err := s.db.conn.QueryRow(query, id, req.Type, req.Source, req.Data, metadata, models.StatusPending, now, now).
-		Scan(&event.ID, &event.Type, &event.Source, &dataStr, &metadataStr, &event.Status, &event.RetryCount, &lastErrorStr, &event.CreatedAt, &event.UpdatedAt, &publishedAt)
+		INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING ` + eventColumns
 
+	event, err := scanEvent(s.db.conn.QueryRowContext(ctx, query, id, req.Type, req.Source, req.Data, metadata, models.StatusPending, now, now, nullString(req.PartitionKey), nullTime(req.DeliverAt), nullTime(req.ExpiresAt), req.Priority))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event: %w", err)
 	}
 
-	// Handle nullable fields
-	event.Data = json.RawMessage(dataStr)
+	return event, nil
+}
 
-	if metadataStr.Valid {
-		event.Metadata = json.RawMessage(metadataStr.String)
-	} else {
-		event.Metadata = nil
+// CreateEventIdempotent creates an event under req.IdempotencyKey, or returns
+// the event already created with that key within the retention window. The
+// returned bool is true when a new event was inserted. Reusing a key with a
+// different payload returns an error.
+func (s *OutboxStore) CreateEventIdempotent(ctx context.Context, req *models.CreateEventRequest, retention time.Duration) (*models.Event, bool, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	if err := req.Validate(); err != nil {
+		return nil, false, err
 	}
 
-	if lastErrorStr.Valid {
-		event.LastError = lastErrorStr.String
-	} else {
-		event.LastError = ""
+	requestHash, err := req.PayloadHash()
+	if err != nil {
+		return nil, false, err
 	}
 
-	if publishedAt.Valid {
-		event.PublishedAt = &publishedAt.Time
-	} else {
-		event.PublishedAt = nil
+	var metadata interface{}
+	if len(req.Metadata) > 0 {
+		metadata = req.Metadata
 	}
 
-	return &event, nil
+	tx, err := s.db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Release the key if it belongs to an event older than the retention window
+	if err := outbox.ReleaseExpiredKey(ctx, tx, req.IdempotencyKey, retention); err != nil {
+		return nil, false, err
+	}
+
+	// A dead-lettered event keeps its key until it is requeued or purged:
+	// return it as the original event if the payload matches
+	deadLetterQuery := `
+		SELECT ` + deadLetterColumns + `, request_hash
+		FROM outbox_dead_letters
+		WHERE idempotency_key = $1
+	`
+
+	var deadLetterHash sql.NullString
+	deadLetter, err := scanDeadLetter(rowScannerFunc(func(dest ...interface{}) error {
+		return tx.QueryRowContext(ctx, deadLetterQuery, req.IdempotencyKey).Scan(append(dest, &deadLetterHash)...)
+	}))
+	if err == nil {
+		if deadLetterHash.String != requestHash {
+			return nil, false, outbox.ErrIdempotencyConflict
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return deadLetter.Event(), false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to get dead letter for idempotency key: %w", err)
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+
+	insertQuery := `
+		INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING ` + eventColumns
+
+	event, err := scanEvent(tx.QueryRowContext(ctx, insertQuery, id, req.Type, req.Source, req.Data, metadata, models.StatusPending, now, now, nullString(req.PartitionKey), nullTime(req.DeliverAt), nullTime(req.ExpiresAt), req.Priority, req.IdempotencyKey, requestHash))
+	if err == nil {
+		if err := tx.Commit(); err != nil {
+			return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return event, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to create event: %w", err)
+	}
+
+	// The key is taken: return the original event if the payload matches
+	existingQuery := `
+		SELECT ` + eventColumns + `, request_hash
+		FROM outbox_events
+		WHERE idempotency_key = $1
+	`
+
+	var existingHash sql.NullString
+	existing, err := scanEvent(rowScannerFunc(func(dest ...interface{}) error {
+		return tx.QueryRowContext(ctx, existingQuery, req.IdempotencyKey).Scan(append(dest, &existingHash)...)
+	}))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get event for idempotency key: %w", err)
+	}
+
+	if existingHash.String != requestHash {
+		return nil, false, outbox.ErrIdempotencyConflict
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return existing, false, nil
 }
 
 // GetEvent retrieves an event by ID
-func (s *OutboxStore) GetEvent(id string) (*models.Event, error) {
+func (s *OutboxStore) GetEvent(ctx context.Context, id string) (*models.Event, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
+		SELECT ` + eventColumns + `
 		FROM outbox_events
 		WHERE id = $1
 	`
 
-	var event models.Event
-	var metadataStr sql.NullString
-	var lastErrorStr sql.NullString
-	var publishedAt sql.NullTime
-	var dataStr string
-	err := s.db.conn.QueryRow(query, id).
-		Scan(&event.ID, &event.Type, &event.Source, &dataStr, &metadataStr, &event.Status, &event.RetryCount, &lastErrorStr, &event.CreatedAt, &event.UpdatedAt, &publishedAt)
-
+	event, err := scanEvent(s.db.conn.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("event not found")
@@ -107,162 +328,361 @@ func (s *OutboxStore) GetEvent(id string) (*models.Event, error) {
 		return nil, fmt.Errorf("failed to get event: %w", err)
 	}
 
-	// Handle nullable fields
-	event.Data = json.RawMessage(dataStr)
+	return event, nil
+}
 
-	if metadataStr.Valid {
-		event.Metadata = json.RawMessage(metadataStr.String)
-	} else {
-		event.Metadata = nil
+// ListEvents retrieves events matching query, newest first. Total counts
+// every match regardless of the page.
+func (s *OutboxStore) ListEvents(ctx context.Context, query *models.EventQuery) ([]models.Event, int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	total, err := s.CountEvents(ctx, query)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if lastErrorStr.Valid {
-		event.LastError = lastErrorStr.String
-	} else {
-		event.LastError = ""
+	filter := newEventFilter(query)
+
+	// Keyset condition on the same (created_at, id) order as the listing, so
+	// pages neither skip nor repeat events when new ones are inserted
+	if query.Cursor != nil {
+		filter.add("(created_at, id) < (%s, %s)", query.Cursor.CreatedAt, query.Cursor.ID)
 	}
 
-	if publishedAt.Valid {
-		event.PublishedAt = &publishedAt.Time
-	} else {
-		event.PublishedAt = nil
+	listQuery := `
+		SELECT ` + eventColumns + `
+		FROM outbox_events
+		` + filter.where() + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + filter.arg(query.Limit) + ` OFFSET ` + filter.arg(query.Offset)
+
+	rows, err := s.db.conn.QueryContext(ctx, listQuery, filter.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list events: %w", err)
 	}
+	defer rows.Close()
 
-	return &event, nil
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
 }
 
-// ListEvents retrieves events with pagination and filtering
-func (s *OutboxStore) ListEvents(status *models.EventStatus, page, limit int) ([]models.Event, int, error) {
-	offset := (page - 1) * limit
+// eventFilter accumulates WHERE conditions and their positional arguments
+type eventFilter struct {
+	conditions []string
+	args       []interface{}
+}
 
-	var whereClause string
-	var args []interface{}
-	argIndex := 1
+func newEventFilter(query *models.EventQuery) *eventFilter {
+	f := &eventFilter{}
 
-	if status != nil {
-		whereClause = "WHERE status = $" + fmt.Sprintf("%d", argIndex)
-		args = append(args, string(*status))
-		argIndex++
+	if len(query.IDs) > 0 {
+		f.add("id = ANY(%s)", pq.Array(query.IDs))
+	}
+	if query.Status != nil {
+		f.add("status = %s", string(*query.Status))
+	}
+	if len(query.Statuses) > 0 {
+		statuses := make([]string, len(query.Statuses))
+		for i, status := range query.Statuses {
+			statuses[i] = string(status)
+		}
+		f.add("status = ANY(%s)", pq.Array(statuses))
+	}
+	if query.Type != "" {
+		f.match("type", query.Type)
+	}
+	if query.Source != "" {
+		f.match("source", query.Source)
+	}
+	if query.PartitionKey != "" {
+		f.add("partition_key = %s", query.PartitionKey)
+	}
+	if query.CreatedAfter != nil {
+		f.add("created_at >= %s", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		f.add("created_at < %s", *query.CreatedBefore)
+	}
+	if query.PublishedAfter != nil {
+		f.add("published_at >= %s", *query.PublishedAfter)
+	}
+	if query.PublishedBefore != nil {
+		f.add("published_at < %s", *query.PublishedBefore)
+	}
+	if query.DeliverAfter != nil {
+		f.add("deliver_at >= %s", *query.DeliverAfter)
+	}
+	if query.DeliverBefore != nil {
+		f.add("deliver_at < %s", *query.DeliverBefore)
+	}
+	if query.ExpiresAfter != nil {
+		f.add("expires_at >= %s", *query.ExpiresAfter)
+	}
+	if query.ExpiresBefore != nil {
+		f.add("expires_at < %s", *query.ExpiresBefore)
+	}
+	if query.MinRetryCount != nil {
+		f.add("retry_count >= %s", *query.MinRetryCount)
+	}
+	if query.MaxRetryCount != nil {
+		f.add("retry_count <= %s", *query.MaxRetryCount)
+	}
+	if query.ErrorContains != "" {
+		f.add("last_error ILIKE %s", "%"+escapeLike(query.ErrorContains)+"%")
+	}
+	if len(query.Metadata) > 0 {
+		f.add("metadata @> %s::jsonb", string(query.Metadata))
 	}
 
-	countQuery := "SELECT COUNT(*) FROM outbox_events " + whereClause
-	var total int
-	err := s.db.conn.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count events: %w", err)
+	return f
+}
+
+// arg appends a query argument and returns its placeholder
+func (f *eventFilter) arg(value interface{}) string {
+	f.args = append(f.args, value)
+	return fmt.Sprintf("$%d", len(f.args))
+}
+
+// add appends a condition, substituting a placeholder for each %s
+func (f *eventFilter) add(condition string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = f.arg(value)
 	}
+	f.conditions = append(f.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+// match compares column to pattern exactly, or by prefix when the pattern
+// ends in "*"
+func (f *eventFilter) match(column, pattern string) {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		f.add(column+" LIKE %s", escapeLike(prefix)+"%")
+		return
+	}
+	f.add(column+" = %s", pattern)
+}
+
+func (f *eventFilter) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(f.conditions, " AND ")
+}
+
+// escapeLike makes LIKE wildcards in value match literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// GetPendingEvents retrieves events ready for publishing, most urgent first.
+// Events gain a priority level every aging interval they wait. Scheduled
+// events are left out until their deliver_at, and expired events altogether.
+func (s *OutboxStore) GetPendingEvents(ctx context.Context, limit int, aging time.Duration) ([]models.Event, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
 
 	query := `
-		SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
+		SELECT ` + eventColumns + `
 		FROM outbox_events
-		` + whereClause + `
-		ORDER BY created_at DESC
-		LIMIT $` + fmt.Sprintf("%d", argIndex) + ` OFFSET $` + fmt.Sprintf("%d", argIndex+1)
-
-	args = append(args, limit, offset)
+		WHERE ` + readyCondition + `
+		ORDER BY ` + urgency("$2") + ` DESC, created_at, id
+		LIMIT $1
+	`
 
-	rows, err := s.db.conn.Query(query, args...)
+	rows, err := s.db.conn.QueryContext(ctx, query, limit, agingRate(aging))
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list events: %w", err)
+		return nil, fmt.Errorf("failed to get pending events: %w", err)
 	}
 	defer rows.Close()
 
-	var events []models.Event
-	for rows.Next() {
-		var event models.Event
-		var metadataStr sql.NullString
-		var lastErrorStr sql.NullString
-		var publishedAt sql.NullTime
-		var dataStr string
-		err := rows.Scan(&event.ID, &event.Type, &event.Source, &dataStr, &metadataStr, &event.Status, &event.RetryCount, &lastErrorStr, &event.CreatedAt, &event.UpdatedAt, &publishedAt)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan event: %w", err)
-		}
+	return scanEvents(rows)
+}
 
-		// Handle nullable fields
-		event.Data = json.RawMessage(dataStr)
+// ClaimPendingEvents atomically leases up to limit pending events to workerID.
+// Leases that have expired (e.g. the worker crashed) are reclaimed, so each
+// event is handed to exactly one worker at a time.
+//
+// The most urgent events are claimed first: those with the highest priority,
+// where every aging interval an event has waited counts as one more level.
+//
+// Events with a partition key are claimed in order: an event is only claimed
+// when every earlier event with the same key is claimable too, so one that is
+// leased, scheduled for later, waiting to retry or failed holds back the rest
+// of its key. Claims are serialized with an advisory lock because that check
+// spans rows. An event is as urgent as the most urgent claimable event queued
+// behind it on its key, so an urgent event pulls the ones ahead of it along
+// and a limited batch never takes a later event without the earlier ones.
+//
+// Events past their expires_at are moved to the expired status first, so
+// they are never delivered late and no longer hold back their key.
+func (s *OutboxStore) ClaimPendingEvents(ctx context.Context, workerID string, limit int, lease, aging time.Duration) ([]models.Event, error) {
+	query := `
+		SELECT id
+		FROM (
+			SELECT id, created_at,
+				max(urgency) OVER (PARTITION BY COALESCE(partition_key, id) ORDER BY created_at DESC, id DESC) AS urgency
+			FROM (
+				SELECT id, created_at, partition_key, urgency,
+					bool_and(ready) OVER (PARTITION BY COALESCE(partition_key, id) ORDER BY created_at, id) AS unblocked
+				FROM (
+					SELECT id, created_at, partition_key, (` + readyCondition + `) AS ready,
+						` + urgency("$4") + ` AS urgency
+					FROM outbox_events
+					WHERE status IN ('pending', 'retrying', 'failed')
+				) unfinished
+			) ordered
+			WHERE unblocked
+		) inherited
+		ORDER BY urgency DESC, created_at, id
+		LIMIT $3`
+
+	return s.claimEvents(ctx, workerID, lease, query, limit, agingRate(aging))
+}
 
-		if metadataStr.Valid {
-			event.Metadata = json.RawMessage(metadataStr.String)
-		} else {
-			event.Metadata = nil
-		}
+// ClaimEvents atomically leases the events with the given IDs to workerID,
+// under the same rules as ClaimPendingEvents, and returns those it claimed.
+// An event is only claimed if its status is one of statuses and it is due,
+// and, when it has a partition key, every earlier unfinished event with that
+// key is claimed along with it. The others are left alone.
+func (s *OutboxStore) ClaimEvents(ctx context.Context, workerID string, ids []string, statuses []models.EventStatus, lease time.Duration) ([]models.Event, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
 
-		if lastErrorStr.Valid {
-			event.LastError = lastErrorStr.String
-		} else {
-			event.LastError = ""
-		}
+	wanted := make([]string, len(statuses))
+	for i, status := range statuses {
+		wanted[i] = string(status)
+	}
 
-		if publishedAt.Valid {
-			event.PublishedAt = &publishedAt.Time
-		} else {
-			event.PublishedAt = nil
-		}
+	query := `
+		SELECT id
+		FROM (
+			SELECT id,
+				bool_and(ready AND id = ANY($3)) OVER (PARTITION BY COALESCE(partition_key, id) ORDER BY created_at, id) AS unblocked
+			FROM (
+				SELECT id, created_at, partition_key, (status = ANY($4)
+					AND ` + dueCondition + `) AS ready
+				FROM outbox_events
+				WHERE status IN ('pending', 'retrying', 'failed')
+					AND (id = ANY($3) OR partition_key IN (
+						SELECT partition_key FROM outbox_events WHERE id = ANY($3)
+					))
+			) unfinished
+		) ordered
+		WHERE unblocked`
+
+	return s.claimEvents(ctx, workerID, lease, query, pq.Array(ids), pq.Array(wanted))
+}
+
+// claimEvents leases the events whose IDs selectQuery returns to workerID and
+// returns them in creation order. selectQuery's own parameters start at $3.
+func (s *OutboxStore) claimEvents(ctx context.Context, workerID string, lease time.Duration, selectQuery string, args ...interface{}) ([]models.Event, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
 
-		events = append(events, event)
+	tx, err := s.db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return events, total, nil
-}
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", claimLockID); err != nil {
+		return nil, fmt.Errorf("failed to lock claims: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, expireEventsQuery); err != nil {
+		return nil, fmt.Errorf("failed to expire events: %w", err)
+	}
 
-// GetPendingEvents retrieves events ready for publishing
-func (s *OutboxStore) GetPendingEvents(limit int) ([]models.Event, error) {
 	query := `
-		SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
-		FROM outbox_events
-		WHERE status IN ('pending', 'retrying')
-		ORDER BY created_at ASC
-		LIMIT $1
-	`
+		UPDATE outbox_events
+		SET locked_by = $1, locked_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (` + selectQuery + `
+		)
+		RETURNING ` + eventColumns
 
-	rows, err := s.db.conn.Query(query, limit)
+	rows, err := tx.QueryContext(ctx, query, append([]interface{}{workerID, lease.Seconds()}, args...)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pending events: %w", err)
+		return nil, fmt.Errorf("failed to claim events: %w", err)
 	}
 	defer rows.Close()
 
-	var events []models.Event
-	for rows.Next() {
-		var event models.Event
-		var metadataStr sql.NullString
-		var lastErrorStr sql.NullString
-		var publishedAt sql.NullTime
-		var dataStr string
-		err := rows.Scan(&event.ID, &event.Type, &event.Source, &dataStr, &metadataStr, &event.Status, &event.RetryCount, &lastErrorStr, &event.CreatedAt, &event.UpdatedAt, &publishedAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
-		}
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
 
-		// Handle nullable fields
-		event.Data = json.RawMessage(dataStr)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-		if metadataStr.Valid {
-			event.Metadata = json.RawMessage(metadataStr.String)
-		} else {
-			event.Metadata = nil
+	// RETURNING does not preserve the subquery order. Within the batch events
+	// are published in creation order, which partition keys rely on.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].ID < events[j].ID
 		}
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
 
-		if lastErrorStr.Valid {
-			event.LastError = lastErrorStr.String
-		} else {
-			event.LastError = ""
-		}
+	return events, nil
+}
 
-		if publishedAt.Valid {
-			event.PublishedAt = &publishedAt.Time
-		} else {
-			event.PublishedAt = nil
-		}
+// ReleaseEvents drops workerID's leases on events that were claimed but not
+// attempted, so they can be claimed again without waiting for the lease to
+// expire. Events leased to another worker meanwhile are left alone.
+func (s *OutboxStore) ReleaseEvents(ctx context.Context, workerID string, ids []string) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
 
-		events = append(events, event)
+	if len(ids) == 0 {
+		return nil
 	}
 
-	return events, nil
+	_, err := s.db.conn.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET locked_by = NULL, locked_until = NULL
+		WHERE id = ANY($1) AND locked_by = $2
+	`, pq.Array(ids), workerID)
+	if err != nil {
+		return fmt.Errorf("failed to release events: %w", err)
+	}
+
+	return nil
+}
+
+// RenewLease extends workerID's lease on an event to lease from now, returning
+// ErrLeaseLost if workerID no longer holds it
+func (s *OutboxStore) RenewLease(ctx context.Context, workerID, id string, lease time.Duration) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE outbox_events
+		SET locked_until = NOW() + make_interval(secs => $3)
+		WHERE id = $1 AND locked_by = $2
+	`
+
+	result, err := s.db.conn.ExecContext(ctx, query, id, workerID, lease.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to renew lease on event %s: %w", id, err)
+	}
+
+	return leaseHeld(result)
 }
 
-// UpdateEventStatus updates an event's status and related fields
-func (s *OutboxStore) UpdateEventStatus(id string, status models.EventStatus, lastError string, retryCount int) error {
+// UpdateEventStatus updates an event leased to workerID with its status and
+// related fields and releases the lease, returning ErrLeaseLost if workerID
+// no longer holds it
+func (s *OutboxStore) UpdateEventStatus(ctx context.Context, workerID, id string, status models.EventStatus, lastError string, retryCount int) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
 	now := time.Now()
 	var publishedAt interface{}
 
@@ -272,22 +692,63 @@ func (s *OutboxStore) UpdateEventStatus(id string, status models.EventStatus, la
 
 	query := `
 		UPDATE outbox_events
-		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5
-		WHERE id = $6
+		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5,
+			next_attempt_at = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = $6 AND locked_by = $7
 	`
 
-	_, err := s.db.conn.Exec(query, status, lastError, retryCount, now, publishedAt, id)
+	result, err := s.db.conn.ExecContext(ctx, query, status, lastError, retryCount, now, publishedAt, id, workerID)
 	if err != nil {
 		return fmt.Errorf("failed to update event status: %w", err)
 	}
 
+	return leaseHeld(result)
+}
+
+// ScheduleRetry moves an event leased to workerID to retrying and holds it
+// back from publishers until nextAttemptAt, releasing the lease. It returns
+// ErrLeaseLost if workerID no longer holds the lease.
+func (s *OutboxStore) ScheduleRetry(ctx context.Context, workerID, id string, lastError string, retryCount int, nextAttemptAt time.Time) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE outbox_events
+		SET status = $1, last_error = $2, retry_count = $3, next_attempt_at = $4, updated_at = $5,
+			locked_by = NULL, locked_until = NULL
+		WHERE id = $6 AND locked_by = $7
+	`
+
+	result, err := s.db.conn.ExecContext(ctx, query, models.StatusRetrying, lastError, retryCount, nextAttemptAt, time.Now(), id, workerID)
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry for event %s: %w", id, err)
+	}
+
+	return leaseHeld(result)
+}
+
+// leaseHeld returns ErrLeaseLost if a write conditioned on holding an event's
+// lease matched no row
+func leaseHeld(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrLeaseLost
+	}
+
 	return nil
 }
 
 // DeleteEvent deletes an event by ID
-func (s *OutboxStore) DeleteEvent(id string) error {
+func (s *OutboxStore) DeleteEvent(ctx context.Context, id string) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
 	query := "DELETE FROM outbox_events WHERE id = $1"
-	result, err := s.db.conn.Exec(query, id)
+	result, err := s.db.conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete event: %w", err)
 	}
@@ -305,23 +766,32 @@ func (s *OutboxStore) DeleteEvent(id string) error {
 }
 
 // GetStats returns event statistics
-func (s *OutboxStore) GetStats() (*models.StatsResponse, error) {
+func (s *OutboxStore) GetStats(ctx context.Context) (*models.StatsResponse, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			COUNT(*) as total_events,
 			COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending_events,
 			COUNT(CASE WHEN status = 'published' THEN 1 END) as published_events,
 			COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed_events,
+			COUNT(CASE WHEN status = 'retrying' THEN 1 END) as retrying_events,
+			COUNT(CASE WHEN status = 'expired' THEN 1 END) as expired_events,
+			(SELECT COUNT(*) FROM outbox_dead_letters) as dead_letter_events,
 			COALESCE(SUM(retry_count), 0) as retry_count
 		FROM outbox_events
 	`
 
 	var stats models.StatsResponse
-	err := s.db.conn.QueryRow(query).Scan(
+	err := s.db.conn.QueryRowContext(ctx, query).Scan(
 		&stats.TotalEvents,
 		&stats.PendingEvents,
 		&stats.PublishedEvents,
 		&stats.FailedEvents,
+		&stats.RetryingEvents,
+		&stats.ExpiredEvents,
+		&stats.DeadLetterEvents,
 		&stats.RetryCount,
 	)
 
@@ -329,11 +799,37 @@ func (s *OutboxStore) GetStats() (*models.StatsResponse, error) {
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
 
+	rows, err := s.db.conn.QueryContext(ctx, `
+		SELECT priority, COUNT(*)
+		FROM outbox_events
+		WHERE status = 'pending'
+		GROUP BY priority
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending events by priority: %w", err)
+	}
+	defer rows.Close()
+
+	stats.PendingByPriority = make(map[int]int)
+	for rows.Next() {
+		var priority, count int
+		if err := rows.Scan(&priority, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan pending events by priority: %w", err)
+		}
+		stats.PendingByPriority[priority] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pending events by priority: %w", err)
+	}
+
 	return &stats, nil
 }
 
 // UpdateEventPublishedAt updates the published_at timestamp for an event
-func (s *OutboxStore) UpdateEventPublishedAt(id string, publishedAt *time.Time) error {
+func (s *OutboxStore) UpdateEventPublishedAt(ctx context.Context, id string, publishedAt *time.Time) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE outbox_events 
 		SET published_at = $1, updated_at = $2
@@ -341,7 +837,7 @@ func (s *OutboxStore) UpdateEventPublishedAt(id string, publishedAt *time.Time)
 	`
 
 	now := time.Now()
-	_, err := s.db.conn.Exec(query, publishedAt, now, id)
+	_, err := s.db.conn.ExecContext(ctx, query, publishedAt, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to update published_at for event %s: %w", id, err)
 	}
@@ -1,13 +1,16 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,6 +23,9 @@ func setupMockDB(t *testing.T) (*DB, sqlmock.Sqlmock) {
 }
 
 func TestOutboxStore_CreateEvent(t *testing.T) {
+	deliverAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := deliverAt.Add(time.Hour)
+
 	tests := []struct {
 		name          string
 		request       *models.CreateEventRequest
@@ -36,12 +42,12 @@ func TestOutboxStore_CreateEvent(t *testing.T) {
 				Metadata: json.RawMessage(`{"version": "1.0"}`),
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at`).
-					WithArgs(sqlmock.AnyArg(), "test.event", "test-service", sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", sqlmock.AnyArg(), sqlmock.AnyArg()).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at"}).
-						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, `{"version": "1.0"}`, "pending", 0, nil, time.Now(), time.Now(), nil))
+				mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`).
+					WithArgs(sqlmock.AnyArg(), "test.event", "test-service", sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, `{"version": "1.0"}`, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0))
 			},
 			expectedEvent: &models.Event{
 				ID:          "test-id",
@@ -64,12 +70,12 @@ func TestOutboxStore_CreateEvent(t *testing.T) {
 				Metadata: nil,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at`).
-					WithArgs(sqlmock.AnyArg(), "test.event", "test-service", sqlmock.AnyArg(), nil, "pending", sqlmock.AnyArg(), sqlmock.AnyArg()).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at"}).
-						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil))
+				mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`).
+					WithArgs(sqlmock.AnyArg(), "test.event", "test-service", sqlmock.AnyArg(), nil, "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0))
 			},
 			expectedEvent: &models.Event{
 				ID:          "test-id",
@@ -83,6 +89,70 @@ func TestOutboxStore_CreateEvent(t *testing.T) {
 				PublishedAt: nil,
 			},
 		},
+		{
+			name: "event creation with partition key",
+			request: &models.CreateEventRequest{
+				Type:         "test.event",
+				Source:       "test-service",
+				Data:         json.RawMessage(`{"message": "hello"}`),
+				PartitionKey: "order-123",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`).
+					WithArgs(sqlmock.AnyArg(), "test.event", "test-service", sqlmock.AnyArg(), nil, "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), "order-123", nil, nil, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, "order-123", nil, nil, 0))
+			},
+			expectedEvent: &models.Event{
+				ID:           "test-id",
+				Type:         "test.event",
+				Source:       "test-service",
+				Data:         json.RawMessage(`{"message": "hello"}`),
+				Status:       models.StatusPending,
+				PartitionKey: "order-123",
+			},
+		},
+		{
+			name: "scheduled event creation",
+			request: &models.CreateEventRequest{
+				Type:      "test.event",
+				Source:    "test-service",
+				Data:      json.RawMessage(`{"message": "hello"}`),
+				DeliverAt: &deliverAt,
+				ExpiresAt: &expiresAt,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`).
+					WithArgs(sqlmock.AnyArg(), "test.event", "test-service", sqlmock.AnyArg(), nil, "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, deliverAt, expiresAt, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, deliverAt, expiresAt, 0))
+			},
+			expectedEvent: &models.Event{
+				ID:        "test-id",
+				Type:      "test.event",
+				Source:    "test-service",
+				Data:      json.RawMessage(`{"message": "hello"}`),
+				Status:    models.StatusPending,
+				DeliverAt: &deliverAt,
+				ExpiresAt: &expiresAt,
+			},
+		},
+		{
+			name: "expiry before delivery",
+			request: &models.CreateEventRequest{
+				Type:      "test.event",
+				Source:    "test-service",
+				Data:      json.RawMessage(`{"message": "hello"}`),
+				DeliverAt: &expiresAt,
+				ExpiresAt: &deliverAt,
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "expires_at must be after deliver_at",
+		},
 		{
 			name: "invalid JSON in data field",
 			request: &models.CreateEventRequest{
@@ -115,7 +185,7 @@ func TestOutboxStore_CreateEvent(t *testing.T) {
 			store := NewOutboxStore(db)
 			tt.mockSetup(mock)
 
-			event, err := store.CreateEvent(tt.request)
+			event, err := store.CreateEvent(context.Background(), tt.request)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -132,6 +202,173 @@ func TestOutboxStore_CreateEvent(t *testing.T) {
 				assert.Equal(t, tt.expectedEvent.Status, event.Status)
 				assert.Equal(t, tt.expectedEvent.RetryCount, event.RetryCount)
 				assert.Equal(t, tt.expectedEvent.LastError, event.LastError)
+				assert.Equal(t, tt.expectedEvent.PartitionKey, event.PartitionKey)
+				assert.Equal(t, tt.expectedEvent.DeliverAt, event.DeliverAt)
+				assert.Equal(t, tt.expectedEvent.ExpiresAt, event.ExpiresAt)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_CreateEventIdempotent(t *testing.T) {
+	request := &models.CreateEventRequest{
+		Type:           "order.created",
+		Source:         "order-service",
+		Data:           json.RawMessage(`{"order_id": "123"}`),
+		IdempotencyKey: "order-123",
+	}
+	requestHash, err := request.PayloadHash()
+	require.NoError(t, err)
+
+	expireQuery := `WITH dead_letters AS (
+			UPDATE outbox_dead_letters
+			SET idempotency_key = NULL, request_hash = NULL
+			WHERE idempotency_key = $1 AND created_at < $2
+		)
+		UPDATE outbox_events
+		SET idempotency_key = NULL, request_hash = NULL
+		WHERE idempotency_key = $1 AND created_at < $2`
+	deadLetterQuery := `SELECT id, type, source, data, metadata, retry_count, last_error, created_at, dead_lettered_at, partition_key, deliver_at, expires_at, priority, request_hash
+		FROM outbox_dead_letters
+		WHERE idempotency_key = $1`
+	deadLetterColumns := []string{"id", "type", "source", "data", "metadata", "retry_count", "last_error", "created_at", "dead_lettered_at", "partition_key", "deliver_at", "expires_at", "priority", "request_hash"}
+	insertQuery := `INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`
+	existingQuery := `SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority, request_hash
+		FROM outbox_events
+		WHERE idempotency_key = $1`
+	columns := []string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}
+
+	tests := []struct {
+		name            string
+		request         *models.CreateEventRequest
+		mockSetup       func(sqlmock.Sqlmock)
+		expectedError   string
+		expectedID      string
+		expectedCreated bool
+	}{
+		{
+			name:    "new key creates event",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(expireQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(sqlmock.NewRows(deadLetterColumns))
+				mock.ExpectQuery(insertQuery).
+					WithArgs(sqlmock.AnyArg(), "order.created", "order-service", sqlmock.AnyArg(), nil, "pending", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, 0, "order-123", requestHash).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("new-id", "order.created", "order-service", `{"order_id": "123"}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0))
+				mock.ExpectCommit()
+			},
+			expectedID:      "new-id",
+			expectedCreated: true,
+		},
+		{
+			name:    "retried request returns original event",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(expireQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(sqlmock.NewRows(deadLetterColumns))
+				mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectQuery(existingQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows(append(columns, "request_hash")).
+						AddRow("original-id", "order.created", "order-service", `{"order_id": "123"}`, nil, "published", 0, nil, time.Now(), time.Now(), time.Now(), nil, nil, nil, nil, 0, requestHash))
+				mock.ExpectCommit()
+			},
+			expectedID:      "original-id",
+			expectedCreated: false,
+		},
+		{
+			name:    "key reused with different payload",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(expireQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(sqlmock.NewRows(deadLetterColumns))
+				mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectQuery(existingQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows(append(columns, "request_hash")).
+						AddRow("original-id", "order.created", "order-service", `{"order_id": "456"}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0, "other-hash"))
+				mock.ExpectRollback()
+			},
+			expectedError: "idempotency key already used with a different payload",
+		},
+		{
+			name:    "retried request returns dead-lettered event",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(expireQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows(deadLetterColumns).
+						AddRow("dead-id", "order.created", "order-service", `{"order_id": "123"}`, nil, 4, "webhook returned status 500", time.Now(), time.Now(), nil, nil, nil, 0, requestHash))
+				mock.ExpectCommit()
+			},
+			expectedID:      "dead-id",
+			expectedCreated: false,
+		},
+		{
+			name:    "dead-lettered key reused with different payload",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(expireQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows(deadLetterColumns).
+						AddRow("dead-id", "order.created", "order-service", `{"order_id": "456"}`, nil, 4, "webhook returned status 500", time.Now(), time.Now(), nil, nil, nil, 0, "other-hash"))
+				mock.ExpectRollback()
+			},
+			expectedError: "idempotency key already used with a different payload",
+		},
+		{
+			name: "invalid JSON in data field",
+			request: &models.CreateEventRequest{
+				Type:           "order.created",
+				Source:         "order-service",
+				Data:           json.RawMessage(`invalid json`),
+				IdempotencyKey: "order-123",
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid JSON in data field",
+		},
+		{
+			name:    "insert error",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(expireQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(sqlmock.NewRows(deadLetterColumns))
+				mock.ExpectQuery(insertQuery).WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: "failed to create event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			event, created, err := store.CreateEventIdempotent(context.Background(), tt.request, 24*time.Hour)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, event)
+				assert.False(t, created)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedID, event.ID)
+				assert.Equal(t, tt.expectedCreated, created)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -151,12 +388,12 @@ func TestOutboxStore_GetEvent(t *testing.T) {
 			name:    "successful event retrieval",
 			eventID: "test-id",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
+				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
 		FROM outbox_events
 		WHERE id = $1`).
 					WithArgs("test-id").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at"}).
-						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, `{"version": "1.0"}`, "pending", 0, nil, time.Now(), time.Now(), nil))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+						AddRow("test-id", "test.event", "test-service", `{"message": "hello"}`, `{"version": "1.0"}`, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0))
 			},
 			expectedEvent: &models.Event{
 				ID:          "test-id",
@@ -174,7 +411,7 @@ func TestOutboxStore_GetEvent(t *testing.T) {
 			name:    "event not found",
 			eventID: "non-existent-id",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
+				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
 		FROM outbox_events
 		WHERE id = $1`).
 					WithArgs("non-existent-id").
@@ -192,7 +429,7 @@ func TestOutboxStore_GetEvent(t *testing.T) {
 			store := NewOutboxStore(db)
 			tt.mockSetup(mock)
 
-			event, err := store.GetEvent(tt.eventID)
+			event, err := store.GetEvent(context.Background(), tt.eventID)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -214,63 +451,180 @@ func TestOutboxStore_GetEvent(t *testing.T) {
 	}
 }
 
+func TestOutboxStore_QueryTimeout(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+	db.queryTimeout = 20 * time.Millisecond
+
+	mock.ExpectQuery(`SELECT ` + eventColumns + `
+		FROM outbox_events
+		WHERE id = $1`).
+		WithArgs("slow-id").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	started := time.Now()
+	_, err := NewOutboxStore(db).GetEvent(context.Background(), "slow-id")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get event")
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestOutboxStore_CancelledContext(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM outbox_events WHERE id = $1`).
+		WithArgs("event-id").
+		WillDelayFor(time.Second).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	started := time.Now()
+	err := NewOutboxStore(db).DeleteEvent(ctx, "event-id")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete event")
+	assert.Less(t, time.Since(started), time.Second)
+}
+
 func TestOutboxStore_ListEvents(t *testing.T) {
+	eventRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"})
+	}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	minRetries := 2
+
 	tests := []struct {
 		name          string
-		status        *models.EventStatus
-		page          int
-		limit         int
+		query         *models.EventQuery
 		mockSetup     func(sqlmock.Sqlmock)
 		expectedError string
 		expectedCount int
 		expectedTotal int
 	}{
 		{
-			name:   "list all events",
-			status: nil,
-			page:   1,
-			limit:  10,
+			name:  "list all events",
+			query: &models.EventQuery{Limit: 10},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Count query
 				mock.ExpectQuery("SELECT COUNT(*) FROM outbox_events").
 					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 
 				// List query
-				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
+				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
 		FROM outbox_events
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT $1 OFFSET $2`).
 					WithArgs(10, 0).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at"}).
-						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil).
-						AddRow("event-2", "test.event", "test-service", `{"id": 2}`, nil, "published", 0, nil, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+					WillReturnRows(eventRows().
+						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0).
+						AddRow("event-2", "test.event", "test-service", `{"id": 2}`, nil, "published", 0, nil, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour), time.Now().Add(-time.Hour), nil, nil, nil, nil, 0))
 			},
 			expectedCount: 2,
 			expectedTotal: 2,
 		},
 		{
-			name:   "list events by status",
-			status: func() *models.EventStatus { s := models.StatusPending; return &s }(),
-			page:   1,
-			limit:  10,
+			name:  "list events by status",
+			query: &models.EventQuery{Status: func() *models.EventStatus { s := models.StatusPending; return &s }(), Offset: 10, Limit: 10},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Count query
 				mock.ExpectQuery("SELECT COUNT(*) FROM outbox_events WHERE status = $1").
 					WithArgs("pending").
-					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
 
 				// List query
-				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at
+				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
 		FROM outbox_events
 		WHERE status = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT $2 OFFSET $3`).
-					WithArgs("pending", 10, 0).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at"}).
-						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil))
+					WithArgs("pending", 10, 10).
+					WillReturnRows(eventRows().
+						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "pending", 0, nil, time.Now(), time.Now(), nil, nil, nil, nil, nil, 0))
 			},
 			expectedCount: 1,
-			expectedTotal: 1,
+			expectedTotal: 11,
+		},
+		{
+			name: "all filters",
+			query: &models.EventQuery{
+				Type:            "order.*",
+				Source:          "billing_service",
+				PartitionKey:    "order-1",
+				CreatedAfter:    &since,
+				CreatedBefore:   &until,
+				PublishedAfter:  &since,
+				PublishedBefore: &until,
+				DeliverAfter:    &since,
+				DeliverBefore:   &until,
+				ExpiresAfter:    &since,
+				ExpiresBefore:   &until,
+				MinRetryCount:   &minRetries,
+				ErrorContains:   "100% timeout",
+				Metadata:        json.RawMessage(`{"tenant":"acme"}`),
+				Limit:           5,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				where := `WHERE type LIKE $1 AND source = $2 AND partition_key = $3
+					AND created_at >= $4 AND created_at < $5
+					AND published_at >= $6 AND published_at < $7
+					AND deliver_at >= $8 AND deliver_at < $9
+					AND expires_at >= $10 AND expires_at < $11
+					AND retry_count >= $12 AND last_error ILIKE $13 AND metadata @> $14::jsonb`
+				args := []driver.Value{`order.%`, "billing_service", "order-1", since, until, since, until, since, until, since, until, 2, `%100\% timeout%`, `{"tenant":"acme"}`}
+
+				mock.ExpectQuery("SELECT COUNT(*) FROM outbox_events " + where).
+					WithArgs(args...).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
+		FROM outbox_events ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $15 OFFSET $16`).
+					WithArgs(append(args, 5, 0)...).
+					WillReturnRows(eventRows())
+			},
+			expectedCount: 0,
+			expectedTotal: 0,
+		},
+		{
+			name: "after cursor",
+			query: &models.EventQuery{
+				Status: func() *models.EventStatus { s := models.StatusFailed; return &s }(),
+				Cursor: &models.EventCursor{CreatedAt: since, ID: "event-9"},
+				Limit:  3,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				// The total ignores the cursor
+				mock.ExpectQuery("SELECT COUNT(*) FROM outbox_events WHERE status = $1").
+					WithArgs("failed").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+				mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
+		FROM outbox_events
+		WHERE status = $1 AND (created_at, id) < ($2, $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5`).
+					WithArgs("failed", since, "event-9", 3, 0).
+					WillReturnRows(eventRows().
+						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "failed", 3, "timeout", since.Add(-time.Minute), since, nil, nil, nil, nil, nil, 0))
+			},
+			expectedCount: 1,
+			expectedTotal: 4,
+		},
+		{
+			name:  "count error",
+			query: &models.EventQuery{Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(*) FROM outbox_events").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to count events",
 		},
 	}
 
@@ -282,7 +636,7 @@ func TestOutboxStore_ListEvents(t *testing.T) {
 			store := NewOutboxStore(db)
 			tt.mockSetup(mock)
 
-			events, total, err := store.ListEvents(tt.status, tt.page, tt.limit)
+			events, total, err := store.ListEvents(context.Background(), tt.query)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -298,6 +652,290 @@ func TestOutboxStore_ListEvents(t *testing.T) {
 	}
 }
 
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\% off\_sale \\o/`, escapeLike(`50% off_sale \o/`))
+}
+
+func TestOutboxStore_GetPendingEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
+		FROM outbox_events
+		WHERE status IN ('pending', 'retrying')
+		AND (deliver_at IS NULL OR deliver_at <= NOW())
+		AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+		AND (expires_at IS NULL OR expires_at > NOW())
+		AND (locked_until IS NULL OR locked_until < NOW())
+		ORDER BY priority + EXTRACT(EPOCH FROM NOW() - COALESCE(deliver_at, created_at)) * $2 DESC, created_at, id
+		LIMIT $1`).
+		WithArgs(10, float64(1)/30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+			AddRow("event-2", "payment.succeeded", "payments", `{"id": 2}`, nil, "pending", 0, nil, now, now, nil, nil, nil, nil, nil, 9).
+			AddRow("event-1", "report.generated", "reports", `{"id": 1}`, nil, "pending", 0, nil, now.Add(-time.Minute), now, nil, nil, nil, nil, nil, 0))
+
+	store := NewOutboxStore(db)
+	events, err := store.GetPendingEvents(context.Background(), 10, 30*time.Second)
+
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, 9, events[0].Priority)
+	assert.Equal(t, 0, events[1].Priority)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgingRate(t *testing.T) {
+	assert.Equal(t, float64(1)/60, agingRate(time.Minute))
+	assert.Zero(t, agingRate(0))
+}
+
+func TestOutboxStore_ClaimPendingEvents(t *testing.T) {
+	lockQuery := "SELECT pg_advisory_xact_lock($1)"
+	expireQuery := `UPDATE outbox_events
+		SET status = 'expired', next_attempt_at = NULL, updated_at = NOW()
+		WHERE status IN ('pending', 'retrying')
+			AND expires_at <= NOW()
+			AND (locked_until IS NULL OR locked_until < NOW())`
+	claimQuery := `UPDATE outbox_events
+		SET locked_by = $1, locked_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM (
+				SELECT id, created_at,
+					max(urgency) OVER (PARTITION BY COALESCE(partition_key, id) ORDER BY created_at DESC, id DESC) AS urgency
+				FROM (
+					SELECT id, created_at, partition_key, urgency,
+						bool_and(ready) OVER (PARTITION BY COALESCE(partition_key, id) ORDER BY created_at, id) AS unblocked
+					FROM (
+						SELECT id, created_at, partition_key, (status IN ('pending', 'retrying')
+		AND (deliver_at IS NULL OR deliver_at <= NOW())
+		AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+		AND (expires_at IS NULL OR expires_at > NOW())
+		AND (locked_until IS NULL OR locked_until < NOW())) AS ready,
+							priority + EXTRACT(EPOCH FROM NOW() - COALESCE(deliver_at, created_at)) * $4 AS urgency
+						FROM outbox_events
+						WHERE status IN ('pending', 'retrying', 'failed')
+					) unfinished
+				) ordered
+				WHERE unblocked
+			) inherited
+			ORDER BY urgency DESC, created_at, id
+			LIMIT $3
+		)
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`
+	columns := []string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}
+
+	tests := []struct {
+		name          string
+		limit         int
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+		expectedIDs   []string
+	}{
+		{
+			name: "claims events in creation order",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(expireQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(claimQuery).
+					WithArgs("worker-1", float64(30), 10, float64(1)/60).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("event-2", "test.event", "test-service", `{"id": 2}`, nil, "pending", 0, nil, now, now, nil, nil, nil, nil, nil, 0).
+						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "retrying", 1, "timeout", now.Add(-time.Minute), now, nil, nil, nil, nil, nil, 0))
+				mock.ExpectCommit()
+			},
+			expectedIDs: []string{"event-1", "event-2"},
+		},
+		{
+			name: "events created together are ordered by ID",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(expireQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(claimQuery).
+					WithArgs("worker-1", float64(30), 10, float64(1)/60).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("event-b", "test.event", "test-service", `{"id": 2}`, nil, "pending", 0, nil, now, now, nil, nil, "order-1", nil, nil, 0).
+						AddRow("event-a", "test.event", "test-service", `{"id": 1}`, nil, "pending", 0, nil, now, now, nil, nil, "order-1", nil, nil, 0))
+				mock.ExpectCommit()
+			},
+			expectedIDs: []string{"event-a", "event-b"},
+		},
+		{
+			// order-1 holds a priority 0 event followed by a priority 9 one.
+			// The urgent event lends its priority to the one ahead of it, so
+			// a batch of one takes the earlier event rather than jumping it.
+			name:  "urgent event claims earlier events with its key first",
+			limit: 1,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(expireQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(claimQuery).
+					WithArgs("worker-1", float64(30), 1, float64(1)/60).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "pending", 0, nil, now.Add(-time.Minute), now, nil, nil, "order-1", nil, nil, 0))
+				mock.ExpectCommit()
+			},
+			expectedIDs: []string{"event-1"},
+		},
+		{
+			name: "nothing to claim",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(expireQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(claimQuery).
+					WithArgs("worker-1", float64(30), 10, float64(1)/60).
+					WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "lock error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: "failed to lock claims",
+		},
+		{
+			name: "expire error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(expireQuery).WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: "failed to expire events",
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(lockQuery).WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(expireQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(claimQuery).
+					WithArgs("worker-1", float64(30), 10, float64(1)/60).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: "failed to claim events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			limit := tt.limit
+			if limit == 0 {
+				limit = 10
+			}
+			events, err := store.ClaimPendingEvents(context.Background(), "worker-1", limit, 30*time.Second, time.Minute)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				var ids []string
+				for _, event := range events {
+					ids = append(ids, event.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_ClaimEvents(t *testing.T) {
+	claimQuery := `UPDATE outbox_events
+		SET locked_by = $1, locked_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM (
+				SELECT id,
+					bool_and(ready AND id = ANY($3)) OVER (PARTITION BY COALESCE(partition_key, id) ORDER BY created_at, id) AS unblocked
+				FROM (
+					SELECT id, created_at, partition_key, (status = ANY($4)
+						AND (deliver_at IS NULL OR deliver_at <= NOW())
+		AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+		AND (expires_at IS NULL OR expires_at > NOW())
+		AND (locked_until IS NULL OR locked_until < NOW())) AS ready
+					FROM outbox_events
+					WHERE status IN ('pending', 'retrying', 'failed')
+						AND (id = ANY($3) OR partition_key IN (
+							SELECT partition_key FROM outbox_events WHERE id = ANY($3)
+						))
+				) unfinished
+			) ordered
+			WHERE unblocked
+		)
+		RETURNING id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority`
+	columns := []string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock($1)").WithArgs(claimLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE outbox_events
+		SET status = 'expired', next_attempt_at = NULL, updated_at = NOW()
+		WHERE status IN ('pending', 'retrying')
+			AND expires_at <= NOW()
+			AND (locked_until IS NULL OR locked_until < NOW())`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(claimQuery).
+		WithArgs("worker-1", float64(30), pq.Array([]string{"event-2", "event-1"}), pq.Array([]string{"pending", "retrying"})).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("event-2", "test.event", "test-service", `{"id": 2}`, nil, "pending", 0, nil, now, now, nil, nil, "order-1", nil, nil, 0).
+			AddRow("event-1", "test.event", "test-service", `{"id": 1}`, nil, "retrying", 1, nil, now.Add(-time.Minute), now, nil, nil, "order-1", nil, nil, 0))
+	mock.ExpectCommit()
+
+	store := NewOutboxStore(db)
+	events, err := store.ClaimEvents(context.Background(), "worker-1", []string{"event-2", "event-1"},
+		[]models.EventStatus{models.StatusPending, models.StatusRetrying}, 30*time.Second)
+
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "event-1", events[0].ID)
+	assert.Equal(t, "event-2", events[1].ID)
+
+	events, err = store.ClaimEvents(context.Background(), "worker-1", nil, []models.EventStatus{models.StatusFailed}, 30*time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ReleaseEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+	mock.ExpectExec(`UPDATE outbox_events
+		SET locked_by = NULL, locked_until = NULL
+		WHERE id = ANY($1) AND locked_by = $2`).
+		WithArgs(pq.Array([]string{"event-2", "event-3"}), "worker-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, store.ReleaseEvents(context.Background(), "worker-1", []string{"event-2", "event-3"}))
+	assert.NoError(t, store.ReleaseEvents(context.Background(), "worker-1", nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestOutboxStore_UpdateEventStatus(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -316,9 +954,10 @@ func TestOutboxStore_UpdateEventStatus(t *testing.T) {
 			retryCount: 0,
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(`UPDATE outbox_events
-		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5
-		WHERE id = $6`).
-					WithArgs("published", "", 0, sqlmock.AnyArg(), sqlmock.AnyArg(), "test-id").
+		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5,
+			next_attempt_at = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = $6 AND locked_by = $7`).
+					WithArgs("published", "", 0, sqlmock.AnyArg(), sqlmock.AnyArg(), "test-id", "worker-1").
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 		},
@@ -330,12 +969,29 @@ func TestOutboxStore_UpdateEventStatus(t *testing.T) {
 			retryCount: 3,
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(`UPDATE outbox_events
-		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5
-		WHERE id = $6`).
-					WithArgs("failed", "connection timeout", 3, sqlmock.AnyArg(), nil, "test-id").
+		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5,
+			next_attempt_at = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = $6 AND locked_by = $7`).
+					WithArgs("failed", "connection timeout", 3, sqlmock.AnyArg(), nil, "test-id", "worker-1").
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 		},
+		{
+			name:       "lease held by another worker",
+			eventID:    "test-id",
+			status:     models.StatusPublished,
+			lastError:  "",
+			retryCount: 0,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE outbox_events
+		SET status = $1, last_error = $2, retry_count = $3, updated_at = $4, published_at = $5,
+			next_attempt_at = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = $6 AND locked_by = $7`).
+					WithArgs("published", "", 0, sqlmock.AnyArg(), sqlmock.AnyArg(), "test-id", "worker-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: "lease lost",
+		},
 	}
 
 	for _, tt := range tests {
@@ -346,7 +1002,7 @@ func TestOutboxStore_UpdateEventStatus(t *testing.T) {
 			store := NewOutboxStore(db)
 			tt.mockSetup(mock)
 
-			err := store.UpdateEventStatus(tt.eventID, tt.status, tt.lastError, tt.retryCount)
+			err := store.UpdateEventStatus(context.Background(), "worker-1", tt.eventID, tt.status, tt.lastError, tt.retryCount)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -360,6 +1016,38 @@ func TestOutboxStore_UpdateEventStatus(t *testing.T) {
 	}
 }
 
+func TestOutboxStore_RenewLease(t *testing.T) {
+	query := `UPDATE outbox_events
+		SET locked_until = NOW() + make_interval(secs => $3)
+		WHERE id = $1 AND locked_by = $2`
+
+	t.Run("renews lease", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(query).
+			WithArgs("test-id", "worker-1", float64(60)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := NewOutboxStore(db).RenewLease(context.Background(), "worker-1", "test-id", time.Minute)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lease lost", func(t *testing.T) {
+		db, mock := setupMockDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(query).
+			WithArgs("test-id", "worker-1", float64(60)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := NewOutboxStore(db).RenewLease(context.Background(), "worker-1", "test-id", time.Minute)
+		assert.ErrorIs(t, err, ErrLeaseLost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestOutboxStore_DeleteEvent(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -396,7 +1084,7 @@ func TestOutboxStore_DeleteEvent(t *testing.T) {
 			store := NewOutboxStore(db)
 			tt.mockSetup(mock)
 
-			err := store.DeleteEvent(tt.eventID)
+			err := store.DeleteEvent(context.Background(), tt.eventID)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -411,6 +1099,21 @@ func TestOutboxStore_DeleteEvent(t *testing.T) {
 }
 
 func TestOutboxStore_GetStats(t *testing.T) {
+	statsQuery := `SELECT
+			COUNT(*) as total_events,
+			COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending_events,
+			COUNT(CASE WHEN status = 'published' THEN 1 END) as published_events,
+			COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed_events,
+			COUNT(CASE WHEN status = 'retrying' THEN 1 END) as retrying_events,
+			COUNT(CASE WHEN status = 'expired' THEN 1 END) as expired_events,
+			(SELECT COUNT(*) FROM outbox_dead_letters) as dead_letter_events,
+			COALESCE(SUM(retry_count), 0) as retry_count
+		FROM outbox_events`
+	priorityQuery := `SELECT priority, COUNT(*)
+		FROM outbox_events
+		WHERE status = 'pending'
+		GROUP BY priority`
+
 	tests := []struct {
 		name          string
 		mockSetup     func(sqlmock.Sqlmock)
@@ -420,23 +1123,35 @@ func TestOutboxStore_GetStats(t *testing.T) {
 		{
 			name: "successful stats retrieval",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT 
-			COUNT(*) as total_events,
-			COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending_events,
-			COUNT(CASE WHEN status = 'published' THEN 1 END) as published_events,
-			COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed_events,
-			COALESCE(SUM(retry_count), 0) as retry_count
-		FROM outbox_events`).
-					WillReturnRows(sqlmock.NewRows([]string{"total_events", "pending_events", "published_events", "failed_events", "retry_count"}).
-						AddRow(100, 25, 70, 5, 15))
+				mock.ExpectQuery(statsQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"total_events", "pending_events", "published_events", "failed_events", "retrying_events", "expired_events", "dead_letter_events", "retry_count"}).
+						AddRow(100, 25, 70, 5, 2, 4, 3, 15))
+				mock.ExpectQuery(priorityQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"priority", "count"}).
+						AddRow(0, 20).
+						AddRow(9, 5))
 			},
 			expectedStats: &models.StatsResponse{
-				TotalEvents:     100,
-				PendingEvents:   25,
-				PublishedEvents: 70,
-				FailedEvents:    5,
-				RetryCount:      15,
+				TotalEvents:       100,
+				PendingEvents:     25,
+				PublishedEvents:   70,
+				FailedEvents:      5,
+				RetryingEvents:    2,
+				ExpiredEvents:     4,
+				DeadLetterEvents:  3,
+				RetryCount:        15,
+				PendingByPriority: map[int]int{0: 20, 9: 5},
+			},
+		},
+		{
+			name: "priority counts error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(statsQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"total_events", "pending_events", "published_events", "failed_events", "retrying_events", "expired_events", "dead_letter_events", "retry_count"}).
+						AddRow(0, 0, 0, 0, 0, 0, 0, 0))
+				mock.ExpectQuery(priorityQuery).WillReturnError(sql.ErrConnDone)
 			},
+			expectedError: "failed to get pending events by priority",
 		},
 	}
 
@@ -448,7 +1163,7 @@ func TestOutboxStore_GetStats(t *testing.T) {
 			store := NewOutboxStore(db)
 			tt.mockSetup(mock)
 
-			stats, err := store.GetStats()
+			stats, err := store.GetStats(context.Background())
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// expiredEventsCondition matches events in status $1 last updated before $2
+const expiredEventsCondition = "status = $1 AND updated_at < $2"
+
+// expiredEventIDsQuery selects a batch of up to $3 expired events, skipping
+// rows a concurrent pruner on another replica has already locked
+const expiredEventIDsQuery = `
+			SELECT id FROM outbox_events
+			WHERE ` + expiredEventsCondition + `
+			ORDER BY updated_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED`
+
+// PruneEvents deletes up to limit events in status that were last updated
+// before cutoff, with their delivery attempts, and returns how many were
+// deleted
+func (s *OutboxStore) PruneEvents(ctx context.Context, status models.EventStatus, before time.Time, limit int) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH expired AS (` + expiredEventIDsQuery + `
+		), attempts AS (
+			DELETE FROM outbox_delivery_attempts
+			WHERE event_id IN (SELECT id FROM expired)
+		)
+		DELETE FROM outbox_events
+		WHERE id IN (SELECT id FROM expired)
+	`
+
+	result, err := s.db.conn.ExecContext(ctx, query, status, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune %s events: %w", status, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ArchiveEvents moves up to limit events in status that were last updated
+// before cutoff into outbox_events_archive in a single statement and returns
+// how many were moved. Their delivery attempts are deleted.
+func (s *OutboxStore) ArchiveEvents(ctx context.Context, status models.EventStatus, before time.Time, limit int) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH moved AS (
+			DELETE FROM outbox_events
+			WHERE id IN (` + expiredEventIDsQuery + `
+			)
+			RETURNING id, type, source, data, metadata, status, retry_count, last_error, partition_key, created_at, updated_at, published_at, deliver_at, expires_at, priority
+		), attempts AS (
+			DELETE FROM outbox_delivery_attempts
+			WHERE event_id IN (SELECT id FROM moved)
+		)
+		INSERT INTO outbox_events_archive (id, type, source, data, metadata, status, retry_count, last_error, partition_key, created_at, updated_at, published_at, deliver_at, expires_at, priority, archived_at)
+		SELECT id, type, source, data, metadata, status, retry_count, last_error, partition_key, created_at, updated_at, published_at, deliver_at, expires_at, priority, NOW()
+		FROM moved
+	`
+
+	result, err := s.db.conn.ExecContext(ctx, query, status, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive %s events: %w", status, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ExportEvents passes up to limit events in status that were last updated
+// before cutoff to export, then deletes them and their delivery attempts. The
+// events stay locked until
+// then, and are kept if export fails, so each is exported at least once.
+func (s *OutboxStore) ExportEvents(ctx context.Context, status models.EventStatus, before time.Time, limit int, export func([]models.Event) error) (int, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM outbox_events
+		WHERE ` + expiredEventsCondition + `
+		ORDER BY updated_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, status, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select %s events to export: %w", status, err)
+	}
+	events, err := scanEvents(rows)
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := export(events); err != nil {
+		return 0, fmt.Errorf("failed to export %s events: %w", status, err)
+	}
+
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM outbox_events WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to delete exported events: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM outbox_delivery_attempts WHERE event_id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to delete delivery attempts of exported events: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(events), nil
+}
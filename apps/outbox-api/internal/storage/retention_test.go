@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxStore_PruneEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	mock.ExpectExec(`WITH expired AS (
+			SELECT id FROM outbox_events
+			WHERE status = $1 AND updated_at < $2
+			ORDER BY updated_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		), attempts AS (
+			DELETE FROM outbox_delivery_attempts
+			WHERE event_id IN (SELECT id FROM expired)
+		)
+		DELETE FROM outbox_events
+		WHERE id IN (SELECT id FROM expired)`).
+		WithArgs("published", cutoff, 500).
+		WillReturnResult(sqlmock.NewResult(0, 500))
+
+	store := NewOutboxStore(db)
+	pruned, err := store.PruneEvents(context.Background(), models.StatusPublished, cutoff, 500)
+
+	require.NoError(t, err)
+	assert.Equal(t, 500, pruned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ArchiveEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	mock.ExpectExec(`WITH moved AS (
+			DELETE FROM outbox_events
+			WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE status = $1 AND updated_at < $2
+			ORDER BY updated_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, type, source, data, metadata, status, retry_count, last_error, partition_key, created_at, updated_at, published_at, deliver_at, expires_at, priority
+		), attempts AS (
+			DELETE FROM outbox_delivery_attempts
+			WHERE event_id IN (SELECT id FROM moved)
+		)
+		INSERT INTO outbox_events_archive (id, type, source, data, metadata, status, retry_count, last_error, partition_key, created_at, updated_at, published_at, deliver_at, expires_at, priority, archived_at)
+		SELECT id, type, source, data, metadata, status, retry_count, last_error, partition_key, created_at, updated_at, published_at, deliver_at, expires_at, priority, NOW()
+		FROM moved`).
+		WithArgs("failed", cutoff, 100).
+		WillReturnError(sql.ErrConnDone)
+
+	store := NewOutboxStore(db)
+	_, err := store.ArchiveEvents(context.Background(), models.StatusFailed, cutoff, 100)
+
+	assert.ErrorContains(t, err, "failed to archive failed events")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ExportEvents(t *testing.T) {
+	cutoff := time.Now().Add(-24 * time.Hour)
+	selectQuery := `SELECT id, type, source, data, metadata, status, retry_count, last_error, created_at, updated_at, published_at, next_attempt_at, partition_key, deliver_at, expires_at, priority
+		FROM outbox_events
+		WHERE status = $1 AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED`
+	eventRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}).
+			AddRow("event-1", "order.created", "order-service", `{"id": 1}`, nil, "published", 0, nil, cutoff, cutoff, cutoff, nil, nil, nil, nil, 0).
+			AddRow("event-2", "order.created", "order-service", `{"id": 2}`, nil, "published", 0, nil, cutoff, cutoff, cutoff, nil, nil, nil, nil, 0)
+	}
+
+	tests := []struct {
+		name          string
+		exportErr     error
+		mockSetup     func(sqlmock.Sqlmock)
+		expected      int
+		expectedError string
+	}{
+		{
+			name: "deletes exported events",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs("published", cutoff, 2).WillReturnRows(eventRows())
+				mock.ExpectExec("DELETE FROM outbox_events WHERE id = ANY($1)").
+					WithArgs(pq.Array([]string{"event-1", "event-2"})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM outbox_delivery_attempts WHERE event_id = ANY($1)").
+					WithArgs(pq.Array([]string{"event-1", "event-2"})).
+					WillReturnResult(sqlmock.NewResult(0, 5))
+				mock.ExpectCommit()
+			},
+			expected: 2,
+		},
+		{
+			name:      "keeps events when export fails",
+			exportErr: errors.New("disk full"),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs("published", cutoff, 2).WillReturnRows(eventRows())
+				mock.ExpectRollback()
+			},
+			expectedError: "failed to export published events: disk full",
+		},
+		{
+			name: "nothing expired",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs("published", cutoff, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "type", "source", "data", "metadata", "status", "retry_count", "last_error", "created_at", "updated_at", "published_at", "next_attempt_at", "partition_key", "deliver_at", "expires_at", "priority"}))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			var exported []string
+			n, err := store.ExportEvents(context.Background(), models.StatusPublished, cutoff, 2, func(events []models.Event) error {
+				for _, event := range events {
+					exported = append(exported, event.ID)
+				}
+				return tt.exportErr
+			})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, n)
+				assert.Len(t, exported, tt.expected)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
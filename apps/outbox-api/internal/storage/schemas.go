@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// schemaColumns lists the columns read back for every schema query
+const schemaColumns = "event_type, version, schema, created_at"
+
+// scanSchema scans a row selected with schemaColumns into a schema
+func scanSchema(row rowScanner) (*models.EventSchema, error) {
+	var schema models.EventSchema
+	var document []byte
+	if err := row.Scan(&schema.EventType, &schema.Version, &document, &schema.CreatedAt); err != nil {
+		return nil, err
+	}
+	schema.Schema = json.RawMessage(document)
+	return &schema, nil
+}
+
+// CreateSchema registers schema as the next version for an event type. The
+// type's rows are locked so concurrent registrations get distinct versions.
+func (s *OutboxStore) CreateSchema(ctx context.Context, eventType string, schema json.RawMessage) (*models.EventSchema, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", eventType); err != nil {
+		return nil, fmt.Errorf("failed to lock schema versions: %w", err)
+	}
+
+	query := `
+		INSERT INTO outbox_event_schemas (event_type, version, schema, created_at)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, NOW()
+		FROM outbox_event_schemas
+		WHERE event_type = $1
+		RETURNING ` + schemaColumns
+
+	created, err := scanSchema(tx.QueryRowContext(ctx, query, eventType, schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetSchema retrieves one version of an event type's schema, or the latest
+// version when version is 0
+func (s *OutboxStore) GetSchema(ctx context.Context, eventType string, version int) (*models.EventSchema, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + schemaColumns + `
+		FROM outbox_event_schemas
+		WHERE event_type = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	schema, err := scanSchema(s.db.conn.QueryRowContext(ctx, query, eventType, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("schema not found")
+		}
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+// ListSchemas retrieves the latest schema version of every event type, or
+// every version of one event type when eventType is set
+func (s *OutboxStore) ListSchemas(ctx context.Context, eventType string) ([]models.EventSchema, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT ON (event_type) ` + schemaColumns + `
+		FROM outbox_event_schemas
+		ORDER BY event_type, version DESC
+	`
+	args := []interface{}{}
+	if eventType != "" {
+		query = `
+			SELECT ` + schemaColumns + `
+			FROM outbox_event_schemas
+			WHERE event_type = $1
+			ORDER BY version DESC
+		`
+		args = append(args, eventType)
+	}
+
+	rows, err := s.db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []models.EventSchema
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schema: %w", err)
+		}
+		schemas = append(schemas, *schema)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schemas: %w", err)
+	}
+
+	return schemas, nil
+}
+
+// DeleteSchema deletes one version of an event type's schema, or every
+// version when version is 0
+func (s *OutboxStore) DeleteSchema(ctx context.Context, eventType string, version int) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.conn.ExecContext(ctx, "DELETE FROM outbox_event_schemas WHERE event_type = $1 AND ($2 = 0 OR version = $2)", eventType, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete schema: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("schema not found")
+	}
+
+	return nil
+}
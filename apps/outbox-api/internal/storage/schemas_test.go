@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"event_type", "version", "schema", "created_at"})
+}
+
+func TestOutboxStore_CreateSchema(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	schema := json.RawMessage(`{"type": "object"}`)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock(hashtext($1))").
+		WithArgs("order.created").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`
+		INSERT INTO outbox_event_schemas (event_type, version, schema, created_at)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, NOW()
+		FROM outbox_event_schemas
+		WHERE event_type = $1
+		RETURNING `+schemaColumns).
+		WithArgs("order.created", schema).
+		WillReturnRows(schemaRows().AddRow("order.created", 3, []byte(schema), now))
+	mock.ExpectCommit()
+
+	created, err := store.CreateSchema(context.Background(), "order.created", schema)
+	require.NoError(t, err)
+	assert.Equal(t, "order.created", created.EventType)
+	assert.Equal(t, 3, created.Version)
+	assert.JSONEq(t, string(schema), string(created.Schema))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_GetSchema(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	query := `
+		SELECT ` + schemaColumns + `
+		FROM outbox_event_schemas
+		WHERE event_type = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1`
+
+	mock.ExpectQuery(query).
+		WithArgs("order.created", 0).
+		WillReturnRows(schemaRows().AddRow("order.created", 2, []byte(`{"type": "object"}`), time.Now()))
+
+	schema, err := store.GetSchema(context.Background(), "order.created", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, schema.Version)
+
+	mock.ExpectQuery(query).
+		WithArgs("order.created", 7).
+		WillReturnRows(schemaRows())
+
+	_, err = store.GetSchema(context.Background(), "order.created", 7)
+	assert.EqualError(t, err, "schema not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ListSchemas(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`
+		SELECT DISTINCT ON (event_type) ` + schemaColumns + `
+		FROM outbox_event_schemas
+		ORDER BY event_type, version DESC`).
+		WillReturnRows(schemaRows().
+			AddRow("order.created", 2, []byte(`{"type": "object"}`), now).
+			AddRow("user.signed_up", 1, []byte(`true`), now))
+
+	latest, err := store.ListSchemas(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.Equal(t, "user.signed_up", latest[1].EventType)
+
+	mock.ExpectQuery(`
+		SELECT ` + schemaColumns + `
+		FROM outbox_event_schemas
+		WHERE event_type = $1
+		ORDER BY version DESC`).
+		WithArgs("order.created").
+		WillReturnRows(schemaRows().
+			AddRow("order.created", 2, []byte(`{"type": "object"}`), now).
+			AddRow("order.created", 1, []byte(`true`), now))
+
+	versions, err := store.ListSchemas(context.Background(), "order.created")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 1, versions[1].Version)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_DeleteSchema(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	query := "DELETE FROM outbox_event_schemas WHERE event_type = $1 AND ($2 = 0 OR version = $2)"
+
+	mock.ExpectExec(query).
+		WithArgs("order.created", 0).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, store.DeleteSchema(context.Background(), "order.created", 0))
+
+	mock.ExpectExec(query).
+		WithArgs("order.created", 9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.EqualError(t, store.DeleteSchema(context.Background(), "order.created", 9), "schema not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
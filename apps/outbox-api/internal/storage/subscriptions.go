@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// subscriptionColumns lists the columns read back for every subscription query
+const subscriptionColumns = "id, name, url, event_types, sources, active, secret, previous_secret, previous_secret_expires_at, created_at, updated_at"
+
+// scanSubscription scans a row selected with subscriptionColumns into a subscription
+func scanSubscription(row rowScanner) (*models.Subscription, error) {
+	var subscription models.Subscription
+	var previousSecret sql.NullString
+	var previousSecretExpiresAt sql.NullTime
+	err := row.Scan(&subscription.ID, &subscription.Name, &subscription.URL, pq.Array(&subscription.EventTypes), pq.Array(&subscription.Sources), &subscription.Active, &subscription.Secret, &previousSecret, &previousSecretExpiresAt, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if previousSecret.Valid {
+		subscription.PreviousSecret = previousSecret.String
+	}
+
+	if previousSecretExpiresAt.Valid {
+		subscription.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
+
+	return &subscription, nil
+}
+
+// nonNil keeps empty filter lists stored as '{}' rather than NULL
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// CreateSubscription creates a new webhook subscription
+func (s *OutboxStore) CreateSubscription(ctx context.Context, req *models.SubscriptionRequest) (*models.Subscription, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	id := uuid.New().String()
+	now := time.Now()
+
+	query := `
+		INSERT INTO outbox_subscriptions (id, name, url, event_types, sources, active, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + subscriptionColumns
+
+	subscription, err := scanSubscription(s.db.conn.QueryRowContext(ctx, query, id, req.Name, req.URL, pq.Array(nonNil(req.EventTypes)), pq.Array(nonNil(req.Sources)), req.IsActive(), req.Secret, now, now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription retrieves a subscription by ID
+func (s *OutboxStore) GetSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM outbox_subscriptions
+		WHERE id = $1
+	`
+
+	subscription, err := scanSubscription(s.db.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription not found")
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions retrieves every subscription, oldest first
+func (s *OutboxStore) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM outbox_subscriptions
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []models.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// UpdateSubscription replaces a subscription's URL, filters and active flag
+func (s *OutboxStore) UpdateSubscription(ctx context.Context, id string, req *models.SubscriptionRequest) (*models.Subscription, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE outbox_subscriptions
+		SET name = $2, url = $3, event_types = $4, sources = $5, active = $6, updated_at = $7
+		WHERE id = $1
+		RETURNING ` + subscriptionColumns
+
+	subscription, err := scanSubscription(s.db.conn.QueryRowContext(ctx, query, id, req.Name, req.URL, pq.Array(nonNil(req.EventTypes)), pq.Array(nonNil(req.Sources)), req.IsActive(), time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription not found")
+		}
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// RotateSubscriptionSecret replaces a subscription's signing secret. The old
+// secret is kept as the previous secret until previousExpiresAt.
+func (s *OutboxStore) RotateSubscriptionSecret(ctx context.Context, id string, secret string, previousExpiresAt time.Time) (*models.Subscription, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE outbox_subscriptions
+		SET previous_secret = secret, previous_secret_expires_at = $3, secret = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + subscriptionColumns
+
+	subscription, err := scanSubscription(s.db.conn.QueryRowContext(ctx, query, id, secret, previousExpiresAt))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription not found")
+		}
+		return nil, fmt.Errorf("failed to rotate subscription secret: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription deletes a subscription along with its delivery history
+func (s *OutboxStore) DeleteSubscription(ctx context.Context, id string) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.conn.ExecContext(ctx, "DELETE FROM outbox_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
+}
+
+// GetDeliveries retrieves the per-subscription delivery status of an event
+func (s *OutboxStore) GetDeliveries(ctx context.Context, eventID string) ([]models.Delivery, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT d.event_id, d.subscription_id, sub.url, d.status, d.attempts, d.last_error, d.last_attempt_at, d.delivered_at
+		FROM outbox_deliveries d
+		JOIN outbox_subscriptions sub ON sub.id = d.subscription_id
+		WHERE d.event_id = $1
+		ORDER BY sub.created_at
+	`
+
+	rows, err := s.db.conn.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.Delivery
+	for rows.Next() {
+		var delivery models.Delivery
+		var lastErrorStr sql.NullString
+		var deliveredAt sql.NullTime
+		err := rows.Scan(&delivery.EventID, &delivery.SubscriptionID, &delivery.URL, &delivery.Status, &delivery.Attempts, &lastErrorStr, &delivery.LastAttemptAt, &deliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+
+		if lastErrorStr.Valid {
+			delivery.LastError = lastErrorStr.String
+		}
+
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordDelivery records the outcome of one delivery attempt of an event to a
+// subscription. An empty lastError marks the delivery as delivered.
+func (s *OutboxStore) RecordDelivery(ctx context.Context, eventID, subscriptionID string, lastError string) error {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	status := models.DeliveryDelivered
+	var errorValue interface{}
+	var deliveredAt interface{}
+	now := time.Now()
+	if lastError != "" {
+		status = models.DeliveryFailed
+		errorValue = lastError
+	} else {
+		deliveredAt = now
+	}
+
+	query := `
+		INSERT INTO outbox_deliveries (event_id, subscription_id, status, attempts, last_error, last_attempt_at, delivered_at)
+		VALUES ($1, $2, $3, 1, $4, $5, $6)
+		ON CONFLICT (event_id, subscription_id) DO UPDATE
+		SET status = EXCLUDED.status,
+			attempts = outbox_deliveries.attempts + 1,
+			last_error = EXCLUDED.last_error,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			delivered_at = EXCLUDED.delivered_at
+	`
+
+	_, err := s.db.conn.ExecContext(ctx, query, eventID, subscriptionID, status, errorValue, now, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+
+	return nil
+}
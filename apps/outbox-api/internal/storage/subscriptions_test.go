@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var subscriptionRowColumns = []string{"id", "name", "url", "event_types", "sources", "active", "secret", "previous_secret", "previous_secret_expires_at", "created_at", "updated_at"}
+
+func TestOutboxStore_CreateSubscription(t *testing.T) {
+	query := `INSERT INTO outbox_subscriptions (id, name, url, event_types, sources, active, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, name, url, event_types, sources, active, secret, previous_secret, previous_secret_expires_at, created_at, updated_at`
+
+	tests := []struct {
+		name          string
+		request       *models.SubscriptionRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "creates subscription with filters",
+			request: &models.SubscriptionRequest{
+				Name:       "billing",
+				URL:        "https://billing.example.com/hooks",
+				EventTypes: []string{"order.*"},
+				Sources:    []string{"order-service"},
+				Secret:     "whsec_test",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectQuery(query).
+					WithArgs(sqlmock.AnyArg(), "billing", "https://billing.example.com/hooks", pq.Array([]string{"order.*"}), pq.Array([]string{"order-service"}), true, "whsec_test", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+						AddRow("sub-1", "billing", "https://billing.example.com/hooks", "{order.*}", "{order-service}", true, "whsec_test", nil, nil, now, now))
+			},
+		},
+		{
+			name: "stores missing filters as empty arrays",
+			request: &models.SubscriptionRequest{
+				Name:   "audit",
+				URL:    "https://audit.example.com/hooks",
+				Secret: "whsec_test",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectQuery(query).
+					WithArgs(sqlmock.AnyArg(), "audit", "https://audit.example.com/hooks", pq.Array([]string{}), pq.Array([]string{}), true, "whsec_test", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+						AddRow("sub-1", "audit", "https://audit.example.com/hooks", "{}", "{}", true, "whsec_test", nil, nil, now, now))
+			},
+		},
+		{
+			name: "database error",
+			request: &models.SubscriptionRequest{
+				Name:   "audit",
+				URL:    "https://audit.example.com/hooks",
+				Secret: "whsec_test",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to create subscription",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			subscription, err := store.CreateSubscription(context.Background(), tt.request)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, subscription)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "sub-1", subscription.ID)
+				assert.Equal(t, tt.request.URL, subscription.URL)
+				assert.Equal(t, nonNil(tt.request.EventTypes), []string(subscription.EventTypes))
+				assert.Equal(t, nonNil(tt.request.Sources), []string(subscription.Sources))
+				assert.True(t, subscription.Active)
+				assert.Equal(t, "whsec_test", subscription.Secret)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxStore_GetSubscription(t *testing.T) {
+	query := `SELECT id, name, url, event_types, sources, active, secret, previous_secret, previous_secret_expires_at, created_at, updated_at
+		FROM outbox_subscriptions
+		WHERE id = $1`
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	now := time.Now()
+	mock.ExpectQuery(query).WithArgs("sub-1").
+		WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+			AddRow("sub-1", "billing", "https://billing.example.com/hooks", "{order.*,invoice.*}", "{}", false, "whsec_test", nil, nil, now, now))
+
+	subscription, err := store.GetSubscription(context.Background(), "sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order.*", "invoice.*"}, subscription.EventTypes)
+	assert.Empty(t, subscription.Sources)
+	assert.False(t, subscription.Active)
+
+	mock.ExpectQuery(query).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+	_, err = store.GetSubscription(context.Background(), "missing")
+	assert.EqualError(t, err, "subscription not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ListSubscriptions(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, name, url, event_types, sources, active, secret, previous_secret, previous_secret_expires_at, created_at, updated_at
+		FROM outbox_subscriptions
+		ORDER BY created_at`).
+		WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+			AddRow("sub-1", "billing", "https://billing.example.com/hooks", "{order.*}", "{}", true, "whsec_test", nil, nil, now, now).
+			AddRow("sub-2", "audit", "https://audit.example.com/hooks", "{}", "{}", true, "whsec_test", nil, nil, now, now))
+
+	subscriptions, err := store.ListSubscriptions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.Equal(t, "sub-1", subscriptions[0].ID)
+	assert.Equal(t, "sub-2", subscriptions[1].ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_UpdateSubscription(t *testing.T) {
+	query := `UPDATE outbox_subscriptions
+		SET name = $2, url = $3, event_types = $4, sources = $5, active = $6, updated_at = $7
+		WHERE id = $1
+		RETURNING id, name, url, event_types, sources, active, secret, previous_secret, previous_secret_expires_at, created_at, updated_at`
+
+	inactive := false
+	req := &models.SubscriptionRequest{Name: "billing", URL: "https://billing.example.com/v2", Active: &inactive}
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	now := time.Now()
+	mock.ExpectQuery(query).
+		WithArgs("sub-1", "billing", "https://billing.example.com/v2", pq.Array([]string{}), pq.Array([]string{}), false, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+			AddRow("sub-1", "billing", "https://billing.example.com/v2", "{}", "{}", false, "whsec_test", nil, nil, now, now))
+
+	subscription, err := store.UpdateSubscription(context.Background(), "sub-1", req)
+	require.NoError(t, err)
+	assert.Equal(t, "https://billing.example.com/v2", subscription.URL)
+	assert.False(t, subscription.Active)
+
+	mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+	_, err = store.UpdateSubscription(context.Background(), "missing", req)
+	assert.EqualError(t, err, "subscription not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_RotateSubscriptionSecret(t *testing.T) {
+	query := `UPDATE outbox_subscriptions
+		SET previous_secret = secret, previous_secret_expires_at = $3, secret = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, name, url, event_types, sources, active, secret, previous_secret, previous_secret_expires_at, created_at, updated_at`
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	now := time.Now()
+	expiresAt := now.Add(24 * time.Hour)
+	mock.ExpectQuery(query).
+		WithArgs("sub-1", "whsec_new", expiresAt).
+		WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+			AddRow("sub-1", "billing", "https://billing.example.com/hooks", "{}", "{}", true, "whsec_new", "whsec_old", expiresAt, now, now))
+
+	subscription, err := store.RotateSubscriptionSecret(context.Background(), "sub-1", "whsec_new", expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_new", subscription.Secret)
+	assert.Equal(t, "whsec_old", subscription.PreviousSecret)
+	require.NotNil(t, subscription.PreviousSecretExpiresAt)
+	assert.Equal(t, expiresAt, *subscription.PreviousSecretExpiresAt)
+
+	mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+	_, err = store.RotateSubscriptionSecret(context.Background(), "missing", "whsec_new", expiresAt)
+	assert.EqualError(t, err, "subscription not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_DeleteSubscription(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	mock.ExpectExec("DELETE FROM outbox_subscriptions WHERE id = $1").
+		WithArgs("sub-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.DeleteSubscription(context.Background(), "sub-1"))
+
+	mock.ExpectExec("DELETE FROM outbox_subscriptions WHERE id = $1").
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.EqualError(t, store.DeleteSubscription(context.Background(), "missing"), "subscription not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_GetDeliveries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	store := NewOutboxStore(db)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT d.event_id, d.subscription_id, sub.url, d.status, d.attempts, d.last_error, d.last_attempt_at, d.delivered_at
+		FROM outbox_deliveries d
+		JOIN outbox_subscriptions sub ON sub.id = d.subscription_id
+		WHERE d.event_id = $1
+		ORDER BY sub.created_at`).
+		WithArgs("event-1").
+		WillReturnRows(sqlmock.NewRows([]string{"event_id", "subscription_id", "url", "status", "attempts", "last_error", "last_attempt_at", "delivered_at"}).
+			AddRow("event-1", "sub-1", "https://billing.example.com/hooks", "delivered", 1, nil, now, now).
+			AddRow("event-1", "sub-2", "https://audit.example.com/hooks", "failed", 3, "webhook returned status 503", now, nil))
+
+	deliveries, err := store.GetDeliveries(context.Background(), "event-1")
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, models.DeliveryDelivered, deliveries[0].Status)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+	assert.Empty(t, deliveries[0].LastError)
+	assert.Equal(t, models.DeliveryFailed, deliveries[1].Status)
+	assert.Equal(t, 3, deliveries[1].Attempts)
+	assert.Equal(t, "webhook returned status 503", deliveries[1].LastError)
+	assert.Nil(t, deliveries[1].DeliveredAt)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_RecordDelivery(t *testing.T) {
+	query := `INSERT INTO outbox_deliveries (event_id, subscription_id, status, attempts, last_error, last_attempt_at, delivered_at)
+		VALUES ($1, $2, $3, 1, $4, $5, $6)
+		ON CONFLICT (event_id, subscription_id) DO UPDATE
+		SET status = EXCLUDED.status,
+			attempts = outbox_deliveries.attempts + 1,
+			last_error = EXCLUDED.last_error,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			delivered_at = EXCLUDED.delivered_at`
+
+	tests := []struct {
+		name      string
+		lastError string
+		mockSetup func(sqlmock.Sqlmock)
+	}{
+		{
+			name: "records a successful delivery",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("event-1", "sub-1", models.DeliveryDelivered, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:      "records a failed delivery",
+			lastError: "webhook returned status 503",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("event-1", "sub-1", models.DeliveryFailed, "webhook returned status 503", sqlmock.AnyArg(), nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			err := store.RecordDelivery(context.Background(), "event-1", "sub-1", tt.lastError)
+			assert.NoError(t, err)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
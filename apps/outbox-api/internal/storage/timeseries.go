@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// GROUPING(bucket, type, source) of each grouping set in timeseriesQuery: a
+// bit is set for every column the set does not group by
+const (
+	groupedByBucket = 3
+	groupedByType   = 5
+	groupedBySource = 6
+	groupedTotal    = 7
+)
+
+// timeseriesQuery aggregates the events created, published and failed
+// between $1 and $2 into buckets of $3 seconds, by type, by source and in
+// total, in a single pass. Failures are events dead-lettered or marked failed.
+const timeseriesQuery = `
+		WITH activity AS (
+			SELECT 'created' AS kind, created_at AS at, type, source, NULL::double precision AS latency_ms
+			FROM outbox_events
+			WHERE created_at >= $1 AND created_at < $2
+			UNION ALL
+			SELECT 'published', published_at, type, source, EXTRACT(EPOCH FROM published_at - created_at) * 1000
+			FROM outbox_events
+			WHERE published_at >= $1 AND published_at < $2
+			UNION ALL
+			SELECT 'failed', updated_at, type, source, NULL
+			FROM outbox_events
+			WHERE status = 'failed' AND updated_at >= $1 AND updated_at < $2
+			UNION ALL
+			SELECT 'failed', dead_lettered_at, type, source, NULL
+			FROM outbox_dead_letters
+			WHERE dead_lettered_at >= $1 AND dead_lettered_at < $2
+		)
+		SELECT
+			GROUPING(bucket, type, source),
+			bucket,
+			type,
+			source,
+			COUNT(*) FILTER (WHERE kind = 'created'),
+			COUNT(*) FILTER (WHERE kind = 'published'),
+			COUNT(*) FILTER (WHERE kind = 'failed'),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms)
+		FROM (
+			SELECT *, FLOOR(EXTRACT(EPOCH FROM at - $1) / $3)::int AS bucket
+			FROM activity
+		) bucketed
+		GROUP BY GROUPING SETS ((bucket), (type), (source), ())
+	`
+
+// GetTimeseries aggregates event throughput over count buckets of bucket
+// length starting at from
+func (s *OutboxStore) GetTimeseries(ctx context.Context, from time.Time, bucket time.Duration, count int) (*models.TimeseriesResponse, error) {
+	ctx, cancel := s.db.withTimeout(ctx)
+	defer cancel()
+
+	to := from.Add(time.Duration(count) * bucket)
+	seconds := int(bucket / time.Second)
+
+	response := &models.TimeseriesResponse{
+		From:          from,
+		To:            to,
+		BucketSeconds: seconds,
+		Buckets:       make([]models.StatsBucket, count),
+		ByType:        []models.StatsBreakdown{},
+		BySource:      []models.StatsBreakdown{},
+	}
+	for i := range response.Buckets {
+		response.Buckets[i].Start = from.Add(time.Duration(i) * bucket)
+	}
+
+	rows, err := s.db.conn.QueryContext(ctx, timeseriesQuery, from, to, seconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeseries stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var grouping int
+		var index sql.NullInt64
+		var eventType, source sql.NullString
+		var throughput models.ThroughputStats
+		var p50, p95 sql.NullFloat64
+		err := rows.Scan(&grouping, &index, &eventType, &source,
+			&throughput.Created, &throughput.Published, &throughput.Failed, &p50, &p95)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timeseries stats: %w", err)
+		}
+		throughput.LatencyP50Ms = nullFloat(p50)
+		throughput.LatencyP95Ms = nullFloat(p95)
+
+		switch grouping {
+		case groupedByBucket:
+			if index.Valid && index.Int64 >= 0 && int(index.Int64) < count {
+				response.Buckets[index.Int64].ThroughputStats = throughput
+			}
+		case groupedByType:
+			response.ByType = append(response.ByType, models.StatsBreakdown{Key: eventType.String, ThroughputStats: throughput})
+		case groupedBySource:
+			response.BySource = append(response.BySource, models.StatsBreakdown{Key: source.String, ThroughputStats: throughput})
+		case groupedTotal:
+			response.Totals = throughput
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read timeseries stats: %w", err)
+	}
+
+	sortBreakdowns(response.ByType)
+	sortBreakdowns(response.BySource)
+
+	return response, nil
+}
+
+// sortBreakdowns orders breakdowns busiest first, then by key
+func sortBreakdowns(breakdowns []models.StatsBreakdown) {
+	sort.Slice(breakdowns, func(i, j int) bool {
+		if ti, tj := breakdowns[i].Total(), breakdowns[j].Total(); ti != tj {
+			return ti > tj
+		}
+		return breakdowns[i].Key < breakdowns[j].Key
+	})
+}
+
+// nullFloat returns a pointer to value, or nil when it is NULL
+func nullFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
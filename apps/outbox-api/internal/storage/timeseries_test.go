@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxStore_GetTimeseries(t *testing.T) {
+	from := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(3 * time.Minute)
+	columns := []string{"grouping", "bucket", "type", "source", "created", "published", "failed", "p50", "p95"}
+	p50, p95 := 120.0, 950.5
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expected      *models.TimeseriesResponse
+		expectedError string
+	}{
+		{
+			name: "fills buckets and breakdowns",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(timeseriesQuery).
+					WithArgs(from, to, 60).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(groupedByBucket, 0, nil, nil, 4, 3, 0, p50, p95).
+						AddRow(groupedByBucket, 2, nil, nil, 1, 0, 1, nil, nil).
+						AddRow(groupedByType, nil, "user.created", nil, 1, 0, 1, nil, nil).
+						AddRow(groupedByType, nil, "order.created", nil, 4, 3, 0, p50, p95).
+						AddRow(groupedBySource, nil, nil, "shop", 5, 3, 1, p50, p95).
+						AddRow(groupedTotal, nil, nil, nil, 5, 3, 1, p50, p95))
+			},
+			expected: &models.TimeseriesResponse{
+				From:          from,
+				To:            to,
+				BucketSeconds: 60,
+				Totals:        models.ThroughputStats{Created: 5, Published: 3, Failed: 1, LatencyP50Ms: &p50, LatencyP95Ms: &p95},
+				Buckets: []models.StatsBucket{
+					{Start: from, ThroughputStats: models.ThroughputStats{Created: 4, Published: 3, LatencyP50Ms: &p50, LatencyP95Ms: &p95}},
+					{Start: from.Add(time.Minute)},
+					{Start: from.Add(2 * time.Minute), ThroughputStats: models.ThroughputStats{Created: 1, Failed: 1}},
+				},
+				ByType: []models.StatsBreakdown{
+					{Key: "order.created", ThroughputStats: models.ThroughputStats{Created: 4, Published: 3, LatencyP50Ms: &p50, LatencyP95Ms: &p95}},
+					{Key: "user.created", ThroughputStats: models.ThroughputStats{Created: 1, Failed: 1}},
+				},
+				BySource: []models.StatsBreakdown{
+					{Key: "shop", ThroughputStats: models.ThroughputStats{Created: 5, Published: 3, Failed: 1, LatencyP50Ms: &p50, LatencyP95Ms: &p95}},
+				},
+			},
+		},
+		{
+			name: "no activity",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(timeseriesQuery).
+					WithArgs(from, to, 60).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(groupedTotal, nil, nil, nil, 0, 0, 0, nil, nil))
+			},
+			expected: &models.TimeseriesResponse{
+				From:          from,
+				To:            to,
+				BucketSeconds: 60,
+				Buckets: []models.StatsBucket{
+					{Start: from},
+					{Start: from.Add(time.Minute)},
+					{Start: from.Add(2 * time.Minute)},
+				},
+				ByType:   []models.StatsBreakdown{},
+				BySource: []models.StatsBreakdown{},
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(timeseriesQuery).
+					WithArgs(from, to, 60).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to get timeseries stats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			defer db.Close()
+
+			store := NewOutboxStore(db)
+			tt.mockSetup(mock)
+
+			response, err := store.GetTimeseries(context.Background(), from, time.Minute, 3)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, response)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package stream fans event changes out to /api/v1/events/stream clients.
+// Changes arrive through PostgreSQL LISTEN/NOTIFY, so a client connected to
+// any replica sees changes made through every replica.
+package stream
+
+import (
+	"sync"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+)
+
+// DefaultBuffer is how many changes a subscriber may fall behind by before
+// it is dropped
+const DefaultBuffer = 256
+
+// Subscription receives the changes matching its filter on C. C is closed
+// when the subscriber falls too far behind or the hub may have missed
+// changes; the client should then reconnect and resume from the last ID.
+type Subscription struct {
+	C      <-chan models.EventChange
+	c      chan models.EventChange
+	filter models.ChangeFilter
+}
+
+// Hub broadcasts changes to subscribers
+type Hub struct {
+	mutex       sync.Mutex
+	subscribers map[*Subscription]struct{}
+	buffer      int
+}
+
+// NewHub creates a hub whose subscribers buffer up to buffer changes
+func NewHub(buffer int) *Hub {
+	if buffer < 1 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		subscribers: make(map[*Subscription]struct{}),
+		buffer:      buffer,
+	}
+}
+
+// Subscribe registers a subscriber for changes matching filter
+func (h *Hub) Subscribe(filter models.ChangeFilter) *Subscription {
+	c := make(chan models.EventChange, h.buffer)
+	sub := &Subscription{C: c, c: c, filter: filter}
+
+	h.mutex.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mutex.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber, closing its channel if still open
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.drop(sub)
+}
+
+// Publish sends change to every matching subscriber without blocking. A
+// subscriber whose buffer is full is dropped rather than holding up the rest.
+func (h *Hub) Publish(change models.EventChange) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for sub := range h.subscribers {
+		if !sub.filter.Matches(&change) {
+			continue
+		}
+		select {
+		case sub.c <- change:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Reset drops every subscriber. It is called when changes may have been
+// missed, so that clients resume from the change log instead.
+func (h *Hub) Reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for sub := range h.subscribers {
+		h.drop(sub)
+	}
+}
+
+// Subscribers returns how many subscribers are connected
+func (h *Hub) Subscribers() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.subscribers)
+}
+
+// drop removes sub and closes its channel; h.mutex must be held
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	close(sub.c)
+}
@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// received drains the changes buffered for sub and reports whether its
+// channel is still open
+func received(sub *Subscription) ([]int64, bool) {
+	var ids []int64
+	for {
+		select {
+		case change, ok := <-sub.C:
+			if !ok {
+				return ids, false
+			}
+			ids = append(ids, change.ID)
+		default:
+			return ids, true
+		}
+	}
+}
+
+func TestHub_PublishFilters(t *testing.T) {
+	hub := NewHub(10)
+	published := models.StatusPublished
+	all := hub.Subscribe(models.ChangeFilter{})
+	orders := hub.Subscribe(models.ChangeFilter{Type: "order.*"})
+	publishedOnly := hub.Subscribe(models.ChangeFilter{Status: &published})
+
+	hub.Publish(models.EventChange{ID: 1, Action: models.ChangeCreated, Type: "order.created", Status: models.StatusPending})
+	hub.Publish(models.EventChange{ID: 2, Action: models.ChangePublished, Type: "order.created", Status: models.StatusPublished})
+	hub.Publish(models.EventChange{ID: 3, Action: models.ChangePublished, Type: "user.created", Status: models.StatusPublished})
+
+	ids, open := received(all)
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.True(t, open)
+
+	ids, _ = received(orders)
+	assert.Equal(t, []int64{1, 2}, ids)
+
+	ids, _ = received(publishedOnly)
+	assert.Equal(t, []int64{2, 3}, ids)
+}
+
+func TestHub_DropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(2)
+	slow := hub.Subscribe(models.ChangeFilter{})
+
+	for id := int64(1); id <= 3; id++ {
+		hub.Publish(models.EventChange{ID: id})
+	}
+
+	ids, open := received(slow)
+	assert.Equal(t, []int64{1, 2}, ids)
+	assert.False(t, open)
+	assert.Equal(t, 0, hub.Subscribers())
+}
+
+func TestHub_UnsubscribeAndReset(t *testing.T) {
+	hub := NewHub(0)
+	first := hub.Subscribe(models.ChangeFilter{})
+	second := hub.Subscribe(models.ChangeFilter{})
+	assert.Equal(t, 2, hub.Subscribers())
+
+	hub.Unsubscribe(first)
+	// Unsubscribing twice, e.g. after being dropped, is harmless
+	hub.Unsubscribe(first)
+	_, open := received(first)
+	assert.False(t, open)
+	assert.Equal(t, 1, hub.Subscribers())
+
+	hub.Reset()
+	_, open = received(second)
+	assert.False(t, open)
+	assert.Equal(t, 0, hub.Subscribers())
+}
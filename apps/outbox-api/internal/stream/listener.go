@@ -0,0 +1,115 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/backoff"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// Listener forwards the event changes PostgreSQL notifies on
+// models.EventChangesChannel to a hub
+type Listener struct {
+	dsn string
+	hub *Hub
+	// pingInterval is how often an idle connection is checked
+	pingInterval time.Duration
+}
+
+// NewListener creates a listener connecting with dsn
+func NewListener(dsn string, hub *Hub) *Listener {
+	return &Listener{dsn: dsn, hub: hub, pingInterval: 90 * time.Second}
+}
+
+// Run listens for changes until ctx is cancelled, reconnecting whenever the
+// connection is lost and retrying with backoff if the channel cannot be
+// listened on. Subscribers are dropped whenever changes may have been missed,
+// and when it returns.
+func (l *Listener) Run(ctx context.Context) {
+	defer l.hub.Reset()
+
+	for attempt := 1; ; attempt++ {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			log.Printf("Event stream stopped")
+			return
+		}
+
+		// Nothing is forwarded until listening succeeds, so subscribers
+		// must reconnect and catch up from the log
+		l.hub.Reset()
+
+		delay := backoff.Delay(attempt, time.Second, time.Minute)
+		log.Printf("Event stream: failed to listen on %s, retrying in %s: %v", models.EventChangesChannel, delay, err)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Event stream stopped")
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// listen connects and forwards changes to the hub until ctx is cancelled,
+// returning early only if the channel cannot be listened on
+func (l *Listener) listen(ctx context.Context) error {
+	listener := pq.NewListener(l.dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event stream: listener connection error: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	// Listen waits for a connection for as long as it takes; closing the
+	// listener is the only way to stop it waiting
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	if err := listener.Listen(models.EventChangesChannel); err != nil {
+		return err
+	}
+
+	log.Printf("Event stream listening on %s", models.EventChangesChannel)
+	l.forward(ctx, listener.Notify, listener.Ping)
+	return nil
+}
+
+// forward publishes notifications to the hub until ctx is cancelled
+func (l *Listener) forward(ctx context.Context, notify <-chan *pq.Notification, ping func() error) {
+	defer l.hub.Reset()
+
+	ticker := time.NewTicker(l.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-notify:
+			if n == nil {
+				// pq sends nil after reconnecting: notifications sent while
+				// disconnected are lost, so clients must catch up from the log
+				l.hub.Reset()
+				continue
+			}
+
+			var change models.EventChange
+			if err := json.Unmarshal([]byte(n.Extra), &change); err != nil {
+				log.Printf("Event stream: ignoring malformed change %q: %v", n.Extra, err)
+				continue
+			}
+			l.hub.Publish(change)
+		case <-ticker.C:
+			go func() {
+				if err := ping(); err != nil {
+					log.Printf("Event stream: listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
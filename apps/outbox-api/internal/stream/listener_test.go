@@ -0,0 +1,102 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startForwarding runs forward in the background and returns the channel
+// notifications are sent on and a function that stops forwarding
+func startForwarding(t *testing.T, listener *Listener) (chan *pq.Notification, func()) {
+	t.Helper()
+
+	notify := make(chan *pq.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.forward(ctx, notify, func() error { return nil })
+	}()
+
+	return notify, func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("listener did not stop")
+		}
+	}
+}
+
+func TestListener_ForwardsChanges(t *testing.T) {
+	hub := NewHub(10)
+	sub := hub.Subscribe(models.ChangeFilter{})
+	notify, stop := startForwarding(t, NewListener("", hub))
+
+	notify <- &pq.Notification{Channel: models.EventChangesChannel, Extra: `not json`}
+	notify <- &pq.Notification{
+		Channel: models.EventChangesChannel,
+		Extra:   `{"id":7,"event_id":"event-1","action":"published","type":"order.created","status":"published","retry_count":1,"changed_at":"2024-06-01T12:00:00.123456+00:00"}`,
+	}
+
+	select {
+	case change := <-sub.C:
+		assert.Equal(t, int64(7), change.ID)
+		assert.Equal(t, "event-1", change.EventID)
+		assert.Equal(t, models.ChangePublished, change.Action)
+		assert.Equal(t, models.StatusPublished, change.Status)
+		assert.Equal(t, 1, change.RetryCount)
+		assert.True(t, change.ChangedAt.Equal(time.Date(2024, 6, 1, 12, 0, 0, 123456000, time.UTC)))
+	case <-time.After(time.Second):
+		t.Fatal("change was not forwarded")
+	}
+
+	stop()
+	_, open := <-sub.C
+	assert.False(t, open, "subscribers are dropped when the listener stops")
+}
+
+func TestListener_ResetsAfterReconnect(t *testing.T) {
+	hub := NewHub(10)
+	sub := hub.Subscribe(models.ChangeFilter{})
+	notify, stop := startForwarding(t, NewListener("", hub))
+	defer stop()
+
+	notify <- nil
+
+	select {
+	case _, open := <-sub.C:
+		require.False(t, open)
+	case <-time.After(time.Second):
+		t.Fatal("subscriber was not dropped")
+	}
+}
+
+func TestListener_RunStopsWhileDatabaseUnreachable(t *testing.T) {
+	hub := NewHub(10)
+	sub := hub.Subscribe(models.ChangeFilter{})
+	listener := NewListener("host=127.0.0.1 port=1 sslmode=disable connect_timeout=1", hub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.Run(ctx)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("listener did not stop")
+	}
+
+	_, open := <-sub.C
+	assert.False(t, open, "subscribers are dropped when the listener stops")
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,7 +19,11 @@ import (
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/config"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/gates"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/handlers"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/publisher"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/relay"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/retention"
 	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/storage"
+	"github.com/jared-scarr/portfolio-monorepo/apps/outbox-api/internal/stream"
 	observability "github.com/jared-scarr/portfolio-monorepo/packages/observability/handlers"
 )
 
@@ -34,12 +44,19 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// `outbox-api migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize database
 	db, err := storage.NewDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
 
 	// Initialize storage layer
 	store := storage.NewOutboxStore(db)
@@ -48,8 +65,68 @@ func main() {
 	flagsClient := gates.NewHTTPFeatureFlagClient(cfg.FeatureFlags.BaseURL)
 	simulationGates := gates.NewSimulationGates(flagsClient, cfg.FeatureFlags.Environment)
 
+	// Initialize the publisher events without a subscription are sent to
+	pub, err := publisher.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to create %s publisher: %v", cfg.Publish.Publisher, err)
+	}
+	defer pub.Close()
+	log.Printf("Publishing events via %s to %s", cfg.Publish.Publisher, pub.Destination())
+
 	// Initialize handlers
-	h := handlers.New(store, cfg, simulationGates)
+	opts := []handlers.Option{handlers.WithPublisher(pub)}
+	var changes *stream.Hub
+	if cfg.Stream.Enabled {
+		changes = stream.NewHub(cfg.Stream.Buffer)
+		opts = append(opts, handlers.WithChangeStream(changes))
+	}
+	h := handlers.New(store, cfg, simulationGates, opts...)
+
+	// Stop background work on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Listen for event changes from every replica to feed the event stream
+	if changes != nil {
+		go stream.NewListener(cfg.Database.DSN(), changes).Run(ctx)
+	} else {
+		log.Printf("Event stream disabled")
+	}
+
+	// Start the background relay that drains pending events. On shutdown it
+	// stops claiming straight away, but the batch in progress gets until the
+	// shutdown deadline to finish before relayCtx cuts it short.
+	relayCtx, cancelRelay := context.WithCancel(context.Background())
+	defer cancelRelay()
+	relayDone := make(chan struct{})
+	var r *relay.Relay
+	if cfg.Publish.RelayEnabled {
+		r = relay.New(h, cfg.Publish.BatchInterval(), cfg.Publish.BatchSize)
+		go func() {
+			defer close(relayDone)
+			r.Run(relayCtx)
+		}()
+	} else {
+		log.Printf("Relay disabled; events are only published via /admin/publish")
+		close(relayDone)
+	}
+
+	// Start the retention janitor that prunes old published and failed events
+	janitorDone := make(chan struct{})
+	if janitor := retention.New(store, cfg.Retention); janitor.Enabled() {
+		go func() {
+			defer close(janitorDone)
+			janitor.Run(ctx)
+		}()
+	} else {
+		log.Printf("Retention disabled; published and failed events are kept forever")
+		close(janitorDone)
+	}
+
+	// Refresh the queue depth and oldest pending age gauges from Postgres
+	sampler := observability.NewSampler(cfg.Metrics.SamplingInterval())
+	sampler.Add("outbox_queue", store.SampleQueueMetrics)
+	go sampler.Run(ctx)
 
 	// Setup Gin router
 	router := gin.Default()
@@ -76,7 +153,10 @@ func main() {
 	{
 		api.POST("/events", h.CreateEvent)
 		api.GET("/events", h.ListEvents)
+		api.GET("/events/stream", h.StreamEvents)
 		api.GET("/events/:id", h.GetEvent)
+		api.GET("/events/:id/deliveries", h.GetEventDeliveries)
+		api.GET("/events/:id/attempts", h.GetEventAttempts)
 		api.POST("/events/:id/retry", h.RetryEvent)
 		api.DELETE("/events/:id", h.DeleteEvent)
 	}
@@ -86,19 +166,98 @@ func main() {
 	{
 		admin.POST("/publish", h.PublishEvents)
 		admin.GET("/stats", h.GetStats)
+		admin.GET("/stats/timeseries", h.GetTimeseries)
 		admin.GET("/simulation-status", h.GetSimulationStatus)
+		admin.GET("/circuits", h.GetCircuits)
+
+		admin.POST("/events/bulk", h.StartBulkJob)
+		admin.GET("/events/bulk/:id", h.GetBulkJob)
+
+		admin.GET("/dead-letters", h.ListDeadLetters)
+		admin.DELETE("/dead-letters", h.PurgeDeadLetters)
+		admin.POST("/dead-letters/requeue", h.RequeueDeadLetters)
+		admin.GET("/dead-letters/:id", h.GetDeadLetter)
+		admin.DELETE("/dead-letters/:id", h.PurgeDeadLetter)
+		admin.POST("/dead-letters/:id/requeue", h.RequeueDeadLetter)
+
+		admin.GET("/subscriptions", h.ListSubscriptions)
+		admin.POST("/subscriptions", h.CreateSubscription)
+		admin.GET("/subscriptions/:id", h.GetSubscription)
+		admin.PUT("/subscriptions/:id", h.UpdateSubscription)
+		admin.POST("/subscriptions/:id/rotate-secret", h.RotateSubscriptionSecret)
+		admin.DELETE("/subscriptions/:id", h.DeleteSubscription)
+
+		admin.GET("/schemas", h.ListSchemas)
+		admin.POST("/schemas", h.RegisterSchema)
+		admin.GET("/schemas/:type", h.ListSchemaVersions)
+		admin.DELETE("/schemas/:type", h.DeleteSchema)
+		admin.GET("/schemas/:type/versions/:version", h.GetSchemaVersion)
+		admin.DELETE("/schemas/:type/versions/:version", h.DeleteSchemaVersion)
 	}
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	readTimeout, writeTimeout := cfg.ServerTimeouts()
+	srv := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+	// Event streams never finish on their own; ending them sends their
+	// clients to reconnect, to another replica
+	if changes != nil {
+		srv.RegisterOnShutdown(changes.Reset)
+	}
+
+	log.Printf("Starting outbox-api server on port %s", cfg.Server.Port)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	// A second signal exits immediately
+	stop()
+
+	// Report not ready first, so load balancers stop routing here while
+	// requests are still being accepted, and stop claiming new batches
+	observability.SetReady(false)
+	if r != nil {
+		r.Stop()
+	}
+	log.Printf("Shutdown signal received, draining for %s", cfg.Server.DrainDelay())
+	time.Sleep(cfg.Server.DrainDelay())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownDeadline())
+	defer cancel()
+
+	// Stop accepting requests, then give what is in flight until the deadline
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Requests still in flight at the shutdown deadline were cut off: %v", err)
+		srv.Close()
+	}
+
+	select {
+	case <-relayDone:
+	case <-shutdownCtx.Done():
+		// Deliveries in progress are cancelled and their events released
+		log.Printf("Relay batch still in flight at the shutdown deadline, cancelling it")
+		cancelRelay()
+		<-relayDone
+	}
+
+	// Jobs still running at the deadline are interrupted and recorded as
+	// failed while the database is still open
+	if err := h.WaitForJobs(shutdownCtx); err != nil {
+		log.Printf("Bulk jobs still running at the shutdown deadline were interrupted: %v", err)
 	}
+	<-janitorDone
 
-	log.Printf("Starting outbox-api server on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if err := db.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
 	}
+	log.Printf("Shutdown complete")
 }
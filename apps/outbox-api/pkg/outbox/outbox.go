@@ -0,0 +1,277 @@
+// Package outbox lets Go services write outbox events inside their own
+// database transactions, which is the point of the transactional outbox
+// pattern: the event is committed if and only if the business change is.
+//
+// The outbox-api relay picks up enqueued events and handles delivery, retries
+// and dead-lettering exactly as for events created through its HTTP API.
+//
+//	tx, err := db.BeginTx(ctx, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer tx.Rollback()
+//
+//	if _, err := tx.ExecContext(ctx, "INSERT INTO orders ...", ...); err != nil {
+//		return err
+//	}
+//
+//	_, err = outbox.EnqueueTx(ctx, tx, outbox.CreateEventRequest{
+//		Type:   "order.created",
+//		Source: "order-service",
+//		Data:   json.RawMessage(`{"order_id": "123"}`),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	return tx.Commit()
+//
+// The transaction must belong to the database the outbox-api uses, so that
+// the outbox_events table is visible to it.
+package outbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrIdempotencyConflict is returned when an idempotency key is reused for an
+// event with a different payload
+var ErrIdempotencyConflict = errors.New("idempotency key already used with a different payload")
+
+// DefaultIdempotencyRetention is how long EnqueueTx remembers an idempotency
+// key, the same as the outbox-api's IDEMPOTENCY_RETENTION default
+const DefaultIdempotencyRetention = 24 * time.Hour
+
+// MaxPartitionKeyLength matches the partition_key column
+const MaxPartitionKeyLength = 255
+
+// MaxPriority is the most urgent event priority; 0, the default, is the least
+const MaxPriority = 9
+
+// CreateEventRequest describes an event to add to the outbox
+type CreateEventRequest struct {
+	Type     string          `json:"type" binding:"required"`
+	Source   string          `json:"source" binding:"required"`
+	Data     json.RawMessage `json:"data" binding:"required"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+	// IdempotencyKey deduplicates retried submissions; the Idempotency-Key
+	// header may be used instead
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// PartitionKey, typically an aggregate ID, makes events that share it
+	// deliver strictly in order. A failed event holds back later events with
+	// the same key but no others.
+	PartitionKey string `json:"partition_key,omitempty"`
+	// DeliverAt holds the event back until the given time
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+	// ExpiresAt is the deadline for delivery. An event still undelivered by
+	// then moves to the expired status instead of being delivered late.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Priority, from 0 to MaxPriority, publishes urgent events ahead of
+	// older, less urgent ones. Waiting events slowly gain priority, so none
+	// is held back indefinitely.
+	Priority int `json:"priority,omitempty"`
+}
+
+// Validate checks that the request can be stored
+func (r *CreateEventRequest) Validate() error {
+	if r.Type == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if r.Source == "" {
+		return fmt.Errorf("event source is required")
+	}
+	if len(r.PartitionKey) > MaxPartitionKeyLength {
+		return fmt.Errorf("partition key must be at most %d characters", MaxPartitionKeyLength)
+	}
+	if r.Priority < 0 || r.Priority > MaxPriority {
+		return fmt.Errorf("priority must be between 0 and %d", MaxPriority)
+	}
+	if r.DeliverAt != nil && r.ExpiresAt != nil && !r.ExpiresAt.After(*r.DeliverAt) {
+		return fmt.Errorf("expires_at must be after deliver_at")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(r.Data, &value); err != nil {
+		return fmt.Errorf("invalid JSON in data field: %w", err)
+	}
+	if len(r.Metadata) > 0 {
+		if err := json.Unmarshal(r.Metadata, &value); err != nil {
+			return fmt.Errorf("invalid JSON in metadata field: %w", err)
+		}
+	}
+	return nil
+}
+
+// PayloadHash returns a SHA-256 fingerprint of the event content, used to
+// tell a retried submission from a different event reusing the same
+// idempotency key. JSON is normalised so formatting and key order don't matter.
+func (r *CreateEventRequest) PayloadHash() (string, error) {
+	var data, metadata interface{}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		return "", fmt.Errorf("invalid JSON in data field: %w", err)
+	}
+	if len(r.Metadata) > 0 {
+		if err := json.Unmarshal(r.Metadata, &metadata); err != nil {
+			return "", fmt.Errorf("invalid JSON in metadata field: %w", err)
+		}
+	}
+
+	fields := []interface{}{r.Type, r.Source, data, metadata}
+	// Appended only when set so that hashes stored before partition keys
+	// existed still match
+	if r.PartitionKey != "" {
+		fields = append(fields, r.PartitionKey)
+	}
+	if r.DeliverAt != nil || r.ExpiresAt != nil {
+		fields = append(fields, r.DeliverAt, r.ExpiresAt)
+	}
+	if r.Priority != 0 {
+		fields = append(fields, r.Priority)
+	}
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode event payload: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// EnqueueTx inserts a pending event into outbox_events as part of tx and
+// returns its ID. Nothing is visible to the relay until tx commits.
+//
+// With an IdempotencyKey, enqueuing the same payload again returns the ID of
+// the event already stored under that key instead of inserting a duplicate;
+// a different payload returns ErrIdempotencyConflict. Either way tx remains
+// usable. Keys are remembered for DefaultIdempotencyRetention.
+func EnqueueTx(ctx context.Context, tx *sql.Tx, req CreateEventRequest) (string, error) {
+	return EnqueueTxWithRetention(ctx, tx, req, DefaultIdempotencyRetention)
+}
+
+// EnqueueTxWithRetention is EnqueueTx for services that run the outbox-api
+// with a different IDEMPOTENCY_RETENTION: a key older than retention is
+// released and may be reused for a new event.
+func EnqueueTxWithRetention(ctx context.Context, tx *sql.Tx, req CreateEventRequest, retention time.Duration) (string, error) {
+	if tx == nil {
+		return "", fmt.Errorf("transaction is required")
+	}
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+
+	var metadata, partitionKey, deliverAt, expiresAt interface{}
+	if len(req.Metadata) > 0 {
+		metadata = req.Metadata
+	}
+	if req.PartitionKey != "" {
+		partitionKey = req.PartitionKey
+	}
+	if req.DeliverAt != nil {
+		deliverAt = *req.DeliverAt
+	}
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+
+	if req.IdempotencyKey == "" {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+			VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $8, $9, $10, $11)
+		`, id, req.Type, req.Source, req.Data, metadata, now, now, partitionKey, deliverAt, expiresAt, req.Priority)
+		if err != nil {
+			return "", fmt.Errorf("failed to enqueue event: %w", err)
+		}
+		return id, nil
+	}
+
+	requestHash, err := req.PayloadHash()
+	if err != nil {
+		return "", err
+	}
+
+	if err := ReleaseExpiredKey(ctx, tx, req.IdempotencyKey, retention); err != nil {
+		return "", err
+	}
+
+	// A dead-lettered event keeps its key until it is requeued or purged
+	var deadLetterID string
+	var deadLetterHash sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, request_hash
+		FROM outbox_dead_letters
+		WHERE idempotency_key = $1
+	`, req.IdempotencyKey).Scan(&deadLetterID, &deadLetterHash)
+	if err == nil {
+		if deadLetterHash.String != requestHash {
+			return "", ErrIdempotencyConflict
+		}
+		return deadLetterID, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get dead letter for idempotency key: %w", err)
+	}
+
+	// ON CONFLICT keeps a duplicate key from aborting the caller's transaction
+	var insertedID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING id
+	`, id, req.Type, req.Source, req.Data, metadata, now, now, partitionKey, deliverAt, expiresAt, req.Priority, req.IdempotencyKey, requestHash).Scan(&insertedID)
+	if err == nil {
+		return insertedID, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to enqueue event: %w", err)
+	}
+
+	var existingID string
+	var existingHash sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, request_hash
+		FROM outbox_events
+		WHERE idempotency_key = $1
+	`, req.IdempotencyKey).Scan(&existingID, &existingHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get event for idempotency key: %w", err)
+	}
+
+	if existingHash.String != requestHash {
+		return "", ErrIdempotencyConflict
+	}
+
+	return existingID, nil
+}
+
+// ReleaseExpiredKey frees an idempotency key held by an event or dead letter
+// created more than retention ago, so that the key can be used for a new event
+func ReleaseExpiredKey(ctx context.Context, tx *sql.Tx, key string, retention time.Duration) error {
+	_, err := tx.ExecContext(ctx, `
+		WITH dead_letters AS (
+			UPDATE outbox_dead_letters
+			SET idempotency_key = NULL, request_hash = NULL
+			WHERE idempotency_key = $1 AND created_at < $2
+		)
+		UPDATE outbox_events
+		SET idempotency_key = NULL, request_hash = NULL
+		WHERE idempotency_key = $1 AND created_at < $2
+	`, key, time.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("failed to expire idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEventRequest_Validate(t *testing.T) {
+	deliverAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := deliverAt.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		request       CreateEventRequest
+		expectedError string
+	}{
+		{
+			name:    "valid request",
+			request: CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{"order_id": "123"}`)},
+		},
+		{
+			name:    "valid request with metadata",
+			request: CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), Metadata: json.RawMessage(`{"trace_id": "abc"}`)},
+		},
+		{
+			name:          "missing type",
+			request:       CreateEventRequest{Source: "order-service", Data: json.RawMessage(`{}`)},
+			expectedError: "event type is required",
+		},
+		{
+			name:          "missing source",
+			request:       CreateEventRequest{Type: "order.created", Data: json.RawMessage(`{}`)},
+			expectedError: "event source is required",
+		},
+		{
+			name:          "missing data",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service"},
+			expectedError: "invalid JSON in data field",
+		},
+		{
+			name:    "valid request with partition key",
+			request: CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), PartitionKey: "order-123"},
+		},
+		{
+			name:          "partition key too long",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), PartitionKey: strings.Repeat("k", 256)},
+			expectedError: "partition key must be at most 255 characters",
+		},
+		{
+			name:    "valid scheduled request",
+			request: CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), DeliverAt: &deliverAt, ExpiresAt: &expiresAt},
+		},
+		{
+			name:    "valid request with priority",
+			request: CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), Priority: MaxPriority},
+		},
+		{
+			name:          "priority out of range",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), Priority: 10},
+			expectedError: "priority must be between 0 and 9",
+		},
+		{
+			name:          "negative priority",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), Priority: -1},
+			expectedError: "priority must be between 0 and 9",
+		},
+		{
+			name:          "expires before delivery",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), DeliverAt: &expiresAt, ExpiresAt: &deliverAt},
+			expectedError: "expires_at must be after deliver_at",
+		},
+		{
+			name:          "invalid metadata",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`), Metadata: json.RawMessage(`invalid json`)},
+			expectedError: "invalid JSON in metadata field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateEventRequest_PayloadHash(t *testing.T) {
+	base := CreateEventRequest{
+		Type:   "order.created",
+		Source: "order-service",
+		Data:   json.RawMessage(`{"order_id": "123", "total": 10}`),
+	}
+
+	hash, err := base.PayloadHash()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	reformatted := base
+	reformatted.Data = json.RawMessage(`{"total":10,"order_id":"123"}`)
+	reformatted.IdempotencyKey = "key-1"
+	reformattedHash, err := reformatted.PayloadHash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, reformattedHash, "formatting, key order and the key itself must not change the hash")
+
+	changed := base
+	changed.Data = json.RawMessage(`{"order_id": "124", "total": 10}`)
+	changedHash, err := changed.PayloadHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changedHash)
+
+	withMetadata := base
+	withMetadata.Metadata = json.RawMessage(`{"trace_id": "abc"}`)
+	metadataHash, err := withMetadata.PayloadHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, metadataHash)
+
+	partitioned := base
+	partitioned.PartitionKey = "order-123"
+	partitionedHash, err := partitioned.PayloadHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, partitionedHash)
+
+	deliverAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	scheduled := base
+	scheduled.DeliverAt = &deliverAt
+	scheduledHash, err := scheduled.PayloadHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, scheduledHash)
+
+	prioritized := base
+	prioritized.Priority = 5
+	prioritizedHash, err := prioritized.PayloadHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, prioritizedHash)
+
+	invalid := base
+	invalid.Data = json.RawMessage(`{not json`)
+	_, err = invalid.PayloadHash()
+	assert.Error(t, err)
+}
+
+func TestEnqueueTx(t *testing.T) {
+	request := CreateEventRequest{
+		Type:   "order.created",
+		Source: "order-service",
+		Data:   json.RawMessage(`{"order_id": "123"}`),
+	}
+	keyed := request
+	keyed.IdempotencyKey = "order-123"
+	requestHash, err := keyed.PayloadHash()
+	require.NoError(t, err)
+
+	partitioned := request
+	partitioned.PartitionKey = "order-123"
+
+	urgent := request
+	urgent.Priority = 7
+
+	deliverAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := deliverAt.Add(time.Hour)
+	scheduled := request
+	scheduled.DeliverAt = &deliverAt
+	scheduled.ExpiresAt = &expiresAt
+
+	insertQuery := `INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority)
+			VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $8, $9, $10, $11)`
+	keyedInsertQuery := `INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING id`
+	existingQuery := `SELECT id, request_hash
+		FROM outbox_events
+		WHERE idempotency_key = $1`
+	deadLetterQuery := `SELECT id, request_hash
+		FROM outbox_dead_letters
+		WHERE idempotency_key = $1`
+	noDeadLetter := sqlmock.NewRows([]string{"id", "request_hash"})
+	releaseQuery := `WITH dead_letters AS (
+			UPDATE outbox_dead_letters
+			SET idempotency_key = NULL, request_hash = NULL
+			WHERE idempotency_key = $1 AND created_at < $2
+		)
+		UPDATE outbox_events
+		SET idempotency_key = NULL, request_hash = NULL
+		WHERE idempotency_key = $1 AND created_at < $2`
+
+	tests := []struct {
+		name          string
+		request       CreateEventRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedID    string
+		expectedError string
+	}{
+		{
+			name:    "enqueue event",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insertQuery).
+					WithArgs(sqlmock.AnyArg(), "order.created", "order-service", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "enqueue event with partition key",
+			request: partitioned,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insertQuery).
+					WithArgs(sqlmock.AnyArg(), "order.created", "order-service", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), "order-123", nil, nil, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "enqueue urgent event",
+			request: urgent,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insertQuery).
+					WithArgs(sqlmock.AnyArg(), "order.created", "order-service", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "enqueue scheduled event",
+			request: scheduled,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insertQuery).
+					WithArgs(sqlmock.AnyArg(), "order.created", "order-service", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, deliverAt, expiresAt, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "enqueue event with idempotency key",
+			request: keyed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(releaseQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(noDeadLetter)
+				mock.ExpectQuery(keyedInsertQuery).
+					WithArgs(sqlmock.AnyArg(), "order.created", "order-service", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, 0, "order-123", requestHash).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("new-id"))
+			},
+			expectedID: "new-id",
+		},
+		{
+			name:    "duplicate idempotency key returns existing event",
+			request: keyed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(releaseQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(noDeadLetter)
+				mock.ExpectQuery(keyedInsertQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectQuery(existingQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "request_hash"}).AddRow("original-id", requestHash))
+			},
+			expectedID: "original-id",
+		},
+		{
+			name:    "idempotency key reused with different payload",
+			request: keyed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(releaseQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").WillReturnRows(noDeadLetter)
+				mock.ExpectQuery(keyedInsertQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectQuery(existingQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "request_hash"}).AddRow("original-id", "other-hash"))
+			},
+			expectedError: ErrIdempotencyConflict.Error(),
+		},
+		{
+			name:    "dead-lettered idempotency key returns dead letter",
+			request: keyed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(releaseQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "request_hash"}).AddRow("dead-id", requestHash))
+			},
+			expectedID: "dead-id",
+		},
+		{
+			name:    "dead-lettered idempotency key reused with different payload",
+			request: keyed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(releaseQuery).WithArgs("order-123", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(deadLetterQuery).WithArgs("order-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "request_hash"}).AddRow("dead-id", "other-hash"))
+			},
+			expectedError: ErrIdempotencyConflict.Error(),
+		},
+		{
+			name:    "release expired idempotency key error",
+			request: keyed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(releaseQuery).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to expire idempotency key",
+		},
+		{
+			name:          "invalid request",
+			request:       CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`invalid json`)},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid JSON in data field",
+		},
+		{
+			name:    "insert error",
+			request: request,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insertQuery).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "failed to enqueue event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectBegin()
+			tt.mockSetup(mock)
+
+			tx, err := db.Begin()
+			require.NoError(t, err)
+
+			id, err := EnqueueTx(context.Background(), tx, tt.request)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Empty(t, id)
+			} else {
+				require.NoError(t, err)
+				if tt.expectedID != "" {
+					assert.Equal(t, tt.expectedID, id)
+				} else {
+					assert.NotEmpty(t, id)
+				}
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEnqueueTxWithRetention(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`WITH dead_letters AS (
+			UPDATE outbox_dead_letters
+			SET idempotency_key = NULL, request_hash = NULL
+			WHERE idempotency_key = $1 AND created_at < $2
+		)
+		UPDATE outbox_events
+		SET idempotency_key = NULL, request_hash = NULL
+		WHERE idempotency_key = $1 AND created_at < $2`).
+		WithArgs("order-123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, request_hash
+		FROM outbox_dead_letters
+		WHERE idempotency_key = $1`).
+		WithArgs("order-123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "request_hash"}))
+	mock.ExpectQuery(`INSERT INTO outbox_events (id, type, source, data, metadata, status, created_at, updated_at, partition_key, deliver_at, expires_at, priority, idempotency_key, request_hash)
+		VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("new-id"))
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	id, err := EnqueueTxWithRetention(context.Background(), tx, CreateEventRequest{
+		Type:           "order.created",
+		Source:         "order-service",
+		Data:           json.RawMessage(`{"order_id": "123"}`),
+		IdempotencyKey: "order-123",
+	}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "new-id", id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnqueueTx_RequiresTransaction(t *testing.T) {
+	_, err := EnqueueTx(context.Background(), nil, CreateEventRequest{Type: "order.created", Source: "order-service", Data: json.RawMessage(`{}`)})
+	assert.EqualError(t, err, "transaction is required")
+}
@@ -0,0 +1,215 @@
+// Package webhook signs outbox-api webhook deliveries and lets consumers
+// verify them.
+//
+// Every signed delivery carries two headers:
+//
+//	X-Outbox-Timestamp: 1700000000
+//	X-Outbox-Signature: v1=5257a869...,v1=9f86d081...
+//
+// Each v1 value is the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed
+// with one of the destination's active secrets. During a key rotation the
+// delivery is signed with both the new and the previous secret, so a consumer
+// can switch secrets at any point within the grace period.
+//
+// A CloudEvents delivery in binary content mode carries the event's
+// attributes as ce- headers, and those are signed too: the body is preceded
+// by one "<name>:<value>\n" line per ce- header, with lowercase names in
+// sorted order, e.g.
+//
+//	1700000000.ce-id:event-1
+//	ce-source:order-service
+//	ce-specversion:1.0
+//	ce-type:order.created
+//	{"order_id":"123"}
+//
+// A consumer verifies a request with:
+//
+//	body, err := webhook.VerifyRequest(r, webhook.DefaultTolerance, secret)
+//	if err != nil {
+//		http.Error(w, "invalid signature", http.StatusUnauthorized)
+//		return
+//	}
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimestampHeader carries the Unix time, in seconds, at which the delivery was signed
+	TimestampHeader = "X-Outbox-Timestamp"
+	// SignatureHeader carries one signature per active secret
+	SignatureHeader = "X-Outbox-Signature"
+	// DefaultTolerance is the maximum accepted age of a delivery, limiting replays
+	DefaultTolerance = 5 * time.Minute
+
+	signatureScheme = "v1"
+	secretPrefix    = "whsec_"
+
+	// cloudEventsHeaderPrefix starts the headers carrying CloudEvents
+	// attributes in binary content mode
+	cloudEventsHeaderPrefix = "ce-"
+)
+
+var (
+	ErrMissingTimestamp = errors.New("missing " + TimestampHeader + " header")
+	ErrMissingSignature = errors.New("missing " + SignatureHeader + " header")
+	ErrInvalidTimestamp = errors.New("invalid " + TimestampHeader + " header")
+	ErrTimestampExpired = errors.New("timestamp outside the tolerance window")
+	ErrNoSecrets        = errors.New("no secrets to verify against")
+	ErrInvalidSignature = errors.New("no signature matches")
+)
+
+// Sign returns the hex encoded HMAC-SHA256 signature of body at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the timestamp and signature headers on req, signing body
+// and any ce- headers already set on req with every non-empty secret. It does
+// nothing when no secret is given.
+func SignRequest(req *http.Request, body []byte, timestamp time.Time, secrets ...string) {
+	content := signedContent(req.Header, body)
+
+	var signatures []string
+	for _, secret := range secrets {
+		if secret != "" {
+			signatures = append(signatures, signatureScheme+"="+Sign(secret, timestamp, content))
+		}
+	}
+
+	if len(signatures) == 0 {
+		return
+	}
+
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(SignatureHeader, strings.Join(signatures, ","))
+}
+
+// Verify checks that header carries a signature of body, and of any ce-
+// headers in header, made with one of secrets no more than tolerance ago. A
+// tolerance of zero disables the age check.
+func Verify(header http.Header, body []byte, tolerance time.Duration, secrets ...string) error {
+	timestampValue := header.Get(TimestampHeader)
+	if timestampValue == "" {
+		return ErrMissingTimestamp
+	}
+
+	signatureValue := header.Get(SignatureHeader)
+	if signatureValue == "" {
+		return ErrMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestampValue, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	timestamp := time.Unix(seconds, 0)
+
+	if tolerance > 0 {
+		age := time.Since(timestamp)
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	content := signedContent(header, body)
+
+	var candidates [][]byte
+	for _, secret := range secrets {
+		if secret != "" {
+			expected, _ := hex.DecodeString(Sign(secret, timestamp, content))
+			candidates = append(candidates, expected)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ErrNoSecrets
+	}
+
+	for _, part := range strings.Split(signatureValue, ",") {
+		scheme, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || scheme != signatureScheme {
+			continue
+		}
+
+		signature, err := hex.DecodeString(value)
+		if err != nil {
+			continue
+		}
+
+		for _, expected := range candidates {
+			if hmac.Equal(signature, expected) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// signedContent returns what is signed for a delivery: body, preceded by a
+// "<name>:<value>\n" line for each ce- header in name order. A header sent
+// more than once is signed with its values joined by commas.
+func signedContent(header http.Header, body []byte) []byte {
+	attributes := make(map[string]string)
+	for name, values := range header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, cloudEventsHeaderPrefix) {
+			attributes[name] = strings.Join(values, ",")
+		}
+	}
+	if len(attributes) == 0 {
+		return body
+	}
+
+	var content bytes.Buffer
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		content.WriteString(name + ":" + attributes[name] + "\n")
+	}
+	content.Write(body)
+	return content.Bytes()
+}
+
+// VerifyRequest reads and verifies the body of r, returning it on success.
+// The body is restored on r so that it can be read again by later handlers.
+func VerifyRequest(r *http.Request, tolerance time.Duration, secrets ...string) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(r.Header, body, tolerance, secrets...); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// NewSecret generates a random signing secret
+func NewSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return secretPrefix + hex.EncodeToString(key), nil
+}
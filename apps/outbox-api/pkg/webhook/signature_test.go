@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignedRequest(t *testing.T, body []byte, timestamp time.Time, secrets ...string) *http.Request {
+	req, err := http.NewRequest("POST", "http://consumer/webhook", bytes.NewReader(body))
+	require.NoError(t, err)
+	SignRequest(req, body, timestamp, secrets...)
+	return req
+}
+
+func TestSign_KnownVector(t *testing.T) {
+	// echo -n '1700000000.{"id":"event-1"}' | openssl dgst -sha256 -hmac secret
+	signature := Sign("secret", time.Unix(1700000000, 0), []byte(`{"id":"event-1"}`))
+	assert.Equal(t, "01017e2b3bf7b2f3c53c64a662fb4ee9c60a8a998e81d1b19dcfd0aa2de23880", signature)
+}
+
+func TestSignRequest(t *testing.T) {
+	body := []byte(`{"id":"event-1"}`)
+	timestamp := time.Unix(1700000000, 0)
+
+	req := newSignedRequest(t, body, timestamp, "new-secret", "", "old-secret")
+	assert.Equal(t, "1700000000", req.Header.Get(TimestampHeader))
+
+	signatures := strings.Split(req.Header.Get(SignatureHeader), ",")
+	require.Len(t, signatures, 2)
+	assert.Equal(t, "v1="+Sign("new-secret", timestamp, body), signatures[0])
+	assert.Equal(t, "v1="+Sign("old-secret", timestamp, body), signatures[1])
+
+	unsigned := newSignedRequest(t, body, timestamp)
+	assert.Empty(t, unsigned.Header.Get(TimestampHeader))
+	assert.Empty(t, unsigned.Header.Get(SignatureHeader))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"event-1","type":"order.created"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		header        func() http.Header
+		body          []byte
+		tolerance     time.Duration
+		secrets       []string
+		expectedError error
+	}{
+		{
+			name:    "valid signature",
+			header:  func() http.Header { return newSignedRequest(t, body, now, "secret").Header },
+			secrets: []string{"secret"},
+		},
+		{
+			name:    "consumer still on the previous secret during rotation",
+			header:  func() http.Header { return newSignedRequest(t, body, now, "new-secret", "old-secret").Header },
+			secrets: []string{"old-secret"},
+		},
+		{
+			name:    "consumer holding both secrets",
+			header:  func() http.Header { return newSignedRequest(t, body, now, "new-secret").Header },
+			secrets: []string{"old-secret", "new-secret"},
+		},
+		{
+			name:          "wrong secret",
+			header:        func() http.Header { return newSignedRequest(t, body, now, "secret").Header },
+			secrets:       []string{"other-secret"},
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered body",
+			header:        func() http.Header { return newSignedRequest(t, body, now, "secret").Header },
+			body:          []byte(`{"id":"event-2","type":"order.created"}`),
+			secrets:       []string{"secret"},
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "stale timestamp",
+			header:        func() http.Header { return newSignedRequest(t, body, now.Add(-10*time.Minute), "secret").Header },
+			secrets:       []string{"secret"},
+			expectedError: ErrTimestampExpired,
+		},
+		{
+			name:      "stale timestamp with tolerance disabled",
+			header:    func() http.Header { return newSignedRequest(t, body, now.Add(-10*time.Minute), "secret").Header },
+			tolerance: -1,
+			secrets:   []string{"secret"},
+		},
+		{
+			name: "replayed signature with a new timestamp",
+			header: func() http.Header {
+				header := newSignedRequest(t, body, now.Add(-time.Minute), "secret").Header
+				header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+				return header
+			},
+			secrets:       []string{"secret"},
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "missing timestamp",
+			header:        func() http.Header { return http.Header{SignatureHeader: []string{"v1=00"}} },
+			secrets:       []string{"secret"},
+			expectedError: ErrMissingTimestamp,
+		},
+		{
+			name:          "missing signature",
+			header:        func() http.Header { return http.Header{TimestampHeader: []string{"1700000000"}} },
+			secrets:       []string{"secret"},
+			expectedError: ErrMissingSignature,
+		},
+		{
+			name: "malformed timestamp",
+			header: func() http.Header {
+				return http.Header{TimestampHeader: []string{"yesterday"}, SignatureHeader: []string{"v1=00"}}
+			},
+			secrets:       []string{"secret"},
+			expectedError: ErrInvalidTimestamp,
+		},
+		{
+			name:          "no secrets",
+			header:        func() http.Header { return newSignedRequest(t, body, now, "secret").Header },
+			secrets:       []string{""},
+			expectedError: ErrNoSecrets,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifyBody := body
+			if tt.body != nil {
+				verifyBody = tt.body
+			}
+
+			tolerance := tt.tolerance
+			if tolerance == 0 {
+				tolerance = DefaultTolerance
+			} else if tolerance < 0 {
+				tolerance = 0
+			}
+
+			err := Verify(tt.header(), verifyBody, tolerance, tt.secrets...)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSignRequest_CloudEventsAttributes(t *testing.T) {
+	body := []byte(`{"order_id":"123"}`)
+	timestamp := time.Unix(1700000000, 0)
+
+	newBinaryRequest := func() *http.Request {
+		req, err := http.NewRequest("POST", "http://consumer/webhook", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("ce-type", "order.created")
+		req.Header.Set("ce-id", "event-1")
+		req.Header.Set("ce-source", "order-service")
+		req.Header.Set("ce-specversion", "1.0")
+		SignRequest(req, body, timestamp, "secret")
+		return req
+	}
+
+	req := newBinaryRequest()
+	content := "ce-id:event-1\nce-source:order-service\nce-specversion:1.0\nce-type:order.created\n" + string(body)
+	assert.Equal(t, "v1="+Sign("secret", timestamp, []byte(content)), req.Header.Get(SignatureHeader))
+	assert.NoError(t, Verify(req.Header, body, 0, "secret"))
+
+	tampered := newBinaryRequest()
+	tampered.Header.Set("ce-type", "order.refunded")
+	assert.ErrorIs(t, Verify(tampered.Header, body, 0, "secret"), ErrInvalidSignature)
+
+	stripped := newBinaryRequest()
+	stripped.Header.Del("ce-id")
+	assert.ErrorIs(t, Verify(stripped.Header, body, 0, "secret"), ErrInvalidSignature)
+
+	added := newBinaryRequest()
+	added.Header.Set("ce-subject", "order-123")
+	assert.ErrorIs(t, Verify(added.Header, body, 0, "secret"), ErrInvalidSignature)
+}
+
+func TestVerifyRequest_RestoresBody(t *testing.T) {
+	body := []byte(`{"id":"event-1"}`)
+	req := newSignedRequest(t, body, time.Now(), "secret")
+
+	verified, err := VerifyRequest(req, DefaultTolerance, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, body, verified)
+
+	again, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, again)
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	require.NoError(t, err)
+	second, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.Len(t, first, len("whsec_")+64)
+	assert.NotEqual(t, first, second)
+}
@@ -37,7 +37,7 @@ const docTemplate = `{
         },
         "/ready": {
             "get": {
-                "description": "Checks dependencies like Postgres/Redis",
+                "description": "Returns 503 once the service has started shutting down",
                 "tags": [
                     "health"
                 ],
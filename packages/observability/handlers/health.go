@@ -1,6 +1,21 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// draining is set once the service is shutting down, so that load balancers
+// stop routing to it before it stops accepting connections
+var draining atomic.Bool
+
+// SetReady sets whether Ready reports the service as ready. Services call
+// SetReady(false) when they start shutting down.
+func SetReady(ready bool) {
+	draining.Store(!ready)
+}
 
 // Health godoc
 // @Summary Liveness probe
@@ -9,17 +24,20 @@ import "github.com/gin-gonic/gin"
 // @Success 200 {object} map[string]string
 // @Router /health [get]
 func Health(c *gin.Context) {
-    c.JSON(200, gin.H{"status": "ok"})
+	c.JSON(200, gin.H{"status": "ok"})
 }
 
 // Ready godoc
 // @Summary Readiness probe
-// @Description Checks dependencies like Postgres/Redis
+// @Description Returns 503 once the service has started shutting down
 // @Tags health
 // @Success 200 {object} map[string]string
 // @Failure 503 {object} map[string]string
 // @Router /ready [get]
 func Ready(c *gin.Context) {
-    // For now, always ready
-    c.JSON(200, gin.H{"status": "ready"})
+	if draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+	c.JSON(200, gin.H{"status": "ready"})
 }
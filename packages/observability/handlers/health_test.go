@@ -90,6 +90,31 @@ func TestReady(t *testing.T) {
 	}
 }
 
+func TestReady_ShuttingDown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { SetReady(true) })
+
+	router := gin.New()
+	router.GET("/ready", Ready)
+
+	SetReady(false)
+
+	req, _ := http.NewRequest("GET", "/ready", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var actualResponse gin.H
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualResponse))
+	assert.Equal(t, gin.H{"status": "shutting down"}, actualResponse)
+
+	SetReady(true)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 // Test both health endpoints together
 func TestHealthEndpoints(t *testing.T) {
 	gin.SetMode(gin.TestMode)
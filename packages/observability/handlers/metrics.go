@@ -37,6 +37,13 @@ func init() {
 	)
 }
 
+// MustRegister registers a service's own metrics so that Metrics serves
+// them next to the HTTP and runtime metrics. Like prometheus.MustRegister it
+// panics if a metric is already registered, so call it from init or main.
+func MustRegister(collectors ...prometheus.Collector) {
+	prometheus.MustRegister(collectors...)
+}
+
 // Metrics (Prometheus text)
 func Metrics(c *gin.Context) {
 	promhttp.Handler().ServeHTTP(c.Writer, c.Request)
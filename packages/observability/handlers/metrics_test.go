@@ -248,6 +248,33 @@ func TestMetricsRegistration(t *testing.T) {
 	assert.True(t, foundRequestDuration, "http_request_duration_seconds metric should be registered")
 }
 
+func TestMustRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test_must_register_jobs_total",
+		Help: "Jobs run by the test service",
+	})
+	MustRegister(jobsTotal)
+	defer prometheus.Unregister(jobsTotal)
+	jobsTotal.Add(3)
+
+	router := gin.New()
+	router.GET("/metrics", Metrics)
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "test_must_register_jobs_total 3")
+
+	// Registering the same metric twice is a programming error
+	assert.Panics(t, func() {
+		MustRegister(jobsTotal)
+	})
+}
+
 // Benchmark tests for performance
 func BenchmarkMetrics(b *testing.B) {
 	gin.SetMode(gin.TestMode)
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var sampleErrorsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metrics_sample_errors_total",
+		Help: "Samples of gauges mirroring external state that failed",
+	},
+	[]string{"sample"},
+)
+
+func init() {
+	prometheus.MustRegister(sampleErrorsTotal)
+}
+
+// SampleFunc reads state held elsewhere and sets the gauges that mirror it
+type SampleFunc func(ctx context.Context) error
+
+type sample struct {
+	name string
+	fn   SampleFunc
+}
+
+// Sampler keeps gauges that mirror state held elsewhere, such as the depth
+// of a queue in a database, up to date by sampling it on an interval. Scrapes
+// read the last values instead of waiting on the source, and the load on the
+// source does not grow with the number of scrapers.
+type Sampler struct {
+	interval time.Duration
+	samples  []sample
+}
+
+// NewSampler creates a sampler that runs its samples every interval
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{interval: interval}
+}
+
+// Add registers a sample. Add must not be called once Run has started.
+func (s *Sampler) Add(name string, fn SampleFunc) {
+	s.samples = append(s.samples, sample{name: name, fn: fn})
+}
+
+// Run samples immediately and then every interval until ctx is cancelled.
+// Each sample gets at most one interval to finish; failures are logged and
+// counted in metrics_sample_errors_total, and leave the gauges at their last
+// values.
+func (s *Sampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sampleAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) sampleAll(ctx context.Context) {
+	for _, sample := range s.samples {
+		sampleCtx, cancel := context.WithTimeout(ctx, s.interval)
+		err := sample.fn(sampleCtx)
+		cancel()
+
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Metrics: failed to sample %s: %v", sample.name, err)
+			sampleErrorsTotal.WithLabelValues(sample.name).Inc()
+		}
+	}
+}
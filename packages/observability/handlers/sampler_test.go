@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler_Run(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedErrors float64
+	}{
+		{
+			name:           "successful samples",
+			expectedErrors: 0,
+		},
+		{
+			// The second sample cancels the sampler, and a sample cut short
+			// by shutdown is not an error
+			name:           "failing samples are counted",
+			err:            errors.New("connection refused"),
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampleErrorsTotal.Reset()
+
+			var calls atomic.Int32
+			ctx, cancel := context.WithCancel(context.Background())
+
+			sampler := NewSampler(10 * time.Millisecond)
+			sampler.Add("queue_depth", func(sampleCtx context.Context) error {
+				_, hasDeadline := sampleCtx.Deadline()
+				assert.True(t, hasDeadline)
+
+				if calls.Add(1) == 2 {
+					cancel()
+				}
+				return tt.err
+			})
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				sampler.Run(ctx)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("sampler did not stop")
+			}
+
+			// The first sample runs straight away, the second after one interval
+			require.Equal(t, int32(2), calls.Load())
+			assert.Equal(t, tt.expectedErrors, testutil.ToFloat64(sampleErrorsTotal.WithLabelValues("queue_depth")))
+		})
+	}
+}
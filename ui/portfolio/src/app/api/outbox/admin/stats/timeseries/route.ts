@@ -0,0 +1,34 @@
+import { NextRequest, NextResponse } from "next/server";
+
+const OUTBOX_API_URL =
+  process.env.NEXT_PUBLIC_OUTBOX_API_URL || "http://localhost:8080";
+
+export async function GET(request: NextRequest) {
+  try {
+    const { searchParams } = new URL(request.url);
+    const queryString = searchParams.toString();
+    const url = `${OUTBOX_API_URL}/admin/stats/timeseries${
+      queryString ? `?${queryString}` : ""
+    }`;
+
+    const response = await fetch(url, {
+      method: "GET",
+      headers: {
+        "Content-Type": "application/json",
+      },
+    });
+
+    if (!response.ok) {
+      throw new Error(`Outbox API error: ${response.status}`);
+    }
+
+    const data = await response.json();
+    return NextResponse.json(data);
+  } catch (error) {
+    console.error("Error fetching timeseries stats:", error);
+    return NextResponse.json(
+      { error: "Failed to fetch timeseries stats" },
+      { status: 500 }
+    );
+  }
+}
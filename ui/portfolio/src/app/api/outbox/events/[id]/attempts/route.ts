@@ -0,0 +1,36 @@
+import { NextRequest, NextResponse } from "next/server";
+
+const OUTBOX_API_URL =
+  process.env.NEXT_PUBLIC_OUTBOX_API_URL || "http://localhost:8080";
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ id: string }> }
+) {
+  try {
+    const { id } = await params;
+
+    const response = await fetch(
+      `${OUTBOX_API_URL}/api/v1/events/${id}/attempts`,
+      {
+        method: "GET",
+        headers: {
+          "Content-Type": "application/json",
+        },
+      }
+    );
+
+    if (!response.ok) {
+      throw new Error(`Outbox API error: ${response.status}`);
+    }
+
+    const data = await response.json();
+    return NextResponse.json(data);
+  } catch (error) {
+    console.error("Error fetching delivery attempts:", error);
+    return NextResponse.json(
+      { error: "Failed to fetch delivery attempts" },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,46 @@
+import { NextRequest, NextResponse } from "next/server";
+
+const OUTBOX_API_URL =
+  process.env.NEXT_PUBLIC_OUTBOX_API_URL || "http://localhost:8080";
+
+export async function GET(request: NextRequest) {
+  try {
+    const { searchParams } = new URL(request.url);
+    const queryString = searchParams.toString();
+    const url = `${OUTBOX_API_URL}/api/v1/events/stream${
+      queryString ? `?${queryString}` : ""
+    }`;
+
+    // EventSource sends the last ID it saw when it reconnects
+    const headers: Record<string, string> = { Accept: "text/event-stream" };
+    const lastEventId = request.headers.get("last-event-id");
+    if (lastEventId) {
+      headers["Last-Event-ID"] = lastEventId;
+    }
+
+    const response = await fetch(url, {
+      method: "GET",
+      headers,
+      cache: "no-store",
+      signal: request.signal,
+    });
+
+    if (!response.ok || !response.body) {
+      throw new Error(`Outbox API error: ${response.status}`);
+    }
+
+    return new Response(response.body, {
+      headers: {
+        "Content-Type": "text/event-stream",
+        "Cache-Control": "no-cache, no-transform",
+        Connection: "keep-alive",
+      },
+    });
+  } catch (error) {
+    console.error("Error streaming events:", error);
+    return NextResponse.json(
+      { error: "Failed to stream events" },
+      { status: 500 }
+    );
+  }
+}
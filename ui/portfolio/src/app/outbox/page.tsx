@@ -1,6 +1,6 @@
 "use client";
 
-import React, { useState, useEffect, useCallback } from "react";
+import React, { useState, useEffect, useCallback, useRef } from "react";
 import {
   Container,
   Typography,
@@ -32,12 +32,15 @@ import {
   EventsResponse,
   CreateEventRequest,
   OutboxStats,
+  TimeseriesStats,
   PublishRequest,
+  EVENT_CHANGE_ACTIONS,
 } from "../../types/outbox";
 
 export default function OutboxPage() {
   const [events, setEvents] = useState<OutboxEvent[]>([]);
   const [stats, setStats] = useState<OutboxStats | null>(null);
+  const [timeseries, setTimeseries] = useState<TimeseriesStats | null>(null);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
@@ -92,6 +95,20 @@ export default function OutboxPage() {
     } catch (err) {
       console.error("Failed to fetch stats:", err);
     }
+
+    try {
+      const response = await fetch(
+        "/api/outbox/admin/stats/timeseries?window=1h&bucket=1m"
+      );
+      if (!response.ok) {
+        throw new Error("Failed to fetch timeseries stats");
+      }
+
+      const data: TimeseriesStats = await response.json();
+      setTimeseries(data);
+    } catch (err) {
+      console.error("Failed to fetch timeseries stats:", err);
+    }
   };
 
   useEffect(() => {
@@ -99,6 +116,34 @@ export default function OutboxPage() {
     fetchStats();
   }, [fetchEvents]);
 
+  // Refresh whenever an event changes on any replica instead of polling
+  const refreshRef = useRef<() => void>(() => {});
+  useEffect(() => {
+    refreshRef.current = () => {
+      fetchEvents();
+      fetchStats();
+    };
+  }, [fetchEvents]);
+
+  useEffect(() => {
+    const source = new EventSource("/api/outbox/events/stream");
+    let timer: ReturnType<typeof setTimeout> | undefined;
+    const onChange = () => {
+      // Coalesce bursts, such as a published batch, into one refresh
+      clearTimeout(timer);
+      timer = setTimeout(() => refreshRef.current(), 500);
+    };
+
+    EVENT_CHANGE_ACTIONS.forEach((action) =>
+      source.addEventListener(action, onChange)
+    );
+
+    return () => {
+      clearTimeout(timer);
+      source.close();
+    };
+  }, []);
+
   const handleCreateEvent = async (eventData: CreateEventRequest) => {
     try {
       const response = await fetch("/api/outbox/events", {
@@ -220,7 +265,7 @@ export default function OutboxPage() {
           </Alert>
         )}
 
-        <StatsCards stats={stats} />
+        <StatsCards stats={stats} timeseries={timeseries} />
 
         <Box sx={{ mt: 4 }}>
           <SimulationControls
@@ -1,6 +1,6 @@
 "use client";
 
-import React from "react";
+import React, { useEffect, useState } from "react";
 import {
   Dialog,
   DialogTitle,
@@ -13,7 +13,11 @@ import {
   Divider,
   Paper,
 } from "@mui/material";
-import { OutboxEvent } from "../../types/outbox";
+import {
+  DeliveryAttempt,
+  DeliveryAttemptsResponse,
+  OutboxEvent,
+} from "../../types/outbox";
 
 interface EventDetailDialogProps {
   open: boolean;
@@ -41,6 +45,40 @@ export const EventDetailDialog: React.FC<EventDetailDialogProps> = ({
   event,
   onClose,
 }) => {
+  const [attempts, setAttempts] = useState<DeliveryAttempt[]>([]);
+  const [attemptsError, setAttemptsError] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!open || !event) return;
+
+    let cancelled = false;
+    const fetchAttempts = async () => {
+      try {
+        setAttemptsError(null);
+        const response = await fetch(
+          `/api/outbox/events/${event.id}/attempts`
+        );
+        if (!response.ok) {
+          throw new Error("Failed to fetch delivery attempts");
+        }
+        const data: DeliveryAttemptsResponse = await response.json();
+        if (!cancelled) setAttempts(data.attempts);
+      } catch (err) {
+        if (!cancelled) {
+          setAttempts([]);
+          setAttemptsError(
+            err instanceof Error ? err.message : "Failed to fetch attempts"
+          );
+        }
+      }
+    };
+
+    fetchAttempts();
+    return () => {
+      cancelled = true;
+    };
+  }, [open, event]);
+
   if (!event) return null;
 
   return (
@@ -84,6 +122,18 @@ export const EventDetailDialog: React.FC<EventDetailDialogProps> = ({
                   {new Date(event.published_at).toLocaleString()}
                 </Typography>
               )}
+              {event.deliver_at && (
+                <Typography variant="body2">
+                  <strong>Deliver At:</strong>{" "}
+                  {new Date(event.deliver_at).toLocaleString()}
+                </Typography>
+              )}
+              {event.expires_at && (
+                <Typography variant="body2">
+                  <strong>Expires At:</strong>{" "}
+                  {new Date(event.expires_at).toLocaleString()}
+                </Typography>
+              )}
             </Paper>
           </Box>
 
@@ -107,6 +157,64 @@ export const EventDetailDialog: React.FC<EventDetailDialogProps> = ({
             </Box>
           )}
 
+          <Box>
+            <Typography variant="subtitle1" fontWeight="bold">
+              Delivery Attempts
+            </Typography>
+            <Paper sx={{ p: 2, mt: 1, bgcolor: "grey.50" }}>
+              {attemptsError && (
+                <Typography variant="body2" color="error">
+                  {attemptsError}
+                </Typography>
+              )}
+              {!attemptsError && attempts.length === 0 && (
+                <Typography variant="body2" color="text.secondary">
+                  No delivery attempts yet
+                </Typography>
+              )}
+              {attempts.map((attempt) => (
+                <Box key={attempt.id} sx={{ mb: 1 }}>
+                  <Box sx={{ display: "flex", alignItems: "center", gap: 1 }}>
+                    <Chip
+                      label={attempt.error ? attempt.error_class : "delivered"}
+                      color={attempt.error ? "error" : "success"}
+                      size="small"
+                    />
+                    <Typography variant="body2">
+                      {new Date(attempt.attempted_at).toLocaleString()} ·{" "}
+                      {attempt.duration_ms} ms
+                      {attempt.status_code
+                        ? ` · HTTP ${attempt.status_code}`
+                        : ""}
+                    </Typography>
+                  </Box>
+                  <Typography
+                    variant="body2"
+                    color="text.secondary"
+                    sx={{ wordBreak: "break-all" }}
+                  >
+                    {attempt.destination}
+                  </Typography>
+                  {attempt.error && (
+                    <Typography variant="body2" fontFamily="monospace">
+                      {attempt.error}
+                    </Typography>
+                  )}
+                  {attempt.response_body && (
+                    <Typography
+                      variant="body2"
+                      fontFamily="monospace"
+                      color="text.secondary"
+                      sx={{ whiteSpace: "pre-wrap", wordBreak: "break-word" }}
+                    >
+                      {attempt.response_body}
+                    </Typography>
+                  )}
+                </Box>
+              ))}
+            </Paper>
+          </Box>
+
           <Divider />
 
           <Box>
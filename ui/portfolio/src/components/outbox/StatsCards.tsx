@@ -15,11 +15,17 @@ import {
   CheckCircle as CheckCircleIcon,
   Error as ErrorIcon,
   Refresh as RefreshIcon,
+  Timer as TimerIcon,
 } from "@mui/icons-material";
-import { OutboxStats } from "../../types/outbox";
+import {
+  OutboxStats,
+  StatsBreakdown,
+  TimeseriesStats,
+} from "../../types/outbox";
 
 interface StatsCardsProps {
   stats: OutboxStats | null;
+  timeseries?: TimeseriesStats | null;
   loading?: boolean;
 }
 
@@ -28,9 +34,38 @@ interface StatCardProps {
   value: number;
   icon: React.ReactNode;
   color: string;
+  // trend is drawn as a sparkline under the value, oldest first
+  trend?: number[];
+  caption?: string;
 }
 
-const StatCard: React.FC<StatCardProps> = ({ title, value, icon, color }) => (
+const Sparkline: React.FC<{ values: number[]; color: string }> = ({
+  values,
+  color,
+}) => {
+  const width = 120;
+  const height = 28;
+  const max = Math.max(...values, 1);
+  const step = values.length > 1 ? width / (values.length - 1) : width;
+  const points = values
+    .map((value, i) => `${i * step},${height - (value / max) * height}`)
+    .join(" ");
+
+  return (
+    <svg width={width} height={height} aria-hidden="true">
+      <polyline points={points} fill="none" stroke={color} strokeWidth={1.5} />
+    </svg>
+  );
+};
+
+const StatCard: React.FC<StatCardProps> = ({
+  title,
+  value,
+  icon,
+  color,
+  trend,
+  caption,
+}) => (
   <Card>
     <CardContent>
       <Box
@@ -48,12 +83,63 @@ const StatCard: React.FC<StatCardProps> = ({ title, value, icon, color }) => (
         </Box>
         <Box sx={{ color, fontSize: 40 }}>{icon}</Box>
       </Box>
+      {trend && trend.length > 0 && (
+        <Box sx={{ mt: 1 }}>
+          <Sparkline values={trend} color={color} />
+          {caption && (
+            <Typography variant="caption" color="text.secondary" display="block">
+              {caption}
+            </Typography>
+          )}
+        </Box>
+      )}
+    </CardContent>
+  </Card>
+);
+
+const formatLatency = (ms: number | null) => {
+  if (ms === null) {
+    return "–";
+  }
+  return ms < 1000 ? `${Math.round(ms)} ms` : `${(ms / 1000).toFixed(1)} s`;
+};
+
+const BreakdownCard: React.FC<{
+  title: string;
+  breakdowns: StatsBreakdown[];
+}> = ({ title, breakdowns }) => (
+  <Card sx={{ height: "100%" }}>
+    <CardContent>
+      <Typography color="text.secondary" gutterBottom>
+        {title}
+      </Typography>
+      {breakdowns.length === 0 ? (
+        <Typography variant="body2" color="text.secondary">
+          No activity in the last hour
+        </Typography>
+      ) : (
+        breakdowns.slice(0, 5).map((breakdown) => (
+          <Box
+            key={breakdown.key}
+            sx={{ display: "flex", justifyContent: "space-between", gap: 2 }}
+          >
+            <Typography variant="body2" noWrap>
+              {breakdown.key}
+            </Typography>
+            <Typography variant="body2" color="text.secondary" noWrap>
+              {breakdown.created} created · {breakdown.published} published ·{" "}
+              {breakdown.failed} failed
+            </Typography>
+          </Box>
+        ))
+      )}
     </CardContent>
   </Card>
 );
 
 export const StatsCards: React.FC<StatsCardsProps> = ({
   stats,
+  timeseries = null,
   loading = false,
 }) => {
   if (loading) {
@@ -74,6 +160,9 @@ export const StatsCards: React.FC<StatsCardsProps> = ({
     );
   }
 
+  const buckets = timeseries?.buckets;
+  const totals = timeseries?.totals;
+
   return (
     <Grid container spacing={3}>
       <Grid size={{ xs: 12, sm: 6, md: 2.4 }}>
@@ -82,6 +171,8 @@ export const StatsCards: React.FC<StatsCardsProps> = ({
           value={stats.total_events}
           icon={<EventNoteIcon />}
           color="#1976d2"
+          trend={buckets?.map((bucket) => bucket.created)}
+          caption={totals && `${totals.created} created in the last hour`}
         />
       </Grid>
       <Grid size={{ xs: 12, sm: 6, md: 2.4 }}>
@@ -98,6 +189,8 @@ export const StatsCards: React.FC<StatsCardsProps> = ({
           value={stats.published_events}
           icon={<CheckCircleIcon />}
           color="#2e7d32"
+          trend={buckets?.map((bucket) => bucket.published)}
+          caption={totals && `${totals.published} in the last hour`}
         />
       </Grid>
       <Grid size={{ xs: 12, sm: 6, md: 2.4 }}>
@@ -106,6 +199,8 @@ export const StatsCards: React.FC<StatsCardsProps> = ({
           value={stats.failed_events}
           icon={<ErrorIcon />}
           color="#d32f2f"
+          trend={buckets?.map((bucket) => bucket.failed)}
+          caption={totals && `${totals.failed} in the last hour`}
         />
       </Grid>
       <Grid size={{ xs: 12, sm: 6, md: 2.4 }}>
@@ -116,6 +211,55 @@ export const StatsCards: React.FC<StatsCardsProps> = ({
           color="#0288d1"
         />
       </Grid>
+      {timeseries && (
+        <>
+          <Grid size={{ xs: 12, md: 4 }}>
+            <Card sx={{ height: "100%" }}>
+              <CardContent>
+                <Box
+                  sx={{
+                    display: "flex",
+                    alignItems: "center",
+                    justifyContent: "space-between",
+                  }}
+                >
+                  <Box>
+                    <Typography color="text.secondary" gutterBottom>
+                      Publish Latency (last hour)
+                    </Typography>
+                    <Typography variant="h5">
+                      p50 {formatLatency(timeseries.totals.latency_p50_ms)}
+                    </Typography>
+                    <Typography variant="body2" color="text.secondary">
+                      p95 {formatLatency(timeseries.totals.latency_p95_ms)}
+                    </Typography>
+                  </Box>
+                  <Box sx={{ color: "#6a1b9a", fontSize: 40 }}>
+                    <TimerIcon />
+                  </Box>
+                </Box>
+                <Box sx={{ mt: 1 }}>
+                  <Sparkline
+                    values={timeseries.buckets.map(
+                      (bucket) => bucket.latency_p95_ms ?? 0
+                    )}
+                    color="#6a1b9a"
+                  />
+                </Box>
+              </CardContent>
+            </Card>
+          </Grid>
+          <Grid size={{ xs: 12, md: 4 }}>
+            <BreakdownCard title="Busiest Types" breakdowns={timeseries.by_type} />
+          </Grid>
+          <Grid size={{ xs: 12, md: 4 }}>
+            <BreakdownCard
+              title="Busiest Sources"
+              breakdowns={timeseries.by_source}
+            />
+          </Grid>
+        </>
+      )}
     </Grid>
   );
 };
@@ -4,18 +4,43 @@ export interface OutboxEvent {
   source: string;
   data: Record<string, unknown>;
   metadata?: Record<string, unknown>;
-  status: "pending" | "published" | "failed" | "retrying";
+  status: "pending" | "published" | "failed" | "retrying" | "expired";
   error_message?: string;
   retry_count: number;
   created_at: string;
   published_at?: string;
+  next_attempt_at?: string;
+  partition_key?: string;
+  priority?: number;
+  deliver_at?: string;
+  expires_at?: string;
 }
 
 export interface EventsResponse {
   events: OutboxEvent[];
   total: number;
-  page: number;
+  page?: number;
   limit: number;
+  next_cursor?: string;
+}
+
+export interface DeliveryAttempt {
+  id: number;
+  event_id: string;
+  subscription_id?: string;
+  destination: string;
+  attempted_at: string;
+  duration_ms: number;
+  status_code?: number;
+  response_body?: string;
+  error?: string;
+  error_class?: string;
+}
+
+export interface DeliveryAttemptsResponse {
+  event_id: string;
+  attempts: DeliveryAttempt[];
+  total: number;
 }
 
 export interface CreateEventRequest {
@@ -23,6 +48,11 @@ export interface CreateEventRequest {
   source: string;
   data: Record<string, unknown>;
   metadata?: Record<string, unknown>;
+  idempotency_key?: string;
+  partition_key?: string;
+  priority?: number;
+  deliver_at?: string;
+  expires_at?: string;
 }
 
 export interface OutboxStats {
@@ -30,9 +60,62 @@ export interface OutboxStats {
   pending_events: number;
   published_events: number;
   failed_events: number;
+  retrying_events: number;
+  expired_events: number;
+  pending_by_priority?: Record<string, number>;
+  dead_letter_events: number;
   retry_count: number;
 }
 
+export interface ThroughputStats {
+  created: number;
+  published: number;
+  failed: number;
+  latency_p50_ms: number | null;
+  latency_p95_ms: number | null;
+}
+
+export interface StatsBucket extends ThroughputStats {
+  start: string;
+}
+
+export interface StatsBreakdown extends ThroughputStats {
+  key: string;
+}
+
+export interface TimeseriesStats {
+  from: string;
+  to: string;
+  bucket_seconds: number;
+  totals: ThroughputStats;
+  buckets: StatsBucket[];
+  by_type: StatsBreakdown[];
+  by_source: StatsBreakdown[];
+}
+
+export const EVENT_CHANGE_ACTIONS = [
+  "created",
+  "published",
+  "failed",
+  "retrying",
+  "expired",
+  "requeued",
+  "dead_lettered",
+  "deleted",
+] as const;
+
+export type EventChangeAction = (typeof EVENT_CHANGE_ACTIONS)[number];
+
+export interface EventChange {
+  id: number;
+  event_id: string;
+  action: EventChangeAction;
+  type: string;
+  status: OutboxEvent["status"];
+  retry_count: number;
+  changed_at: string;
+}
+
 export interface PublishRequest {
   batch_size?: number;
   event_ids?: string[];
@@ -41,5 +124,30 @@ export interface PublishRequest {
 export interface PublishResponse {
   published: number;
   failed: number;
+  deferred?: number;
+  blocked?: number;
   errors?: string[];
 }
+
+export interface Subscription {
+  id: string;
+  name: string;
+  url: string;
+  event_types: string[];
+  sources: string[];
+  active: boolean;
+  previous_secret_expires_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Delivery {
+  event_id: string;
+  subscription_id: string;
+  url: string;
+  status: 'delivered' | 'failed';
+  attempts: number;
+  last_error?: string;
+  last_attempt_at: string;
+  delivered_at?: string;
+}